@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is a named set of permissions; each role includes the permissions of the roles below it
+type Role string
+
+const (
+	RoleNone   Role = ""
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// rank orders roles from least to most privileged
+var rank = map[Role]int{
+	RoleNone:   0,
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// ParseRole parses a role name
+func ParseRole(name string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := rank[role]; !ok || role == RoleNone {
+		return RoleNone, fmt.Errorf("unknown role %q", name)
+	}
+	return role, nil
+}
+
+// Includes reports whether the role grants at least the permissions of other
+func (r Role) Includes(other Role) bool {
+	return rank[r] >= rank[other]
+}
+
+// String returns the role name, or "none"
+func (r Role) String() string {
+	if r == RoleNone {
+		return "none"
+	}
+	return string(r)
+}
+
+// RouteRule requires a role for requests matching a method and path pattern.
+// The method may be "*"; path segments may be {param} placeholders and a trailing /* matches any suffix.
+type RouteRule struct {
+	Method   string
+	Pattern  string
+	Role     Role
+	segments []string
+}
+
+// matches reports whether the rule applies to the request
+func (r *RouteRule) matches(method, path string) bool {
+	if r.Method != "*" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range r.segments {
+		if segment == "*" && i == len(r.segments)-1 {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if !strings.HasPrefix(segment, "{") && segment != segments[i] {
+			return false
+		}
+	}
+	return len(segments) == len(r.segments)
+}
+
+// Policy declares which role each principal has and which role each route and operation requires
+type Policy struct {
+	// Bindings grants roles to principals by ID, in addition to the roles carried by their credentials
+	Bindings map[string]Role
+	// DefaultRole is granted to authenticated principals without any role
+	DefaultRole Role
+	// Routes are evaluated in order and the first match applies; unmatched routes require RoleAdmin
+	Routes []RouteRule
+	// Operations maps service operations (e.g. ProductService.DeleteProduct) to their required role;
+	// unlisted operations require RoleAdmin
+	Operations map[string]Role
+}
+
+// NewPolicy parses a policy from its configuration:
+// bindings as "principal:role", routes as "METHOD /path=role" and operations as "Service.Method=role",
+// each comma-separated
+func NewPolicy(bindings, defaultRole, routes, operations string) (*Policy, error) {
+	policy := &Policy{
+		Bindings:   make(map[string]Role),
+		Operations: make(map[string]Role),
+	}
+
+	if defaultRole != "" {
+		role, err := ParseRole(defaultRole)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default role: %w", err)
+		}
+		policy.DefaultRole = role
+	}
+
+	for _, entry := range splitList(bindings) {
+		principal, name, ok := strings.Cut(entry, ":")
+		if !ok || strings.TrimSpace(principal) == "" {
+			return nil, fmt.Errorf("invalid role binding %q, expected principal:role", entry)
+		}
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid role binding %q: %w", entry, err)
+		}
+		policy.Bindings[strings.TrimSpace(principal)] = role
+	}
+
+	for _, entry := range splitList(routes) {
+		route, name, ok := strings.Cut(entry, "=")
+		method, pattern, hasMethod := strings.Cut(strings.TrimSpace(route), " ")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || !hasMethod || !strings.HasPrefix(pattern, "/") {
+			return nil, fmt.Errorf("invalid route rule %q, expected METHOD /path=role", entry)
+		}
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route rule %q: %w", entry, err)
+		}
+		policy.Routes = append(policy.Routes, RouteRule{
+			Method:   strings.ToUpper(method),
+			Pattern:  pattern,
+			Role:     role,
+			segments: strings.Split(strings.Trim(pattern, "/"), "/"),
+		})
+	}
+
+	for _, entry := range splitList(operations) {
+		operation, name, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(operation) == "" {
+			return nil, fmt.Errorf("invalid operation rule %q, expected Service.Method=role", entry)
+		}
+		role, err := ParseRole(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operation rule %q: %w", entry, err)
+		}
+		policy.Operations[strings.TrimSpace(operation)] = role
+	}
+
+	return policy, nil
+}
+
+// route returns the rule that applies to the request, or nil when no rule matches
+func (p *Policy) route(method, path string) *RouteRule {
+	for i := range p.Routes {
+		if p.Routes[i].matches(method, path) {
+			return &p.Routes[i]
+		}
+	}
+	return nil
+}
+
+// roleOf returns the most privileged role granted to the principal
+func (p *Policy) roleOf(principal *Principal) Role {
+	role := p.Bindings[principal.ID]
+	for _, name := range principal.Roles {
+		if granted, err := ParseRole(name); err == nil && !role.Includes(granted) {
+			role = granted
+		}
+	}
+	if role == RoleNone {
+		role = p.DefaultRole
+	}
+	return role
+}
+
+// splitList splits a comma-separated list, dropping blank entries
+func splitList(list string) []string {
+	var entries []string
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
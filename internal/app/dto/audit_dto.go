@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// AuditChangeResponse represents the before and after value of one product field
+type AuditChangeResponse struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// AuditEntryResponse represents one entry of the audit trail
+type AuditEntryResponse struct {
+	ID         string                 `json:"id"`
+	ProductID  string                 `json:"product_id"`
+	Operation  string                 `json:"operation"`
+	Actor      string                 `json:"actor"`
+	Changes    []*AuditChangeResponse `json:"changes"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	TraceID    string                 `json:"trace_id,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
+
+// ToAuditEntryResponse converts a domain AuditEntry to AuditEntryResponse
+func ToAuditEntryResponse(e *domain.AuditEntry) *AuditEntryResponse {
+	changes := make([]*AuditChangeResponse, len(e.Changes))
+	for i, c := range e.Changes {
+		changes[i] = &AuditChangeResponse{Field: c.Field, Before: c.Before, After: c.After}
+	}
+	return &AuditEntryResponse{
+		ID:         e.ID,
+		ProductID:  e.ProductID,
+		Operation:  string(e.Operation),
+		Actor:      e.Actor,
+		Changes:    changes,
+		RequestID:  e.RequestID,
+		TraceID:    e.TraceID,
+		OccurredAt: e.OccurredAt,
+	}
+}
+
+// ToAuditEntryResponseList converts a list of domain AuditEntries to AuditEntryResponse list
+func ToAuditEntryResponseList(entries []*domain.AuditEntry) []*AuditEntryResponse {
+	responses := make([]*AuditEntryResponse, len(entries))
+	for i, e := range entries {
+		responses[i] = ToAuditEntryResponse(e)
+	}
+	return responses
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// CreateCategoryRequest represents the request to create a category
+type CreateCategoryRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ParentID    string `json:"parent_id,omitempty"`
+}
+
+// UpdateCategoryRequest represents the request to update a category
+type UpdateCategoryRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	ParentID    string `json:"parent_id,omitempty"`
+}
+
+// CategoryResponse represents the category response
+type CategoryResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	ParentID    string    `json:"parent_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ToCategoryResponse converts a domain Category to CategoryResponse
+func ToCategoryResponse(c *domain.Category) *CategoryResponse {
+	return &CategoryResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		ParentID:    c.ParentID,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+// ToCategoryResponseList converts a list of domain Categories to CategoryResponse list
+func ToCategoryResponseList(categories []*domain.Category) []*CategoryResponse {
+	responses := make([]*CategoryResponse, len(categories))
+	for i, c := range categories {
+		responses[i] = ToCategoryResponse(c)
+	}
+	return responses
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// EventResponse is the wire representation of a product domain event
+type EventResponse struct {
+	ID         string           `json:"id"`
+	Type       string           `json:"type"`
+	ProductID  string           `json:"product_id"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       *ProductResponse `json:"data"`
+}
+
+// ToEventResponse converts a domain Event to EventResponse
+func ToEventResponse(e domain.Event) *EventResponse {
+	return &EventResponse{
+		ID:         e.ID,
+		Type:       string(e.Type),
+		ProductID:  e.ProductID,
+		OccurredAt: e.OccurredAt,
+		Data:       ToProductResponse(&e.Product),
+	}
+}
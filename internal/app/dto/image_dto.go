@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"io"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// ImageUpload is one uploaded image file, read once while it is stored
+type ImageUpload struct {
+	Filename string
+	Content  io.Reader
+}
+
+// ImageContent is the content of an image or thumbnail; callers close Content
+type ImageContent struct {
+	ContentType string
+	Content     io.ReadCloser
+}
+
+// ImageResponse represents a product image and its processing state
+type ImageResponse struct {
+	ID           string     `json:"id"`
+	ProductID    string     `json:"product_id"`
+	Filename     string     `json:"filename,omitempty"`
+	ContentType  string     `json:"content_type"`
+	Size         int64      `json:"size"`
+	Width        int        `json:"width,omitempty"`
+	Height       int        `json:"height,omitempty"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	URL          string     `json:"url"`
+	ThumbnailURL string     `json:"thumbnail_url,omitempty"`
+	UploadedAt   time.Time  `json:"uploaded_at"`
+	ProcessedAt  *time.Time `json:"processed_at,omitempty"`
+}
+
+// ToImageResponse converts a domain Image to ImageResponse
+func ToImageResponse(i *domain.Image) *ImageResponse {
+	url := "/products/" + i.ProductID + "/images/" + i.ID
+	response := &ImageResponse{
+		ID:          i.ID,
+		ProductID:   i.ProductID,
+		Filename:    i.Filename,
+		ContentType: i.ContentType,
+		Size:        i.Size,
+		Width:       i.Width,
+		Height:      i.Height,
+		Status:      string(i.Status),
+		Error:       i.Error,
+		URL:         url,
+		UploadedAt:  i.UploadedAt,
+		ProcessedAt: i.ProcessedAt,
+	}
+	if i.Status == domain.ImageReady {
+		response.ThumbnailURL = url + "/thumbnail"
+	}
+	return response
+}
+
+// ToImageResponseList converts a list of domain Images to ImageResponse list
+func ToImageResponseList(images []*domain.Image) []*ImageResponse {
+	responses := make([]*ImageResponse, len(images))
+	for i, image := range images {
+		responses[i] = ToImageResponse(image)
+	}
+	return responses
+}
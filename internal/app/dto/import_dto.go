@@ -0,0 +1,47 @@
+package dto
+
+import "time"
+
+// Catalogue file formats, for imports and exports
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Import job statuses
+const (
+	ImportJobPending   = "pending"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	// ImportJobFailed marks a job that stopped early; rows processed before the failure stay created
+	ImportJobFailed = "failed"
+)
+
+// ImportRow is one parsed row of an import file.
+// Rows that could not be parsed carry an Error instead of a Product.
+type ImportRow struct {
+	Line    int
+	Product *CreateProductRequest
+	Error   string
+}
+
+// ImportRowError reports why a row of an import file was not created
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportJobResponse represents the progress and outcome of an import
+type ImportJobResponse struct {
+	ID          string            `json:"id"`
+	Status      string            `json:"status"`
+	Format      string            `json:"format"`
+	Total       int               `json:"total"`
+	Processed   int               `json:"processed"`
+	Created     int               `json:"created"`
+	Failed      int               `json:"failed"`
+	Errors      []*ImportRowError `json:"errors"`
+	Error       string            `json:"error,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// SetStockRequest represents the request to set the on-hand stock of a product
+type SetStockRequest struct {
+	OnHand int `json:"on_hand"`
+}
+
+// CreateReservationRequest represents the request to reserve stock of a product
+type CreateReservationRequest struct {
+	Quantity   int `json:"quantity"`
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
+}
+
+// StockResponse represents the stock level response
+type StockResponse struct {
+	ProductID string    `json:"product_id"`
+	OnHand    int       `json:"on_hand"`
+	Reserved  int       `json:"reserved"`
+	Available int       `json:"available"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReservationResponse represents the reservation response
+type ReservationResponse struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ToStockResponse converts a domain Stock to StockResponse
+func ToStockResponse(s *domain.Stock) *StockResponse {
+	return &StockResponse{
+		ProductID: s.ProductID,
+		OnHand:    s.OnHand,
+		Reserved:  s.Reserved,
+		Available: s.Available(),
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// ToReservationResponse converts a domain Reservation to ReservationResponse
+func ToReservationResponse(r *domain.Reservation) *ReservationResponse {
+	return &ReservationResponse{
+		ID:        r.ID,
+		ProductID: r.ProductID,
+		Quantity:  r.Quantity,
+		Status:    string(r.Status),
+		ExpiresAt: r.ExpiresAt,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
@@ -8,19 +8,85 @@ import (
 
 // CreateProductRequest represents the request to create a product
 type CreateProductRequest struct {
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	CategoryIDs []string          `json:"category_ids,omitempty"`
+	Variants    []*VariantRequest `json:"variants,omitempty"`
+}
+
+// UpdateProductRequest represents the request to replace a product's editable fields.
+// Variants are kept when Variants is nil and replaced otherwise, so an empty list removes them all.
+type UpdateProductRequest struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	CategoryIDs []string          `json:"category_ids,omitempty"`
+	Variants    []*VariantRequest `json:"variants,omitempty"`
 }
 
 // ProductResponse represents the product response
 type ProductResponse struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Price       float64            `json:"price"`
+	CategoryIDs []string           `json:"category_ids,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	DeletedAt   *time.Time         `json:"deleted_at,omitempty"`
+	Variants    []*VariantResponse `json:"variants,omitempty"`
+}
+
+// VariantRequest represents a product variant in create and update requests
+type VariantRequest struct {
+	SKU           string            `json:"sku"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	PriceOverride *float64          `json:"price_override,omitempty"`
+	Stock         int               `json:"stock"`
+}
+
+// VariantResponse represents a product variant; Price is the override, or else the product price
+type VariantResponse struct {
+	SKU           string            `json:"sku"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	Price         float64           `json:"price"`
+	PriceOverride *float64          `json:"price_override,omitempty"`
+	Stock         int               `json:"stock"`
+}
+
+// SKUResponse represents the result of a SKU lookup: the variant and the product it belongs to
+type SKUResponse struct {
+	Variant *VariantResponse `json:"variant"`
+	Product *ProductResponse `json:"product"`
+}
+
+// ToVariants converts variant requests to domain Variants
+func ToVariants(reqs []*VariantRequest) []domain.Variant {
+	if len(reqs) == 0 {
+		return nil
+	}
+	variants := make([]domain.Variant, len(reqs))
+	for i, req := range reqs {
+		variants[i] = domain.Variant{
+			SKU:           req.SKU,
+			Attributes:    req.Attributes,
+			PriceOverride: req.PriceOverride,
+			Stock:         req.Stock,
+		}
+	}
+	return variants
+}
+
+// ToVariantResponse converts a domain Variant of a product with the given price to VariantResponse
+func ToVariantResponse(v domain.Variant, productPrice float64) *VariantResponse {
+	return &VariantResponse{
+		SKU:           v.SKU,
+		Attributes:    v.Attributes,
+		Price:         v.Price(productPrice),
+		PriceOverride: v.PriceOverride,
+		Stock:         v.Stock,
+	}
 }
 
 // ToProductResponse converts a domain Product to ProductResponse
@@ -30,11 +96,26 @@ func ToProductResponse(p *domain.Product) *ProductResponse {
 		Name:        p.Name,
 		Description: p.Description,
 		Price:       p.Price,
+		CategoryIDs: p.CategoryIDs,
 		CreatedAt:   p.CreatedAt,
 		UpdatedAt:   p.UpdatedAt,
+		DeletedAt:   p.DeletedAt,
+		Variants:    toVariantResponses(p.Variants, p.Price),
 	}
 }
 
+// toVariantResponses converts the variants of a product, keeping a product without variants at nil
+func toVariantResponses(variants []domain.Variant, productPrice float64) []*VariantResponse {
+	if len(variants) == 0 {
+		return nil
+	}
+	responses := make([]*VariantResponse, len(variants))
+	for i, v := range variants {
+		responses[i] = ToVariantResponse(v, productPrice)
+	}
+	return responses
+}
+
 // ToProductResponseList converts a list of domain Products to ProductResponse list
 func ToProductResponseList(products []*domain.Product) []*ProductResponse {
 	responses := make([]*ProductResponse, len(products))
@@ -43,3 +124,52 @@ func ToProductResponseList(products []*domain.Product) []*ProductResponse {
 	}
 	return responses
 }
+
+// PriceChangeResponse represents one point of a product's price history
+type PriceChangeResponse struct {
+	Price     float64   `json:"price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// ToPriceHistoryResponse converts a domain price history to a PriceChangeResponse list
+func ToPriceHistoryResponse(changes []domain.PriceChange) []*PriceChangeResponse {
+	responses := make([]*PriceChangeResponse, len(changes))
+	for i, c := range changes {
+		responses[i] = &PriceChangeResponse{Price: c.Price, ChangedAt: c.ChangedAt}
+	}
+	return responses
+}
+
+// BatchMode selects how a batch handles invalid items
+type BatchMode string
+
+const (
+	// BatchAtomic creates every product or none
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort creates the valid products and reports the invalid ones
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// Batch item statuses
+const (
+	BatchItemCreated = "created"
+	BatchItemFailed  = "failed"
+	// BatchItemSkipped marks a valid item that was not created because its atomic batch failed
+	BatchItemSkipped = "skipped"
+)
+
+// BatchItemResult is the outcome of one item of a batch
+type BatchItemResult struct {
+	Index   int              `json:"index"`
+	Status  string           `json:"status"`
+	Product *ProductResponse `json:"product,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// BatchCreateResponse represents the outcome of a batch create
+type BatchCreateResponse struct {
+	Mode    BatchMode          `json:"mode"`
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+	Items   []*BatchItemResult `json:"items"`
+}
@@ -0,0 +1,107 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// CreateWebhookRequest represents the request to register a webhook subscription
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types,omitempty"`
+}
+
+// WebhookResponse represents a webhook subscription; the secret is only returned on creation
+type WebhookResponse struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DeliveryAttemptResponse represents one entry of a delivery log
+type DeliveryAttemptResponse struct {
+	Attempt     int       `json:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  float64   `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// WebhookDeliveryResponse represents a webhook delivery and its attempt log
+type WebhookDeliveryResponse struct {
+	ID             string                     `json:"id"`
+	SubscriptionID string                     `json:"subscription_id"`
+	EventID        string                     `json:"event_id"`
+	EventType      string                     `json:"event_type"`
+	Status         string                     `json:"status"`
+	Attempts       []*DeliveryAttemptResponse `json:"attempts"`
+	NextAttemptAt  *time.Time                 `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time                  `json:"created_at"`
+	UpdatedAt      time.Time                  `json:"updated_at"`
+}
+
+// ToWebhookResponse converts a domain WebhookSubscription to WebhookResponse without its secret
+func ToWebhookResponse(s *domain.WebhookSubscription) *WebhookResponse {
+	eventTypes := make([]string, len(s.EventTypes))
+	for i, t := range s.EventTypes {
+		eventTypes[i] = string(t)
+	}
+	return &WebhookResponse{
+		ID:         s.ID,
+		URL:        s.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  s.CreatedAt,
+	}
+}
+
+// ToWebhookResponseList converts a list of domain WebhookSubscriptions to WebhookResponse list
+func ToWebhookResponseList(subscriptions []*domain.WebhookSubscription) []*WebhookResponse {
+	responses := make([]*WebhookResponse, len(subscriptions))
+	for i, s := range subscriptions {
+		responses[i] = ToWebhookResponse(s)
+	}
+	return responses
+}
+
+// ToWebhookDeliveryResponse converts a domain WebhookDelivery to WebhookDeliveryResponse
+func ToWebhookDeliveryResponse(d *domain.WebhookDelivery) *WebhookDeliveryResponse {
+	attempts := make([]*DeliveryAttemptResponse, len(d.Attempts))
+	for i, a := range d.Attempts {
+		attempts[i] = &DeliveryAttemptResponse{
+			Attempt:     a.Attempt,
+			StatusCode:  a.StatusCode,
+			Error:       a.Error,
+			DurationMs:  float64(a.Duration.Microseconds()) / 1000,
+			AttemptedAt: a.AttemptedAt,
+		}
+	}
+
+	response := &WebhookDeliveryResponse{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Status:         string(d.Status),
+		Attempts:       attempts,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+	if d.Status == domain.DeliveryPending {
+		next := d.NextAttemptAt
+		response.NextAttemptAt = &next
+	}
+	return response
+}
+
+// ToWebhookDeliveryResponseList converts a list of domain WebhookDeliveries to WebhookDeliveryResponse list
+func ToWebhookDeliveryResponseList(deliveries []*domain.WebhookDelivery) []*WebhookDeliveryResponse {
+	responses := make([]*WebhookDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		responses[i] = ToWebhookDeliveryResponse(d)
+	}
+	return responses
+}
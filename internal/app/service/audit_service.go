@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Audit actors for changes made without an authenticated principal
+const (
+	AnonymousActor = "anonymous"
+	SystemActor    = "system"
+)
+
+// ErrInvalidAuditLimit is returned when an audit query asks for too few or too many entries
+var ErrInvalidAuditLimit = errors.New("limit must be between 1 and 1000")
+
+// maxAuditLimit bounds the number of entries returned by one audit query
+const maxAuditLimit = 1000
+
+// requestIDKey is the context key for the ID of the request being served
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the ID of the request being served, recorded in audit entries
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// requestIDFromContext returns the ID of the request being served, if any
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// AuditUseCases defines the audit trail queries, implemented by AuditService and
+// decorated with tracing, metrics and logs by InstrumentedAuditService
+type AuditUseCases interface {
+	GetProductHistory(ctx context.Context, productID string) ([]*dto.AuditEntryResponse, error)
+	ListEntries(ctx context.Context, since time.Time, limit int) ([]*dto.AuditEntryResponse, error)
+}
+
+// AuditService records the audit trail of product mutations and answers queries over it.
+// Record runs in its own span under the mutation; queries annotate the span of the calling
+// InstrumentedAuditService, which records the outcome of each of them.
+type AuditService struct {
+	repo     domain.AuditRepository
+	authz    *auth.Authorizer
+	tracer   trace.Tracer
+	logger   *slog.Logger
+	entries  metric.Int64Counter
+	failures metric.Int64Counter
+}
+
+// NewAuditService creates a new audit service.
+// authz enforces the role required to query the audit trail; nil allows every caller.
+func NewAuditService(
+	repo domain.AuditRepository,
+	authz *auth.Authorizer,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *AuditService {
+	// Initialize metrics
+	entries, _ := meter.Int64Counter(
+		"audit.entries",
+		metric.WithDescription("Total number of audit entries recorded"),
+	)
+
+	failures, _ := meter.Int64Counter(
+		"audit.failures",
+		metric.WithDescription("Total number of audit entries that could not be recorded"),
+	)
+
+	return &AuditService{
+		repo:     repo,
+		authz:    authz,
+		tracer:   tracer,
+		logger:   logger,
+		entries:  entries,
+		failures: failures,
+	}
+}
+
+// Record appends entries for mutations that have already been stored, filling in the actor,
+// request ID and trace ID from ctx. A failure is logged and counted but not returned, since
+// the mutation itself succeeded.
+func (s *AuditService) Record(ctx context.Context, entries ...*domain.AuditEntry) {
+	if s == nil || len(entries) == 0 {
+		return
+	}
+
+	ctx, span := s.tracer.Start(ctx, "AuditService.Record")
+	defer span.End()
+
+	actor := AnonymousActor
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		actor = principal.ID
+	}
+	requestID := requestIDFromContext(ctx)
+	traceID := ""
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		traceID = sc.TraceID().String()
+	}
+
+	for _, entry := range entries {
+		if entry.Actor == "" {
+			entry.Actor = actor
+		}
+		entry.RequestID = requestID
+		entry.TraceID = traceID
+	}
+
+	operation := entries[0].Operation
+	span.SetAttributes(
+		attribute.String("audit.operation", string(operation)),
+		attribute.String("audit.actor", entries[0].Actor),
+		attribute.Int("audit.entries", len(entries)),
+	)
+
+	if err := s.repo.Append(ctx, entries...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to record audit entries")
+		s.failures.Add(ctx, int64(len(entries)), metric.WithAttributes(attribute.String("operation", string(operation))))
+		s.logger.ErrorContext(ctx, "Failed to record audit entries",
+			slog.String("operation", string(operation)),
+			slog.Int("count", len(entries)),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	s.entries.Add(ctx, int64(len(entries)), metric.WithAttributes(attribute.String("operation", string(operation))))
+	span.SetStatus(codes.Ok, "Audit entries recorded")
+}
+
+// GetProductHistory retrieves the audit trail of a product, oldest first, including after it was purged
+func (s *AuditService) GetProductHistory(ctx context.Context, productID string) ([]*dto.AuditEntryResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "AuditService.GetProductHistory"); err != nil {
+		return nil, err
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("product.id", productID))
+
+	entries, err := s.repo.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, domain.ErrProductNotFound
+	}
+
+	span.SetAttributes(attribute.Int("audit.entries", len(entries)))
+	return dto.ToAuditEntryResponseList(entries), nil
+}
+
+// ListEntries retrieves up to limit audit entries that occurred at or after since, oldest first
+func (s *AuditService) ListEntries(ctx context.Context, since time.Time, limit int) ([]*dto.AuditEntryResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "AuditService.ListEntries"); err != nil {
+		return nil, err
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("audit.since", since.Format(time.RFC3339Nano)),
+		attribute.Int("audit.limit", limit),
+	)
+
+	if limit < 1 || limit > maxAuditLimit {
+		return nil, ErrInvalidAuditLimit
+	}
+
+	entries, err := s.repo.FindSince(ctx, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("audit.entries", len(entries)))
+	return dto.ToAuditEntryResponseList(entries), nil
+}
@@ -0,0 +1,36 @@
+package service
+
+import "sync"
+
+// CategoryLock keeps categories from being deleted while something is being linked to them.
+// Writes that link products or child categories to categories hold it for reading, from checking that the
+// categories exist to storing the link; deleting a category holds it for writing, from checking that nothing
+// links to it to removing it, and so does updating a category, from checking that a new parent creates
+// no cycle to storing it.
+type CategoryLock struct {
+	mu sync.RWMutex
+}
+
+// NewCategoryLock creates a category lock shared by the services that link to or delete categories
+func NewCategoryLock() *CategoryLock {
+	return &CategoryLock{}
+}
+
+// link holds the lock while a write links to categories; the returned func releases it
+func (l *CategoryLock) link() func() {
+	l.mu.RLock()
+	return l.mu.RUnlock
+}
+
+// reparent holds the lock exclusively while a category is updated, so two re-parents can't both pass
+// the cycle check before either is stored; the returned func releases it
+func (l *CategoryLock) reparent() func() {
+	l.mu.Lock()
+	return l.mu.Unlock
+}
+
+// remove holds the lock while a category is checked and deleted; the returned func releases it
+func (l *CategoryLock) remove() func() {
+	l.mu.Lock()
+	return l.mu.Unlock
+}
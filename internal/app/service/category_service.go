@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CategoryUseCases defines the category operations, implemented by CategoryService and
+// decorated with tracing, metrics and logs by InstrumentedCategoryService
+type CategoryUseCases interface {
+	CreateCategory(ctx context.Context, req *dto.CreateCategoryRequest) (*dto.CategoryResponse, error)
+	GetCategoryByID(ctx context.Context, id string) (*dto.CategoryResponse, error)
+	ListCategories(ctx context.Context) ([]*dto.CategoryResponse, error)
+	UpdateCategory(ctx context.Context, id string, req *dto.UpdateCategoryRequest) (*dto.CategoryResponse, error)
+	DeleteCategory(ctx context.Context, id string) error
+	ListCategoryProducts(ctx context.Context, id string) ([]*dto.ProductResponse, error)
+}
+
+// CategoryService handles category use cases.
+// It annotates the span of the calling InstrumentedCategoryService, which records the outcome of each operation.
+type CategoryService struct {
+	repo     domain.CategoryRepository
+	products domain.ProductRepository
+	lock     *CategoryLock
+	logger   *slog.Logger
+}
+
+// NewCategoryService creates a new category service.
+// lock is shared with the product service, so a category cannot be deleted as a product is linked to it.
+func NewCategoryService(
+	repo domain.CategoryRepository,
+	products domain.ProductRepository,
+	lock *CategoryLock,
+	logger *slog.Logger,
+) *CategoryService {
+	return &CategoryService{
+		repo:     repo,
+		products: products,
+		lock:     lock,
+		logger:   logger,
+	}
+}
+
+// CreateCategory creates a new category
+func (s *CategoryService) CreateCategory(ctx context.Context, req *dto.CreateCategoryRequest) (*dto.CategoryResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("category.name", req.Name),
+		attribute.String("category.parent_id", req.ParentID),
+	)
+
+	s.logger.InfoContext(ctx, "Creating category",
+		slog.String("name", req.Name),
+		slog.String("parent_id", req.ParentID),
+	)
+
+	// Create domain entity
+	category, err := domain.NewCategory(req.Name, req.Description, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("category.id", category.ID))
+
+	// The parent must already exist, and cannot be deleted until the category is stored
+	defer s.lock.link()()
+	if !category.IsRoot() {
+		if _, err := s.repo.FindByID(ctx, category.ParentID); err != nil {
+			if err == domain.ErrCategoryNotFound {
+				err = domain.ErrUnknownParentCategory
+			}
+			return nil, err
+		}
+	}
+
+	// Store in repository
+	if err := s.repo.Create(ctx, category); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Category created successfully",
+		slog.String("category_id", category.ID),
+	)
+
+	return dto.ToCategoryResponse(category), nil
+}
+
+// GetCategoryByID retrieves a category by ID
+func (s *CategoryService) GetCategoryByID(ctx context.Context, id string) (*dto.CategoryResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("category.id", id))
+
+	s.logger.InfoContext(ctx, "Getting category by ID",
+		slog.String("category_id", id),
+	)
+
+	category, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Category retrieved successfully",
+		slog.String("category_id", id),
+	)
+
+	return dto.ToCategoryResponse(category), nil
+}
+
+// ListCategories retrieves all categories
+func (s *CategoryService) ListCategories(ctx context.Context) ([]*dto.CategoryResponse, error) {
+	s.logger.InfoContext(ctx, "Listing all categories")
+
+	categories, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("category.count", len(categories)))
+
+	s.logger.InfoContext(ctx, "Categories listed successfully",
+		slog.Int("count", len(categories)),
+	)
+
+	return dto.ToCategoryResponseList(categories), nil
+}
+
+// UpdateCategory updates the name, description and parent of a category
+func (s *CategoryService) UpdateCategory(ctx context.Context, id string, req *dto.UpdateCategoryRequest) (*dto.CategoryResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("category.id", id),
+		attribute.String("category.parent_id", req.ParentID),
+	)
+
+	s.logger.InfoContext(ctx, "Updating category",
+		slog.String("category_id", id),
+		slog.String("parent_id", req.ParentID),
+	)
+
+	// A new parent cannot be deleted, nor another category re-parented, until the category is stored
+	defer s.lock.reparent()()
+
+	existing, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work on a copy so concurrent readers never see a half-applied update
+	category := *existing
+	category.Name = req.Name
+	category.Description = req.Description
+	category.ParentID = req.ParentID
+	category.UpdatedAt = time.Now()
+
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Re-parenting must not create a cycle in the taxonomy
+	if !category.IsRoot() && category.ParentID != existing.ParentID {
+		all, err := s.repo.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := s.repo.FindByID(ctx, category.ParentID); err != nil {
+			if err == domain.ErrCategoryNotFound {
+				err = domain.ErrUnknownParentCategory
+			}
+			return nil, err
+		}
+
+		for _, descendantID := range domain.Subtree(all, id) {
+			if descendantID == category.ParentID {
+				return nil, domain.ErrInvalidCategoryParent
+			}
+		}
+	}
+
+	if err := s.repo.Update(ctx, &category); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Category updated successfully",
+		slog.String("category_id", id),
+	)
+
+	return dto.ToCategoryResponse(&category), nil
+}
+
+// DeleteCategory removes a category that has no children and no linked products
+func (s *CategoryService) DeleteCategory(ctx context.Context, id string) error {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("category.id", id))
+
+	s.logger.InfoContext(ctx, "Deleting category",
+		slog.String("category_id", id),
+	)
+
+	// Nothing can be linked to the category between checking its links and deleting it
+	defer s.lock.remove()()
+
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return err
+	}
+
+	all, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(domain.Subtree(all, id)) > 1 {
+		return domain.ErrCategoryHasChildren
+	}
+
+	linked, err := s.products.FindByCategories(ctx, []string{id})
+	if err != nil {
+		return err
+	}
+
+	// Products in the trash count too, so they never come back linked to a missing category
+	trashed, err := s.products.FindDeleted(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, product := range trashed {
+		if product.InCategory(id) {
+			linked = append(linked, product)
+		}
+	}
+
+	if len(linked) > 0 {
+		return domain.ErrCategoryInUse
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Category deleted successfully",
+		slog.String("category_id", id),
+	)
+
+	return nil
+}
+
+// ListCategoryProducts retrieves the products in a category and all of its descendants
+func (s *CategoryService) ListCategoryProducts(ctx context.Context, id string) ([]*dto.ProductResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("category.id", id))
+
+	s.logger.InfoContext(ctx, "Listing category products",
+		slog.String("category_id", id),
+	)
+
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	all, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	subtree := domain.Subtree(all, id)
+	span.SetAttributes(attribute.Int("category.subtree_size", len(subtree)))
+
+	products, err := s.products.FindByCategories(ctx, subtree)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("product.count", len(products)))
+
+	s.logger.InfoContext(ctx, "Category products listed successfully",
+		slog.String("category_id", id),
+		slog.Int("count", len(products)),
+	)
+
+	return dto.ToProductResponseList(products), nil
+}
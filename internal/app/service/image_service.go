@@ -0,0 +1,450 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sniffLen is the number of leading bytes used to detect the content type of an upload
+const sniffLen = 512
+
+// maxImagePixels bounds the size of the bitmap decoded to generate a thumbnail
+const maxImagePixels = 40_000_000
+
+var errImageTooManyPixels = errors.New("image has too many pixels to process")
+
+// supportedImageTypes are the sniffed content types that can be uploaded and thumbnailed
+var supportedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// imageJob is an uploaded image waiting for its thumbnail
+type imageJob struct {
+	image *domain.Image
+	link  trace.Link
+}
+
+// ImageUseCases defines the product image operations, implemented by ImageService and
+// decorated with tracing, metrics and logs by InstrumentedImageService
+type ImageUseCases interface {
+	ReplaceImages(ctx context.Context, productID string, uploads []*dto.ImageUpload) ([]*dto.ImageResponse, error)
+	ListImages(ctx context.Context, productID string) ([]*dto.ImageResponse, error)
+	GetImageContent(ctx context.Context, productID, imageID string, thumbnail bool) (*dto.ImageContent, error)
+	// HandleEvent is registered on the in-process event bus rather than called on behalf of a caller
+	HandleEvent(ctx context.Context, event domain.Event) error
+}
+
+// ImageService stores product images in a blob store and generates their thumbnails.
+// Uploads are stored within the request; thumbnails are generated in the background by Run.
+// It annotates the span of the calling InstrumentedImageService, and generates thumbnails in their own traces.
+type ImageService struct {
+	products           domain.ProductRepository
+	images             domain.ImageRepository
+	blobs              domain.BlobStore
+	authz              *auth.Authorizer
+	maxSize            int64
+	maxCount           int
+	thumbnailSize      int
+	queue              chan *imageJob
+	tracer             trace.Tracer
+	logger             *slog.Logger
+	uploadSize         metric.Int64Histogram
+	processingDuration metric.Float64Histogram
+}
+
+// NewImageService creates a new image service.
+// Products have at most maxCount images of at most maxSize bytes each; thumbnails fit in a
+// thumbnailSize square, and up to queueSize images wait for Run.
+func NewImageService(
+	products domain.ProductRepository,
+	images domain.ImageRepository,
+	blobs domain.BlobStore,
+	authz *auth.Authorizer,
+	maxSize int64,
+	maxCount int,
+	thumbnailSize int,
+	queueSize int,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *ImageService {
+	// Initialize metrics
+	uploadSize, _ := meter.Int64Histogram(
+		"images.upload.size",
+		metric.WithDescription("Size of uploaded product images"),
+		metric.WithUnit("By"),
+		metric.WithExplicitBucketBoundaries(1<<10, 16<<10, 64<<10, 256<<10, 1<<20, 4<<20, 16<<20),
+	)
+
+	processingDuration, _ := meter.Float64Histogram(
+		"images.processing.duration",
+		metric.WithDescription("Duration of product image thumbnail generation"),
+		metric.WithUnit("s"),
+	)
+
+	return &ImageService{
+		products:           products,
+		images:             images,
+		blobs:              blobs,
+		authz:              authz,
+		maxSize:            maxSize,
+		maxCount:           maxCount,
+		thumbnailSize:      thumbnailSize,
+		queue:              make(chan *imageJob, queueSize),
+		tracer:             tracer,
+		logger:             logger,
+		uploadSize:         uploadSize,
+		processingDuration: processingDuration,
+	}
+}
+
+// ReplaceImages stores the uploaded images in place of the product's current ones and queues
+// their thumbnails. Nothing is replaced unless every upload is a supported image within the limits.
+func (s *ImageService) ReplaceImages(ctx context.Context, productID string, uploads []*dto.ImageUpload) ([]*dto.ImageResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ImageService.ReplaceImages"); err != nil {
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("product.id", productID),
+		attribute.Int("image.count", len(uploads)),
+	)
+
+	switch {
+	case len(uploads) == 0:
+		return nil, domain.ErrNoImages
+	case len(uploads) > s.maxCount:
+		return nil, domain.ErrTooManyImages
+	}
+
+	if _, err := s.products.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	images := make([]*domain.Image, 0, len(uploads))
+	for _, upload := range uploads {
+		img, err := s.store(ctx, productID, upload)
+		if err != nil {
+			s.discard(ctx, images)
+			s.logger.WarnContext(ctx, "Product image upload rejected",
+				slog.String("product_id", productID),
+				slog.String("filename", upload.Filename),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+		images = append(images, img)
+	}
+
+	replaced, err := s.images.Replace(ctx, productID, images)
+	if err != nil {
+		s.discard(ctx, images)
+		return nil, err
+	}
+	s.discard(ctx, replaced)
+
+	link := trace.LinkFromContext(ctx)
+	for _, img := range images {
+		s.enqueue(ctx, img, link)
+	}
+
+	s.logger.InfoContext(ctx, "Product images replaced",
+		slog.String("product_id", productID),
+		slog.Int("count", len(images)),
+		slog.Int("replaced", len(replaced)),
+	)
+
+	return dto.ToImageResponseList(images), nil
+}
+
+// ListImages retrieves the images of a product in upload order
+func (s *ImageService) ListImages(ctx context.Context, productID string) ([]*dto.ImageResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ImageService.ListImages"); err != nil {
+		return nil, err
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("product.id", productID))
+
+	if _, err := s.products.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	images, err := s.images.FindByProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("image.count", len(images)))
+	return dto.ToImageResponseList(images), nil
+}
+
+// GetImageContent opens the content of a product image, or of its thumbnail once it is ready
+func (s *ImageService) GetImageContent(ctx context.Context, productID, imageID string, thumbnail bool) (*dto.ImageContent, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ImageService.GetImageContent"); err != nil {
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("product.id", productID),
+		attribute.String("image.id", imageID),
+		attribute.Bool("image.thumbnail", thumbnail),
+	)
+
+	if _, err := s.products.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	img, err := s.images.FindByID(ctx, productID, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, contentType := img.BlobKey, img.ContentType
+	if thumbnail {
+		if img.Status != domain.ImageReady {
+			return nil, domain.ErrThumbnailNotReady
+		}
+		key, contentType = img.ThumbnailKey(), "image/png"
+	}
+
+	content, err := s.blobs.Get(ctx, key)
+	if err == domain.ErrBlobNotFound {
+		// The image was replaced after it was looked up
+		err = domain.ErrImageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ImageContent{ContentType: contentType, Content: content}, nil
+}
+
+// Run blocks, generating the thumbnails of queued images one at a time until ctx is done
+func (s *ImageService) Run(ctx context.Context) {
+	s.logger.Info("Image worker started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Image worker stopped")
+			return
+		case job := <-s.queue:
+			s.process(ctx, job)
+		}
+	}
+}
+
+// store sniffs the content type of an upload and writes it to the blob store within the size limit
+func (s *ImageService) store(ctx context.Context, productID string, upload *dto.ImageUpload) (*domain.Image, error) {
+	content := bufio.NewReaderSize(upload.Content, sniffLen)
+	header, err := content.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	contentType := http.DetectContentType(header)
+	if !supportedImageTypes[contentType] {
+		return nil, domain.ErrUnsupportedImageType
+	}
+
+	img := domain.NewImage(productID, upload.Filename, contentType)
+	size, err := s.blobs.Put(ctx, img.BlobKey, io.LimitReader(content, s.maxSize+1))
+	if err == nil && size > s.maxSize {
+		err = domain.ErrImageTooLarge
+	}
+	if err != nil {
+		_ = s.blobs.Delete(ctx, img.BlobKey)
+		return nil, err
+	}
+	img.Size = size
+
+	s.uploadSize.Record(ctx, size, metric.WithAttributes(attribute.String("content_type", contentType)))
+	return img, nil
+}
+
+// enqueue queues the thumbnail of an image, failing the image when the queue is full
+func (s *ImageService) enqueue(ctx context.Context, img *domain.Image, link trace.Link) {
+	select {
+	case s.queue <- &imageJob{image: img, link: link}:
+	default:
+		img.MarkFailed("thumbnail queue full")
+		_ = s.images.Update(ctx, img)
+		s.logger.WarnContext(ctx, "Image thumbnail not queued, queue full",
+			slog.String("product_id", img.ProductID),
+			slog.String("image_id", img.ID),
+		)
+	}
+}
+
+// process generates the thumbnail of a queued image in a new root span linked to the upload
+func (s *ImageService) process(ctx context.Context, job *imageJob) {
+	img := *job.image
+	ctx, span := s.tracer.Start(ctx, "ImageService.GenerateThumbnail",
+		trace.WithNewRoot(),
+		trace.WithLinks(job.link),
+		trace.WithAttributes(
+			attribute.String("product.id", img.ProductID),
+			attribute.String("image.id", img.ID),
+			attribute.String("image.content_type", img.ContentType),
+			attribute.Int64("image.size", img.Size),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	result := string(domain.ImageReady)
+	if width, height, err := s.generateThumbnail(ctx, &img); err != nil {
+		result = string(domain.ImageFailed)
+		img.MarkFailed(err.Error())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Thumbnail generation failed")
+		s.logger.ErrorContext(ctx, "Image thumbnail generation failed",
+			slog.String("product_id", img.ProductID),
+			slog.String("image_id", img.ID),
+			slog.String("error", err.Error()),
+		)
+	} else {
+		img.MarkReady(width, height)
+		span.SetAttributes(attribute.Int("image.width", width), attribute.Int("image.height", height))
+		span.SetStatus(codes.Ok, "Thumbnail generated")
+	}
+
+	if err := s.images.Update(ctx, &img); err != nil {
+		// The image was replaced while it was processed
+		result = "discarded"
+		s.discard(ctx, []*domain.Image{&img})
+	}
+
+	s.processingDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attribute.String("result", result)))
+
+	s.logger.InfoContext(ctx, "Image processed",
+		slog.String("product_id", img.ProductID),
+		slog.String("image_id", img.ID),
+		slog.String("result", result),
+	)
+}
+
+// generateThumbnail decodes an image, stores a PNG thumbnail of it and returns its dimensions
+func (s *ImageService) generateThumbnail(ctx context.Context, img *domain.Image) (int, int, error) {
+	content, err := s.blobs.Get(ctx, img.BlobKey)
+	if err != nil {
+		return 0, 0, err
+	}
+	data, err := io.ReadAll(content)
+	content.Close()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Check the dimensions before decoding, so a small file cannot expand into a huge bitmap
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return 0, 0, errImageTooManyPixels
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var thumbnail bytes.Buffer
+	if err := png.Encode(&thumbnail, fit(src, s.thumbnailSize)); err != nil {
+		return 0, 0, err
+	}
+	if _, err := s.blobs.Put(ctx, img.ThumbnailKey(), &thumbnail); err != nil {
+		return 0, 0, err
+	}
+	return config.Width, config.Height, nil
+}
+
+// HandleEvent deletes the images of purged products, with their content and thumbnails.
+// It is registered as a handler on the in-process event bus.
+func (s *ImageService) HandleEvent(ctx context.Context, event domain.Event) error {
+	if event.Type != domain.EventProductPurged {
+		return nil
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("event.id", event.ID),
+		attribute.String("product.id", event.ProductID),
+	)
+
+	images, err := s.images.DeleteByProduct(ctx, event.ProductID)
+	if err != nil {
+		return err
+	}
+	s.discard(ctx, images)
+	span.SetAttributes(attribute.Int("image.count", len(images)))
+
+	if len(images) > 0 {
+		s.logger.InfoContext(ctx, "Images of purged product deleted",
+			slog.String("product_id", event.ProductID),
+			slog.Int("count", len(images)),
+		)
+	}
+
+	return nil
+}
+
+// discard deletes the content and thumbnails of images that are no longer stored
+func (s *ImageService) discard(ctx context.Context, images []*domain.Image) {
+	for _, img := range images {
+		for _, key := range []string{img.BlobKey, img.ThumbnailKey()} {
+			if err := s.blobs.Delete(ctx, key); err != nil {
+				s.logger.WarnContext(ctx, "Failed to delete image content",
+					slog.String("key", key),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+	}
+}
+
+// fit scales src down with nearest-neighbour sampling to fit in a size by size square,
+// keeping its aspect ratio; smaller images keep their size
+func fit(src image.Image, size int) *image.NRGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/width)
+		} else {
+			width, height = max(1, width*size/height), size
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sy := bounds.Min.Y + (2*y+1)*bounds.Dy()/(2*height)
+		for x := 0; x < width; x++ {
+			sx := bounds.Min.X + (2*x+1)*bounds.Dx()/(2*width)
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+	return dst
+}
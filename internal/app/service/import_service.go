@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrImportJobNotFound = errors.New("import job not found")
+	ErrImportQueueFull   = errors.New("too many imports are queued, retry later")
+)
+
+// maxImportRowErrors bounds the row errors kept per job; Failed still counts every failed row
+const maxImportRowErrors = 1000
+
+// importJob is an import and the rows it still has to create
+type importJob struct {
+	state     dto.ImportJobResponse
+	rows      []*dto.ImportRow
+	principal *auth.Principal
+	link      trace.Link
+}
+
+// ImportUseCases defines the import operations, implemented by ImportService and
+// decorated with tracing, metrics and logs by InstrumentedImportService
+type ImportUseCases interface {
+	ImportProducts(ctx context.Context, format string, rows []*dto.ImportRow) (*dto.ImportJobResponse, error)
+	GetImportJob(ctx context.Context, id string) (*dto.ImportJobResponse, error)
+}
+
+// ImportService imports products in chunks through the product service's best-effort batch create.
+// Small imports run within the request; larger ones are queued and run in the background by Run.
+// It annotates the span of the calling InstrumentedImportService, and runs queued jobs in their own traces.
+type ImportService struct {
+	products    ProductUseCases
+	authz       *auth.Authorizer
+	chunkSize   int
+	syncMaxRows int
+	retention   time.Duration
+	queue       chan *importJob
+	mu          sync.Mutex
+	jobs        map[string]*importJob
+	tracer      trace.Tracer
+	logger      *slog.Logger
+	rowCounter  metric.Int64Counter
+	jobCounter  metric.Int64Counter
+	activeJobs  metric.Int64UpDownCounter
+}
+
+// NewImportService creates a new import service.
+// Imports of up to syncMaxRows rows run synchronously; up to queueSize larger imports wait for Run,
+// and finished jobs can be looked up for the retention period.
+func NewImportService(
+	products ProductUseCases,
+	authz *auth.Authorizer,
+	chunkSize int,
+	syncMaxRows int,
+	queueSize int,
+	retention time.Duration,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *ImportService {
+	// Initialize metrics
+	rowCounter, _ := meter.Int64Counter(
+		"products.import.rows",
+		metric.WithDescription("Total number of import rows processed, by result"),
+	)
+
+	jobCounter, _ := meter.Int64Counter(
+		"products.import.jobs",
+		metric.WithDescription("Total number of import jobs finished, by result"),
+	)
+
+	activeJobs, _ := meter.Int64UpDownCounter(
+		"products.import.jobs.active",
+		metric.WithDescription("Number of import jobs queued or running"),
+	)
+
+	return &ImportService{
+		products:    products,
+		authz:       authz,
+		chunkSize:   chunkSize,
+		syncMaxRows: syncMaxRows,
+		retention:   retention,
+		queue:       make(chan *importJob, queueSize),
+		jobs:        make(map[string]*importJob),
+		tracer:      tracer,
+		logger:      logger,
+		rowCounter:  rowCounter,
+		jobCounter:  jobCounter,
+		activeJobs:  activeJobs,
+	}
+}
+
+// ImportProducts creates the products of the parsed rows, reporting the rows that failed.
+// It returns a completed job when the import ran synchronously and a pending job when it was queued.
+func (s *ImportService) ImportProducts(ctx context.Context, format string, rows []*dto.ImportRow) (*dto.ImportJobResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ImportService.ImportProducts"); err != nil {
+		return nil, err
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("import.format", format),
+		attribute.Int("import.rows", len(rows)),
+	)
+
+	if len(rows) == 0 {
+		return nil, domain.ErrEmptyBatch
+	}
+
+	job := &importJob{
+		state: dto.ImportJobResponse{
+			ID:        uuid.New().String(),
+			Status:    dto.ImportJobPending,
+			Format:    format,
+			Total:     len(rows),
+			Errors:    []*dto.ImportRowError{},
+			CreatedAt: time.Now(),
+		},
+		rows: rows,
+		link: trace.LinkFromContext(ctx),
+	}
+	job.principal, _ = auth.PrincipalFromContext(ctx)
+
+	span.SetAttributes(attribute.String("import.job_id", job.state.ID))
+
+	if len(rows) <= s.syncMaxRows {
+		s.store(job)
+		s.activeJobs.Add(ctx, 1)
+		s.run(ctx, job)
+		return s.snapshot(job), nil
+	}
+
+	// Register the job before queueing it so its status can be read as soon as it is returned,
+	// and take the pending state before the worker can pick it up
+	s.store(job)
+	pending := s.snapshot(job)
+	s.activeJobs.Add(ctx, 1)
+	select {
+	case s.queue <- job:
+	default:
+		s.mu.Lock()
+		delete(s.jobs, job.state.ID)
+		s.mu.Unlock()
+		s.activeJobs.Add(ctx, -1)
+		s.logger.WarnContext(ctx, "Import rejected, queue full",
+			slog.Int("rows", len(rows)),
+		)
+		return nil, ErrImportQueueFull
+	}
+
+	s.logger.InfoContext(ctx, "Import job queued",
+		slog.String("job_id", job.state.ID),
+		slog.Int("rows", len(rows)),
+	)
+
+	return pending, nil
+}
+
+// GetImportJob returns the progress of an import job
+func (s *ImportService) GetImportJob(ctx context.Context, id string) (*dto.ImportJobResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ImportService.GetImportJob"); err != nil {
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("import.job_id", id))
+
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrImportJobNotFound
+	}
+
+	return s.snapshot(job), nil
+}
+
+// Run blocks, running queued import jobs one at a time until ctx is done
+func (s *ImportService) Run(ctx context.Context) {
+	s.logger.Info("Import worker started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Import worker stopped")
+			return
+		case job := <-s.queue:
+			s.runQueued(ctx, job)
+		}
+	}
+}
+
+// runQueued runs a queued job in a new root span linked to the request that queued it,
+// on behalf of the principal that queued it
+func (s *ImportService) runQueued(ctx context.Context, job *importJob) {
+	if job.principal != nil {
+		ctx = auth.WithPrincipal(ctx, job.principal)
+	}
+	ctx, span := s.tracer.Start(ctx, "ImportService.RunJob",
+		trace.WithNewRoot(),
+		trace.WithLinks(job.link),
+		trace.WithAttributes(
+			attribute.String("import.job_id", job.state.ID),
+			attribute.Int("import.rows", job.state.Total),
+		),
+	)
+	defer span.End()
+
+	s.run(ctx, job)
+
+	if state := s.snapshot(job); state.Status == dto.ImportJobFailed {
+		span.SetStatus(codes.Error, state.Error)
+		return
+	}
+	span.SetStatus(codes.Ok, "Import job completed")
+}
+
+// run creates the rows of a job chunk by chunk, updating its progress after each chunk
+func (s *ImportService) run(ctx context.Context, job *importJob) {
+	s.update(job, func(state *dto.ImportJobResponse) { state.Status = dto.ImportJobRunning })
+
+	s.logger.InfoContext(ctx, "Import job started",
+		slog.String("job_id", job.state.ID),
+		slog.Int("rows", job.state.Total),
+	)
+
+	var failure error
+	for start := 0; start < len(job.rows) && failure == nil; start += s.chunkSize {
+		chunk := job.rows[start:min(start+s.chunkSize, len(job.rows))]
+		failure = s.runChunk(ctx, job, chunk)
+	}
+
+	result := dto.ImportJobCompleted
+	now := time.Now()
+	s.update(job, func(state *dto.ImportJobResponse) {
+		if failure != nil {
+			result = dto.ImportJobFailed
+			state.Error = failure.Error()
+		}
+		state.Status = result
+		state.CompletedAt = &now
+	})
+	// The rows are no longer needed once the job is done
+	job.rows = nil
+
+	s.activeJobs.Add(ctx, -1)
+	s.jobCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+
+	state := s.snapshot(job)
+	if failure != nil {
+		s.logger.ErrorContext(ctx, "Import job failed",
+			slog.String("job_id", state.ID),
+			slog.Int("processed", state.Processed),
+			slog.String("error", failure.Error()),
+		)
+		return
+	}
+	s.logger.InfoContext(ctx, "Import job completed",
+		slog.String("job_id", state.ID),
+		slog.Int("created", state.Created),
+		slog.Int("failed", state.Failed),
+	)
+}
+
+// runChunk creates the parsed rows of a chunk and records the rows that failed
+func (s *ImportService) runChunk(ctx context.Context, job *importJob, chunk []*dto.ImportRow) error {
+	var errs []*dto.ImportRowError
+	var reqs []*dto.CreateProductRequest
+	var lines []int
+	for _, row := range chunk {
+		if row.Product == nil {
+			errs = append(errs, &dto.ImportRowError{Line: row.Line, Error: row.Error})
+			continue
+		}
+		reqs = append(reqs, row.Product)
+		lines = append(lines, row.Line)
+	}
+
+	created := 0
+	if len(reqs) > 0 {
+		result, err := s.products.CreateProducts(ctx, reqs, dto.BatchBestEffort)
+		if err != nil {
+			return err
+		}
+		for _, item := range result.Items {
+			if item.Status == dto.BatchItemFailed {
+				errs = append(errs, &dto.ImportRowError{Line: lines[item.Index], Error: item.Error})
+			}
+		}
+		created = result.Created
+	}
+
+	s.rowCounter.Add(ctx, int64(created), metric.WithAttributes(attribute.String("result", "created")))
+	s.rowCounter.Add(ctx, int64(len(errs)), metric.WithAttributes(attribute.String("result", "failed")))
+
+	s.update(job, func(state *dto.ImportJobResponse) {
+		state.Processed += len(chunk)
+		state.Created += created
+		state.Failed += len(errs)
+		for _, rowErr := range errs {
+			if len(state.Errors) == maxImportRowErrors {
+				break
+			}
+			state.Errors = append(state.Errors, rowErr)
+		}
+	})
+	return nil
+}
+
+// store registers a job, evicting finished jobs older than the retention period
+func (s *ImportService) store(job *importJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := time.Now().Add(-s.retention)
+	for id, existing := range s.jobs {
+		if completedAt := existing.state.CompletedAt; completedAt != nil && completedAt.Before(cutoff) {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobs[job.state.ID] = job
+}
+
+// update changes the state of a job under the lock
+func (s *ImportService) update(job *importJob, fn func(state *dto.ImportJobResponse)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&job.state)
+}
+
+// snapshot returns a copy of the state of a job
+func (s *ImportService) snapshot(job *importJob) *dto.ImportJobResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := job.state
+	state.Errors = append([]*dto.ImportRowError{}, job.state.Errors...)
+	return &state
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentedAuditService wraps any AuditUseCases with tracing, metrics and logs.
+// Every call gets an AuditService.<Method> span, and its outcome is counted in audit.operations
+// and timed in audit.operation.duration.
+type InstrumentedAuditService struct {
+	next AuditUseCases
+	ops  *operations
+}
+
+// NewInstrumentedAuditService instruments next
+func NewInstrumentedAuditService(
+	next AuditUseCases,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *InstrumentedAuditService {
+	return &InstrumentedAuditService{
+		next: next,
+		ops:  newOperations("AuditService", "audit", "audit query", tracer, meter, logger),
+	}
+}
+
+// GetProductHistory retrieves the audit trail of a product, oldest first
+func (s *InstrumentedAuditService) GetProductHistory(ctx context.Context, productID string) ([]*dto.AuditEntryResponse, error) {
+	return instrument(ctx, s.ops, "GetProductHistory", "product_history", func(ctx context.Context) ([]*dto.AuditEntryResponse, error) {
+		return s.next.GetProductHistory(ctx, productID)
+	}, nil)
+}
+
+// ListEntries retrieves up to limit audit entries that occurred at or after since, oldest first
+func (s *InstrumentedAuditService) ListEntries(ctx context.Context, since time.Time, limit int) ([]*dto.AuditEntryResponse, error) {
+	return instrument(ctx, s.ops, "ListEntries", "list", func(ctx context.Context) ([]*dto.AuditEntryResponse, error) {
+		return s.next.ListEntries(ctx, since, limit)
+	}, nil)
+}
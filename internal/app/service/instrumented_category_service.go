@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentedCategoryService wraps any CategoryUseCases with tracing, metrics and logs.
+// Every call gets a CategoryService.<Method> span, and its outcome is counted in categories.operations
+// and timed in categories.operation.duration.
+type InstrumentedCategoryService struct {
+	next                   CategoryUseCases
+	ops                    *operations
+	categoryCreatedCounter metric.Int64Counter
+}
+
+// NewInstrumentedCategoryService instruments next
+func NewInstrumentedCategoryService(
+	next CategoryUseCases,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *InstrumentedCategoryService {
+	// Initialize metrics
+	categoryCreatedCounter, _ := meter.Int64Counter(
+		"categories.created.total",
+		metric.WithDescription("Total number of categories created"),
+	)
+
+	return &InstrumentedCategoryService{
+		next:                   next,
+		ops:                    newOperations("CategoryService", "categories", "category", tracer, meter, logger),
+		categoryCreatedCounter: categoryCreatedCounter,
+	}
+}
+
+// CreateCategory creates a new category
+func (s *InstrumentedCategoryService) CreateCategory(ctx context.Context, req *dto.CreateCategoryRequest) (*dto.CategoryResponse, error) {
+	category, err := instrument(ctx, s.ops, "CreateCategory", "create", func(ctx context.Context) (*dto.CategoryResponse, error) {
+		return s.next.CreateCategory(ctx, req)
+	}, nil)
+	if err == nil {
+		s.categoryCreatedCounter.Add(ctx, 1)
+	}
+	return category, err
+}
+
+// GetCategoryByID retrieves a category by ID
+func (s *InstrumentedCategoryService) GetCategoryByID(ctx context.Context, id string) (*dto.CategoryResponse, error) {
+	return instrument(ctx, s.ops, "GetCategoryByID", "read", func(ctx context.Context) (*dto.CategoryResponse, error) {
+		return s.next.GetCategoryByID(ctx, id)
+	}, nil)
+}
+
+// ListCategories retrieves all categories
+func (s *InstrumentedCategoryService) ListCategories(ctx context.Context) ([]*dto.CategoryResponse, error) {
+	return instrument(ctx, s.ops, "ListCategories", "list", s.next.ListCategories, nil)
+}
+
+// UpdateCategory updates the name, description and parent of a category
+func (s *InstrumentedCategoryService) UpdateCategory(ctx context.Context, id string, req *dto.UpdateCategoryRequest) (*dto.CategoryResponse, error) {
+	return instrument(ctx, s.ops, "UpdateCategory", "update", func(ctx context.Context) (*dto.CategoryResponse, error) {
+		return s.next.UpdateCategory(ctx, id, req)
+	}, nil)
+}
+
+// DeleteCategory removes a category that has no children and no linked products
+func (s *InstrumentedCategoryService) DeleteCategory(ctx context.Context, id string) error {
+	return run(ctx, s.ops, "DeleteCategory", "delete", func(ctx context.Context) error {
+		return s.next.DeleteCategory(ctx, id)
+	})
+}
+
+// ListCategoryProducts retrieves the products in a category and all of its descendants
+func (s *InstrumentedCategoryService) ListCategoryProducts(ctx context.Context, id string) ([]*dto.ProductResponse, error) {
+	return instrument(ctx, s.ops, "ListCategoryProducts", "list_products", func(ctx context.Context) ([]*dto.ProductResponse, error) {
+		return s.next.ListCategoryProducts(ctx, id)
+	}, nil)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentedImageService wraps any ImageUseCases with tracing, metrics and logs.
+// Every call gets an ImageService.<Method> span, and its outcome is counted in images.operations
+// and timed in images.operation.duration.
+type InstrumentedImageService struct {
+	next ImageUseCases
+	ops  *operations
+}
+
+// NewInstrumentedImageService instruments next
+func NewInstrumentedImageService(
+	next ImageUseCases,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *InstrumentedImageService {
+	return &InstrumentedImageService{
+		next: next,
+		ops:  newOperations("ImageService", "images", "product image", tracer, meter, logger),
+	}
+}
+
+// ReplaceImages stores the uploaded images in place of the product's current ones
+func (s *InstrumentedImageService) ReplaceImages(ctx context.Context, productID string, uploads []*dto.ImageUpload) ([]*dto.ImageResponse, error) {
+	return instrument(ctx, s.ops, "ReplaceImages", "replace", func(ctx context.Context) ([]*dto.ImageResponse, error) {
+		return s.next.ReplaceImages(ctx, productID, uploads)
+	}, nil)
+}
+
+// ListImages retrieves the images of a product in upload order
+func (s *InstrumentedImageService) ListImages(ctx context.Context, productID string) ([]*dto.ImageResponse, error) {
+	return instrument(ctx, s.ops, "ListImages", "list", func(ctx context.Context) ([]*dto.ImageResponse, error) {
+		return s.next.ListImages(ctx, productID)
+	}, nil)
+}
+
+// GetImageContent opens the content of a product image, or of its thumbnail once it is ready
+func (s *InstrumentedImageService) GetImageContent(ctx context.Context, productID, imageID string, thumbnail bool) (*dto.ImageContent, error) {
+	return instrument(ctx, s.ops, "GetImageContent", "get_content", func(ctx context.Context) (*dto.ImageContent, error) {
+		return s.next.GetImageContent(ctx, productID, imageID, thumbnail)
+	}, nil)
+}
+
+// HandleEvent deletes the images of purged products
+func (s *InstrumentedImageService) HandleEvent(ctx context.Context, event domain.Event) error {
+	return run(ctx, s.ops, "HandleEvent", "handle_event", func(ctx context.Context) error {
+		return s.next.HandleEvent(ctx, event)
+	})
+}
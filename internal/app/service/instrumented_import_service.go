@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentedImportService wraps any ImportUseCases with tracing, metrics and logs.
+// Every call gets an ImportService.<Method> span, and its outcome is counted in products.import.operations
+// and timed in products.import.operation.duration.
+type InstrumentedImportService struct {
+	next ImportUseCases
+	ops  *operations
+}
+
+// NewInstrumentedImportService instruments next
+func NewInstrumentedImportService(
+	next ImportUseCases,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *InstrumentedImportService {
+	return &InstrumentedImportService{
+		next: next,
+		ops:  newOperations("ImportService", "products.import", "import", tracer, meter, logger),
+	}
+}
+
+// ImportProducts creates the products of the parsed rows, or queues a job to create them
+func (s *InstrumentedImportService) ImportProducts(ctx context.Context, format string, rows []*dto.ImportRow) (*dto.ImportJobResponse, error) {
+	return instrument(ctx, s.ops, "ImportProducts", "import", func(ctx context.Context) (*dto.ImportJobResponse, error) {
+		return s.next.ImportProducts(ctx, format, rows)
+	}, nil)
+}
+
+// GetImportJob returns the progress of an import job
+func (s *InstrumentedImportService) GetImportJob(ctx context.Context, id string) (*dto.ImportJobResponse, error) {
+	return instrument(ctx, s.ops, "GetImportJob", "get_job", func(ctx context.Context) (*dto.ImportJobResponse, error) {
+		return s.next.GetImportJob(ctx, id)
+	}, nil)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentedInventoryService wraps any InventoryUseCases with tracing, metrics and logs.
+// Every call gets an InventoryService.<Method> span, and its outcome is counted in inventory.operations
+// and timed in inventory.operation.duration.
+type InstrumentedInventoryService struct {
+	next             InventoryUseCases
+	ops              *operations
+	reservedQuantity metric.Int64Counter
+}
+
+// NewInstrumentedInventoryService instruments next
+func NewInstrumentedInventoryService(
+	next InventoryUseCases,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *InstrumentedInventoryService {
+	// Initialize metrics
+	reservedQuantity, _ := meter.Int64Counter(
+		"inventory.reserved.quantity",
+		metric.WithDescription("Total quantity of stock reserved"),
+		metric.WithUnit("{item}"),
+	)
+
+	return &InstrumentedInventoryService{
+		next:             next,
+		ops:              newOperations("InventoryService", "inventory", "inventory", tracer, meter, logger),
+		reservedQuantity: reservedQuantity,
+	}
+}
+
+// GetStock retrieves the stock level of a product
+func (s *InstrumentedInventoryService) GetStock(ctx context.Context, productID string) (*dto.StockResponse, error) {
+	return instrument(ctx, s.ops, "GetStock", "get_stock", func(ctx context.Context) (*dto.StockResponse, error) {
+		return s.next.GetStock(ctx, productID)
+	}, nil)
+}
+
+// SetStock replaces the on-hand stock of a product
+func (s *InstrumentedInventoryService) SetStock(ctx context.Context, productID string, req *dto.SetStockRequest) (*dto.StockResponse, error) {
+	return instrument(ctx, s.ops, "SetStock", "set_stock", func(ctx context.Context) (*dto.StockResponse, error) {
+		return s.next.SetStock(ctx, productID, req)
+	}, nil)
+}
+
+// ReserveStock holds stock of a product until the reservation is confirmed, released or expires
+func (s *InstrumentedInventoryService) ReserveStock(ctx context.Context, productID string, req *dto.CreateReservationRequest) (*dto.ReservationResponse, error) {
+	reservation, err := instrument(ctx, s.ops, "ReserveStock", "reserve", func(ctx context.Context) (*dto.ReservationResponse, error) {
+		return s.next.ReserveStock(ctx, productID, req)
+	}, nil)
+	if err == nil {
+		s.reservedQuantity.Add(ctx, int64(reservation.Quantity))
+	}
+	return reservation, err
+}
+
+// GetReservation retrieves a reservation of a product
+func (s *InstrumentedInventoryService) GetReservation(ctx context.Context, productID, reservationID string) (*dto.ReservationResponse, error) {
+	return instrument(ctx, s.ops, "GetReservation", "get_reservation", func(ctx context.Context) (*dto.ReservationResponse, error) {
+		return s.next.GetReservation(ctx, productID, reservationID)
+	}, nil)
+}
+
+// ConfirmReservation commits a pending reservation, permanently removing its stock
+func (s *InstrumentedInventoryService) ConfirmReservation(ctx context.Context, productID, reservationID string) (*dto.ReservationResponse, error) {
+	return instrument(ctx, s.ops, "ConfirmReservation", "confirm", func(ctx context.Context) (*dto.ReservationResponse, error) {
+		return s.next.ConfirmReservation(ctx, productID, reservationID)
+	}, nil)
+}
+
+// ReleaseReservation cancels a pending reservation, returning its stock to the available pool
+func (s *InstrumentedInventoryService) ReleaseReservation(ctx context.Context, productID, reservationID string) (*dto.ReservationResponse, error) {
+	return instrument(ctx, s.ops, "ReleaseReservation", "release", func(ctx context.Context) (*dto.ReservationResponse, error) {
+		return s.next.ReleaseReservation(ctx, productID, reservationID)
+	}, nil)
+}
+
+// ExpireReservations expires the pending reservations past their TTL and purges old completed ones
+func (s *InstrumentedInventoryService) ExpireReservations(ctx context.Context) error {
+	return run(ctx, s.ops, "ExpireReservations", "expire", s.next.ExpireReservations)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentedProductService wraps any ProductUseCases with tracing, metrics and logs.
+// Every call gets a ProductService.<Method> span, and its outcome is counted in products.operations
+// and timed in products.operation.duration, labelled with the operation and a result of
+// success, partial, denied, not_found, conflict or failure.
+type InstrumentedProductService struct {
+	next                  ProductUseCases
+	ops                   *operations
+	productCreatedCounter metric.Int64Counter
+	batchSize             metric.Int64Histogram
+	purgedCounter         metric.Int64Counter
+}
+
+// NewInstrumentedProductService instruments next
+func NewInstrumentedProductService(
+	next ProductUseCases,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *InstrumentedProductService {
+	// Initialize metrics
+	productCreatedCounter, _ := meter.Int64Counter(
+		"products.created.total",
+		metric.WithDescription("Total number of products created"),
+	)
+
+	batchSize, _ := meter.Int64Histogram(
+		"products.batch.size",
+		metric.WithDescription("Number of products per batch create"),
+		metric.WithUnit("{product}"),
+	)
+
+	purgedCounter, _ := meter.Int64Counter(
+		"products.purged",
+		metric.WithDescription("Total number of soft-deleted products permanently removed"),
+	)
+
+	return &InstrumentedProductService{
+		next:                  next,
+		ops:                   newOperations("ProductService", "products", "product", tracer, meter, logger),
+		productCreatedCounter: productCreatedCounter,
+		batchSize:             batchSize,
+		purgedCounter:         purgedCounter,
+	}
+}
+
+// CreateProduct creates a new product
+func (s *InstrumentedProductService) CreateProduct(ctx context.Context, req *dto.CreateProductRequest) (*dto.ProductResponse, error) {
+	product, err := instrument(ctx, s.ops, "CreateProduct", "create", func(ctx context.Context) (*dto.ProductResponse, error) {
+		return s.next.CreateProduct(ctx, req)
+	}, nil)
+	if err == nil {
+		s.productCreatedCounter.Add(ctx, 1)
+	}
+	return product, err
+}
+
+// CreateProducts creates a batch of products
+func (s *InstrumentedProductService) CreateProducts(ctx context.Context, reqs []*dto.CreateProductRequest, mode dto.BatchMode) (*dto.BatchCreateResponse, error) {
+	if len(reqs) > 0 {
+		s.batchSize.Record(ctx, int64(len(reqs)), metric.WithAttributes(attribute.String("batch.mode", string(mode))))
+	}
+
+	result, err := instrument(ctx, s.ops, "CreateProducts", "batch_create", func(ctx context.Context) (*dto.BatchCreateResponse, error) {
+		return s.next.CreateProducts(ctx, reqs, mode)
+	}, func(result *dto.BatchCreateResponse) string {
+		switch {
+		case result.Failed == 0:
+			return "success"
+		case result.Created == 0:
+			// Atomic batches with a failed item, and best-effort batches where every item failed
+			return "failure"
+		default:
+			return "partial"
+		}
+	})
+	if err == nil {
+		s.productCreatedCounter.Add(ctx, int64(result.Created))
+	}
+	return result, err
+}
+
+// GetProductByID retrieves a product by ID
+func (s *InstrumentedProductService) GetProductByID(ctx context.Context, id string) (*dto.ProductResponse, error) {
+	return instrument(ctx, s.ops, "GetProductByID", "read", func(ctx context.Context) (*dto.ProductResponse, error) {
+		return s.next.GetProductByID(ctx, id)
+	}, nil)
+}
+
+// GetProductBySKU retrieves the product and variant of a SKU
+func (s *InstrumentedProductService) GetProductBySKU(ctx context.Context, sku string) (*dto.SKUResponse, error) {
+	return instrument(ctx, s.ops, "GetProductBySKU", "read_sku", func(ctx context.Context) (*dto.SKUResponse, error) {
+		return s.next.GetProductBySKU(ctx, sku)
+	}, nil)
+}
+
+// GetProductAsOf retrieves a product as it was at a point in time
+func (s *InstrumentedProductService) GetProductAsOf(ctx context.Context, id string, asOf time.Time) (*dto.ProductResponse, error) {
+	return instrument(ctx, s.ops, "GetProductAsOf", "read_as_of", func(ctx context.Context) (*dto.ProductResponse, error) {
+		return s.next.GetProductAsOf(ctx, id, asOf)
+	}, nil)
+}
+
+// GetPriceHistory retrieves the price history of a product
+func (s *InstrumentedProductService) GetPriceHistory(ctx context.Context, id string) ([]*dto.PriceChangeResponse, error) {
+	return instrument(ctx, s.ops, "GetPriceHistory", "price_history", func(ctx context.Context) ([]*dto.PriceChangeResponse, error) {
+		return s.next.GetPriceHistory(ctx, id)
+	}, nil)
+}
+
+// ListProducts retrieves all products, and optionally the soft-deleted ones
+func (s *InstrumentedProductService) ListProducts(ctx context.Context, includeDeleted bool) ([]*dto.ProductResponse, error) {
+	return instrument(ctx, s.ops, "ListProducts", "list", func(ctx context.Context) ([]*dto.ProductResponse, error) {
+		return s.next.ListProducts(ctx, includeDeleted)
+	}, nil)
+}
+
+// SearchProducts retrieves all products whose name or description matches the query
+func (s *InstrumentedProductService) SearchProducts(ctx context.Context, query string) ([]*dto.ProductResponse, error) {
+	return instrument(ctx, s.ops, "SearchProducts", "search", func(ctx context.Context) ([]*dto.ProductResponse, error) {
+		return s.next.SearchProducts(ctx, query)
+	}, nil)
+}
+
+// ExportProducts passes every product to fn in ID order
+func (s *InstrumentedProductService) ExportProducts(ctx context.Context, fn func(*dto.ProductResponse) error) error {
+	return run(ctx, s.ops, "ExportProducts", "export", func(ctx context.Context) error {
+		return s.next.ExportProducts(ctx, fn)
+	})
+}
+
+// UpdateProduct replaces the editable fields of a product
+func (s *InstrumentedProductService) UpdateProduct(ctx context.Context, id string, req *dto.UpdateProductRequest) (*dto.ProductResponse, error) {
+	return instrument(ctx, s.ops, "UpdateProduct", "update", func(ctx context.Context) (*dto.ProductResponse, error) {
+		return s.next.UpdateProduct(ctx, id, req)
+	}, nil)
+}
+
+// DeleteProduct soft-deletes a product
+func (s *InstrumentedProductService) DeleteProduct(ctx context.Context, id string) error {
+	return run(ctx, s.ops, "DeleteProduct", "delete", func(ctx context.Context) error {
+		return s.next.DeleteProduct(ctx, id)
+	})
+}
+
+// RestoreProduct brings back a soft-deleted product
+func (s *InstrumentedProductService) RestoreProduct(ctx context.Context, id string) (*dto.ProductResponse, error) {
+	return instrument(ctx, s.ops, "RestoreProduct", "restore", func(ctx context.Context) (*dto.ProductResponse, error) {
+		return s.next.RestoreProduct(ctx, id)
+	}, nil)
+}
+
+// PurgeDeletedProducts permanently removes the products past the trash retention
+func (s *InstrumentedProductService) PurgeDeletedProducts(ctx context.Context) (int, error) {
+	purged, err := instrument(ctx, s.ops, "PurgeDeletedProducts", "purge", s.next.PurgeDeletedProducts, nil)
+	if err == nil {
+		s.purgedCounter.Add(ctx, int64(purged))
+	}
+	return purged, err
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentedWebhookService wraps any WebhookUseCases with tracing, metrics and logs.
+// Every call gets a WebhookService.<Method> span, and its outcome is counted in webhooks.operations
+// and timed in webhooks.operation.duration.
+type InstrumentedWebhookService struct {
+	next WebhookUseCases
+	ops  *operations
+}
+
+// NewInstrumentedWebhookService instruments next
+func NewInstrumentedWebhookService(
+	next WebhookUseCases,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *InstrumentedWebhookService {
+	return &InstrumentedWebhookService{
+		next: next,
+		ops:  newOperations("WebhookService", "webhooks", "webhook subscription", tracer, meter, logger),
+	}
+}
+
+// CreateSubscription registers a new webhook subscription
+func (s *InstrumentedWebhookService) CreateSubscription(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, error) {
+	return instrument(ctx, s.ops, "CreateSubscription", "create", func(ctx context.Context) (*dto.WebhookResponse, error) {
+		return s.next.CreateSubscription(ctx, req)
+	}, nil)
+}
+
+// GetSubscription retrieves a webhook subscription by ID
+func (s *InstrumentedWebhookService) GetSubscription(ctx context.Context, id string) (*dto.WebhookResponse, error) {
+	return instrument(ctx, s.ops, "GetSubscription", "read", func(ctx context.Context) (*dto.WebhookResponse, error) {
+		return s.next.GetSubscription(ctx, id)
+	}, nil)
+}
+
+// ListSubscriptions retrieves all webhook subscriptions
+func (s *InstrumentedWebhookService) ListSubscriptions(ctx context.Context) ([]*dto.WebhookResponse, error) {
+	return instrument(ctx, s.ops, "ListSubscriptions", "list", s.next.ListSubscriptions, nil)
+}
+
+// DeleteSubscription removes a webhook subscription
+func (s *InstrumentedWebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	return run(ctx, s.ops, "DeleteSubscription", "delete", func(ctx context.Context) error {
+		return s.next.DeleteSubscription(ctx, id)
+	})
+}
+
+// ListDeliveries retrieves the delivery log of a webhook subscription
+func (s *InstrumentedWebhookService) ListDeliveries(ctx context.Context, id string) ([]*dto.WebhookDeliveryResponse, error) {
+	return instrument(ctx, s.ops, "ListDeliveries", "list_deliveries", func(ctx context.Context) ([]*dto.WebhookDeliveryResponse, error) {
+		return s.next.ListDeliveries(ctx, id)
+	}, nil)
+}
+
+// ListDeadLetters retrieves every delivery that exhausted its retry attempts
+func (s *InstrumentedWebhookService) ListDeadLetters(ctx context.Context) ([]*dto.WebhookDeliveryResponse, error) {
+	return instrument(ctx, s.ops, "ListDeadLetters", "list_dead_letters", s.next.ListDeadLetters, nil)
+}
+
+// RetryDelivery moves a dead-lettered delivery back onto the delivery queue
+func (s *InstrumentedWebhookService) RetryDelivery(ctx context.Context, deliveryID string) (*dto.WebhookDeliveryResponse, error) {
+	return instrument(ctx, s.ops, "RetryDelivery", "retry", func(ctx context.Context) (*dto.WebhookDeliveryResponse, error) {
+		return s.next.RetryDelivery(ctx, deliveryID)
+	}, nil)
+}
+
+// HandleEvent queues a delivery of the event for every matching subscription
+func (s *InstrumentedWebhookService) HandleEvent(ctx context.Context, event domain.Event) error {
+	return run(ctx, s.ops, "HandleEvent", "handle_event", func(ctx context.Context) error {
+		return s.next.HandleEvent(ctx, event)
+	})
+}
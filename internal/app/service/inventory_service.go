@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InventoryUseCases defines the stock and reservation operations, implemented by InventoryService and
+// decorated with tracing, metrics and logs by InstrumentedInventoryService
+type InventoryUseCases interface {
+	GetStock(ctx context.Context, productID string) (*dto.StockResponse, error)
+	SetStock(ctx context.Context, productID string, req *dto.SetStockRequest) (*dto.StockResponse, error)
+	ReserveStock(ctx context.Context, productID string, req *dto.CreateReservationRequest) (*dto.ReservationResponse, error)
+	GetReservation(ctx context.Context, productID, reservationID string) (*dto.ReservationResponse, error)
+	ConfirmReservation(ctx context.Context, productID, reservationID string) (*dto.ReservationResponse, error)
+	ReleaseReservation(ctx context.Context, productID, reservationID string) (*dto.ReservationResponse, error)
+	// ExpireReservations is run by the reservation expiry worker rather than on behalf of a caller
+	ExpireReservations(ctx context.Context) error
+}
+
+// InventoryService handles stock and reservation use cases.
+// It annotates the span of the calling InstrumentedInventoryService, which records the outcome of each operation.
+type InventoryService struct {
+	repo                domain.InventoryRepository
+	products            domain.ProductRepository
+	reservationTTL      time.Duration
+	maxReservationTTL   time.Duration
+	retention           time.Duration
+	logger              *slog.Logger
+	expiredReservations metric.Int64Counter
+}
+
+// NewInventoryService creates a new inventory service.
+// Reservations last reservationTTL unless the caller asks for another TTL, capped at maxReservationTTL,
+// and are kept for retention once they are no longer pending.
+func NewInventoryService(
+	repo domain.InventoryRepository,
+	products domain.ProductRepository,
+	reservationTTL time.Duration,
+	maxReservationTTL time.Duration,
+	retention time.Duration,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *InventoryService {
+	// Initialize metrics
+	expiredReservations, _ := meter.Int64Counter(
+		"inventory.reservations.expired",
+		metric.WithDescription("Total number of reservations expired by the background worker"),
+	)
+
+	return &InventoryService{
+		repo:                repo,
+		products:            products,
+		reservationTTL:      reservationTTL,
+		maxReservationTTL:   maxReservationTTL,
+		retention:           retention,
+		logger:              logger,
+		expiredReservations: expiredReservations,
+	}
+}
+
+// GetStock retrieves the stock level of a product
+func (s *InventoryService) GetStock(ctx context.Context, productID string) (*dto.StockResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("product.id", productID))
+
+	if _, err := s.products.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	stock, err := s.repo.GetStock(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("inventory.available", stock.Available()))
+
+	return dto.ToStockResponse(stock), nil
+}
+
+// SetStock replaces the on-hand stock of a product
+func (s *InventoryService) SetStock(ctx context.Context, productID string, req *dto.SetStockRequest) (*dto.StockResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("product.id", productID),
+		attribute.Int("inventory.on_hand", req.OnHand),
+	)
+
+	s.logger.InfoContext(ctx, "Setting stock level",
+		slog.String("product_id", productID),
+		slog.Int("on_hand", req.OnHand),
+	)
+
+	if _, err := s.products.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	stock, err := s.repo.SetOnHand(ctx, productID, req.OnHand)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Stock level set successfully",
+		slog.String("product_id", productID),
+		slog.Int("on_hand", stock.OnHand),
+		slog.Int("reserved", stock.Reserved),
+	)
+
+	return dto.ToStockResponse(stock), nil
+}
+
+// ReserveStock holds stock of a product until the reservation is confirmed, released or expires
+func (s *InventoryService) ReserveStock(ctx context.Context, productID string, req *dto.CreateReservationRequest) (*dto.ReservationResponse, error) {
+	// Compared in seconds, so a huge TTL is capped rather than overflowing the duration
+	ttl := s.reservationTTL
+	if req.TTLSeconds > 0 {
+		ttl = s.maxReservationTTL
+		if req.TTLSeconds < int(s.maxReservationTTL/time.Second) {
+			ttl = time.Duration(req.TTLSeconds) * time.Second
+		}
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("product.id", productID),
+		attribute.Int("reservation.quantity", req.Quantity),
+		attribute.String("reservation.ttl", ttl.String()),
+	)
+
+	s.logger.InfoContext(ctx, "Reserving stock",
+		slog.String("product_id", productID),
+		slog.Int("quantity", req.Quantity),
+	)
+
+	if _, err := s.products.FindByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	reservation, err := domain.NewReservation(productID, req.Quantity, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("reservation.id", reservation.ID))
+
+	stock, err := s.repo.Reserve(ctx, reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("inventory.available", stock.Available()))
+
+	s.logger.InfoContext(ctx, "Stock reserved successfully",
+		slog.String("reservation_id", reservation.ID),
+		slog.String("product_id", productID),
+		slog.Int("available", stock.Available()),
+	)
+
+	return dto.ToReservationResponse(reservation), nil
+}
+
+// GetReservation retrieves a reservation of a product
+func (s *InventoryService) GetReservation(ctx context.Context, productID, reservationID string) (*dto.ReservationResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("product.id", productID),
+		attribute.String("reservation.id", reservationID),
+	)
+
+	reservation, err := s.findReservation(ctx, productID, reservationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToReservationResponse(reservation), nil
+}
+
+// ConfirmReservation commits a pending reservation, permanently removing its stock
+func (s *InventoryService) ConfirmReservation(ctx context.Context, productID, reservationID string) (*dto.ReservationResponse, error) {
+	return s.completeReservation(ctx, productID, reservationID, domain.ReservationConfirmed)
+}
+
+// ReleaseReservation cancels a pending reservation, returning its stock to the available pool
+func (s *InventoryService) ReleaseReservation(ctx context.Context, productID, reservationID string) (*dto.ReservationResponse, error) {
+	return s.completeReservation(ctx, productID, reservationID, domain.ReservationReleased)
+}
+
+// ExpireReservations expires all pending reservations past their TTL and removes the reservations
+// no longer pending for longer than the retention. It is run periodically by the reservation expiry worker.
+func (s *InventoryService) ExpireReservations(ctx context.Context) error {
+	span := trace.SpanFromContext(ctx)
+
+	expired, err := s.repo.ExpireReservations(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	span.SetAttributes(attribute.Int("reservation.expired_count", len(expired)))
+
+	if len(expired) > 0 {
+		s.expiredReservations.Add(ctx, int64(len(expired)))
+		s.logger.InfoContext(ctx, "Expired pending reservations",
+			slog.Int("count", len(expired)),
+		)
+	}
+
+	purged, err := s.repo.PurgeReservations(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return err
+	}
+
+	span.SetAttributes(attribute.Int("reservation.purged_count", purged))
+
+	if purged > 0 {
+		s.logger.InfoContext(ctx, "Purged completed reservations",
+			slog.Int("count", purged),
+		)
+	}
+
+	return nil
+}
+
+// completeReservation moves a pending reservation of a product to its final status
+func (s *InventoryService) completeReservation(
+	ctx context.Context,
+	productID, reservationID string,
+	status domain.ReservationStatus,
+) (*dto.ReservationResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("product.id", productID),
+		attribute.String("reservation.id", reservationID),
+	)
+
+	s.logger.InfoContext(ctx, "Completing reservation",
+		slog.String("reservation_id", reservationID),
+		slog.String("status", string(status)),
+	)
+
+	if _, err := s.findReservation(ctx, productID, reservationID); err != nil {
+		return nil, err
+	}
+
+	reservation, err := s.repo.CompleteReservation(ctx, reservationID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Reservation completed successfully",
+		slog.String("reservation_id", reservationID),
+		slog.String("status", string(reservation.Status)),
+	)
+
+	return dto.ToReservationResponse(reservation), nil
+}
+
+// findReservation retrieves a reservation, treating one that belongs to another product as missing
+func (s *InventoryService) findReservation(ctx context.Context, productID, reservationID string) (*domain.Reservation, error) {
+	reservation, err := s.repo.FindReservation(ctx, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.ProductID != productID {
+		return nil, domain.ErrReservationNotFound
+	}
+	return reservation, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// operations traces, counts, times and logs the calls to one service, for its instrumented decorator.
+// Outcomes are counted in <prefix>.operations and timed in <prefix>.operation.duration, labelled with
+// the operation and a result of success, partial, denied, not_found, conflict, insufficient_stock or failure.
+type operations struct {
+	service  string
+	tracer   trace.Tracer
+	logger   *slog.Logger
+	counter  metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// newOperations creates the instrumentation of a service; spans are named <service>.<Method>,
+// and noun names the operations in the metric descriptions, e.g. product
+func newOperations(service, prefix, noun string, tracer trace.Tracer, meter metric.Meter, logger *slog.Logger) *operations {
+	// Initialize metrics
+	counter, _ := meter.Int64Counter(
+		prefix+".operations",
+		metric.WithDescription("Total number of "+noun+" operations"),
+	)
+
+	duration, _ := meter.Float64Histogram(
+		prefix+".operation.duration",
+		metric.WithDescription("Duration of "+noun+" operations"),
+		metric.WithUnit("s"),
+	)
+
+	return &operations{
+		service:  service,
+		tracer:   tracer,
+		logger:   logger,
+		counter:  counter,
+		duration: duration,
+	}
+}
+
+// instrument runs one service operation in a span and records its result.
+// outcome, when set, classifies successful calls that still did not fully succeed, such as partial batches.
+func instrument[T any](
+	ctx context.Context,
+	ops *operations,
+	method, operation string,
+	fn func(ctx context.Context) (T, error),
+	outcome func(T) string,
+) (T, error) {
+	ctx, span := ops.tracer.Start(ctx, ops.service+"."+method)
+	defer span.End()
+
+	start := time.Now()
+	value, err := fn(ctx)
+
+	result := "success"
+	switch {
+	case err != nil:
+		result = operationResult(err)
+	case outcome != nil:
+		result = outcome(value)
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("operation", operation),
+		attribute.String("result", result),
+	)
+	ops.counter.Add(ctx, 1, attrs)
+	ops.duration.Record(ctx, time.Since(start).Seconds(), attrs)
+
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		level := slog.LevelError
+		if result != "failure" {
+			level = slog.LevelWarn
+		}
+		ops.logger.Log(ctx, level, "Service operation failed",
+			slog.String("service", ops.service),
+			slog.String("operation", operation),
+			slog.String("result", result),
+			slog.String("error", err.Error()),
+		)
+	case result == "failure":
+		span.SetStatus(codes.Error, "Operation rejected")
+	default:
+		span.SetStatus(codes.Ok, "")
+	}
+	return value, err
+}
+
+// run instruments an operation that only returns an error
+func run(ctx context.Context, ops *operations, method, operation string, fn func(ctx context.Context) error) error {
+	_, err := instrument(ctx, ops, method, operation, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, nil)
+	return err
+}
+
+// operationResult classifies the error of a failed operation
+func operationResult(err error) string {
+	switch {
+	case errors.Is(err, auth.ErrForbidden):
+		return "denied"
+	case errors.Is(err, domain.ErrProductNotFound),
+		errors.Is(err, domain.ErrVariantNotFound),
+		errors.Is(err, domain.ErrCategoryNotFound),
+		errors.Is(err, domain.ErrReservationNotFound),
+		errors.Is(err, domain.ErrWebhookNotFound),
+		errors.Is(err, domain.ErrWebhookDeliveryNotFound),
+		errors.Is(err, domain.ErrImageNotFound),
+		errors.Is(err, ErrImportJobNotFound):
+		return "not_found"
+	case errors.Is(err, domain.ErrDuplicateSKU),
+		errors.Is(err, domain.ErrProductConflict),
+		errors.Is(err, domain.ErrCategoryHasChildren),
+		errors.Is(err, domain.ErrCategoryInUse),
+		errors.Is(err, domain.ErrReservationNotPending),
+		errors.Is(err, domain.ErrDeliveryNotDead),
+		errors.Is(err, domain.ErrThumbnailNotReady):
+		return "conflict"
+	case errors.Is(err, domain.ErrInsufficientStock):
+		return "insufficient_stock"
+	default:
+		return "failure"
+	}
+}
@@ -3,56 +3,82 @@ package service
 import (
 	"context"
 	"log/slog"
+	"time"
 
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
 	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
 	"github.com/mrops-br/testing-otlp-api/internal/domain"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
-	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 )
 
-// ProductService handles product use cases
+// ProductUseCases defines the product use cases offered to the transports.
+// ProductService implements them; InstrumentedProductService adds spans, metrics and logs around any implementation.
+type ProductUseCases interface {
+	CreateProduct(ctx context.Context, req *dto.CreateProductRequest) (*dto.ProductResponse, error)
+	CreateProducts(ctx context.Context, reqs []*dto.CreateProductRequest, mode dto.BatchMode) (*dto.BatchCreateResponse, error)
+	GetProductByID(ctx context.Context, id string) (*dto.ProductResponse, error)
+	GetProductBySKU(ctx context.Context, sku string) (*dto.SKUResponse, error)
+	GetProductAsOf(ctx context.Context, id string, asOf time.Time) (*dto.ProductResponse, error)
+	GetPriceHistory(ctx context.Context, id string) ([]*dto.PriceChangeResponse, error)
+	ListProducts(ctx context.Context, includeDeleted bool) ([]*dto.ProductResponse, error)
+	SearchProducts(ctx context.Context, query string) ([]*dto.ProductResponse, error)
+	ExportProducts(ctx context.Context, fn func(*dto.ProductResponse) error) error
+	UpdateProduct(ctx context.Context, id string, req *dto.UpdateProductRequest) (*dto.ProductResponse, error)
+	DeleteProduct(ctx context.Context, id string) error
+	RestoreProduct(ctx context.Context, id string) (*dto.ProductResponse, error)
+	// PurgeDeletedProducts is run by the purge worker rather than on behalf of a caller, so it is not authorized
+	PurgeDeletedProducts(ctx context.Context) (int, error)
+}
+
+// ProductService handles product use cases.
+// It annotates the span of the calling InstrumentedProductService, which records the outcome of each operation.
 type ProductService struct {
-	repo                  domain.ProductRepository
-	tracer                trace.Tracer
-	logger                *slog.Logger
-	productCreatedCounter metric.Int64Counter
-	productOperations     metric.Int64Counter
+	repo           domain.ProductRepository
+	categories     domain.CategoryRepository
+	categoryLock   *CategoryLock
+	authz          *auth.Authorizer
+	trashRetention time.Duration
+	audit          *AuditService
+	tracer         trace.Tracer
+	logger         *slog.Logger
 }
 
-// NewProductService creates a new product service
+// NewProductService creates a new product service.
+// categoryLock is shared with the category service, so a category cannot be deleted as a product is linked to it.
+// authz enforces the role required by each operation; nil allows every caller.
+// Deleted products can be restored until they have been in the trash for trashRetention.
+// Every stored mutation is recorded in audit; nil records nothing.
 func NewProductService(
 	repo domain.ProductRepository,
+	categories domain.CategoryRepository,
+	categoryLock *CategoryLock,
+	authz *auth.Authorizer,
+	trashRetention time.Duration,
+	audit *AuditService,
 	tracer trace.Tracer,
-	meter metric.Meter,
 	logger *slog.Logger,
 ) *ProductService {
-	// Initialize metrics
-	productCreatedCounter, _ := meter.Int64Counter(
-		"products.created.total",
-		metric.WithDescription("Total number of products created"),
-	)
-
-	productOperations, _ := meter.Int64Counter(
-		"products.operations",
-		metric.WithDescription("Total number of product operations"),
-	)
-
 	return &ProductService{
-		repo:                  repo,
-		tracer:                tracer,
-		logger:                logger,
-		productCreatedCounter: productCreatedCounter,
-		productOperations:     productOperations,
+		repo:           repo,
+		categories:     categories,
+		categoryLock:   categoryLock,
+		authz:          authz,
+		trashRetention: trashRetention,
+		audit:          audit,
+		tracer:         tracer,
+		logger:         logger,
 	}
 }
 
 // CreateProduct creates a new product
 func (s *ProductService) CreateProduct(ctx context.Context, req *dto.CreateProductRequest) (*dto.ProductResponse, error) {
-	ctx, span := s.tracer.Start(ctx, "ProductService.CreateProduct")
-	defer span.End()
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.CreateProduct"); err != nil {
+		return nil, err
+	}
 
+	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(
 		attribute.String("product.name", req.Name),
 		attribute.Float64("product.price", req.Price),
@@ -66,61 +92,46 @@ func (s *ProductService) CreateProduct(ctx context.Context, req *dto.CreateProdu
 	// Create domain entity
 	product, err := domain.NewProduct(req.Name, req.Description, req.Price)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Validation failed")
-		s.logger.ErrorContext(ctx, "Failed to create product",
-			slog.String("error", err.Error()),
-		)
-		s.productOperations.Add(ctx, 1,
-			metric.WithAttributes(
-				attribute.String("operation", "create"),
-				attribute.String("result", "failure"),
-			),
-		)
 		return nil, err
 	}
 
-	span.SetAttributes(attribute.String("product.id", product.ID))
+	span.SetAttributes(
+		attribute.String("product.id", product.ID),
+		attribute.Int("product.category_count", len(req.CategoryIDs)),
+	)
+
+	// Link to categories, which must already exist and cannot be deleted until the product is stored
+	defer s.categoryLock.link()()
+	if err := s.checkCategories(ctx, req.CategoryIDs); err != nil {
+		return nil, err
+	}
+	product.CategoryIDs = req.CategoryIDs
+
+	// SKUs are checked against other products when the product is stored
+	if err := product.SetVariants(dto.ToVariants(req.Variants)); err != nil {
+		return nil, err
+	}
 
 	// Store in repository
 	if err := s.repo.Create(ctx, product); err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to store product")
-		s.logger.ErrorContext(ctx, "Failed to store product",
-			slog.String("error", err.Error()),
-		)
-		s.productOperations.Add(ctx, 1,
-			metric.WithAttributes(
-				attribute.String("operation", "create"),
-				attribute.String("result", "failure"),
-			),
-		)
 		return nil, err
 	}
-
-	// Record metrics
-	s.productCreatedCounter.Add(ctx, 1)
-	s.productOperations.Add(ctx, 1,
-		metric.WithAttributes(
-			attribute.String("operation", "create"),
-			attribute.String("result", "success"),
-		),
-	)
+	s.audit.Record(ctx, domain.NewAuditEntry(domain.AuditCreate, nil, product))
 
 	s.logger.InfoContext(ctx, "Product created successfully",
 		slog.String("product_id", product.ID),
 	)
 
-	span.SetStatus(codes.Ok, "Product created successfully")
 	return dto.ToProductResponse(product), nil
 }
 
 // GetProductByID retrieves a product by ID
 func (s *ProductService) GetProductByID(ctx context.Context, id string) (*dto.ProductResponse, error) {
-	ctx, span := s.tracer.Start(ctx, "ProductService.GetProductByID")
-	defer span.End()
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.GetProductByID"); err != nil {
+		return nil, err
+	}
 
-	span.SetAttributes(attribute.String("product.id", id))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("product.id", id))
 
 	s.logger.InfoContext(ctx, "Getting product by ID",
 		slog.String("product_id", id),
@@ -128,71 +139,568 @@ func (s *ProductService) GetProductByID(ctx context.Context, id string) (*dto.Pr
 
 	product, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Product not found")
-		s.logger.WarnContext(ctx, "Product not found",
-			slog.String("product_id", id),
-		)
-		s.productOperations.Add(ctx, 1,
-			metric.WithAttributes(
-				attribute.String("operation", "read"),
-				attribute.String("result", "not_found"),
-			),
-		)
 		return nil, err
 	}
 
-	s.productOperations.Add(ctx, 1,
-		metric.WithAttributes(
-			attribute.String("operation", "read"),
-			attribute.String("result", "success"),
-		),
+	s.logger.InfoContext(ctx, "Product retrieved successfully",
+		slog.String("product_id", id),
+	)
+
+	return dto.ToProductResponse(product), nil
+}
+
+// GetProductBySKU retrieves the variant with the given SKU and the product it belongs to
+func (s *ProductService) GetProductBySKU(ctx context.Context, sku string) (*dto.SKUResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.GetProductBySKU"); err != nil {
+		return nil, err
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("product.variant.sku", sku))
+
+	s.logger.InfoContext(ctx, "Getting product by SKU",
+		slog.String("sku", sku),
 	)
 
+	product, err := s.repo.FindBySKU(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, ok := product.Variant(sku)
+	if !ok {
+		return nil, domain.ErrVariantNotFound
+	}
+
+	span.SetAttributes(attribute.String("product.id", product.ID))
+
 	s.logger.InfoContext(ctx, "Product retrieved successfully",
+		slog.String("product_id", product.ID),
+		slog.String("sku", sku),
+	)
+
+	return &dto.SKUResponse{
+		Variant: dto.ToVariantResponse(variant, product.Price),
+		Product: dto.ToProductResponse(product),
+	}, nil
+}
+
+// GetProductAsOf retrieves a product as it was at asOf, with the price then in effect.
+// Products deleted since asOf are found as long as they have not been purged.
+func (s *ProductService) GetProductAsOf(ctx context.Context, id string, asOf time.Time) (*dto.ProductResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.GetProductAsOf"); err != nil {
+		return nil, err
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("product.id", id),
+		attribute.String("product.as_of", asOf.Format(time.RFC3339)),
+	)
+
+	s.logger.InfoContext(ctx, "Getting product as of a point in time",
 		slog.String("product_id", id),
+		slog.Time("as_of", asOf),
 	)
 
-	span.SetStatus(codes.Ok, "Product retrieved successfully")
-	return dto.ToProductResponse(product), nil
+	product, err := s.repo.FindByID(ctx, id)
+	if err == domain.ErrProductNotFound {
+		product, err = s.repo.FindDeletedByID(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	past, ok := product.AsOf(asOf)
+	if !ok {
+		return nil, domain.ErrProductNotFound
+	}
+
+	span.SetAttributes(attribute.Float64("product.price", past.Price))
+
+	s.logger.InfoContext(ctx, "Product retrieved successfully",
+		slog.String("product_id", id),
+		slog.Time("as_of", asOf),
+	)
+
+	return dto.ToProductResponse(past), nil
 }
 
-// ListProducts retrieves all products
-func (s *ProductService) ListProducts(ctx context.Context) ([]*dto.ProductResponse, error) {
-	ctx, span := s.tracer.Start(ctx, "ProductService.ListProducts")
-	defer span.End()
+// GetPriceHistory retrieves every price a product has had, oldest first
+func (s *ProductService) GetPriceHistory(ctx context.Context, id string) ([]*dto.PriceChangeResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.GetPriceHistory"); err != nil {
+		return nil, err
+	}
 
-	s.logger.InfoContext(ctx, "Listing all products")
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("product.id", id))
+
+	s.logger.InfoContext(ctx, "Getting product price history",
+		slog.String("product_id", id),
+	)
+
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := product.Prices()
+	span.SetAttributes(attribute.Int("product.price_changes", len(prices)))
+
+	s.logger.InfoContext(ctx, "Product price history retrieved successfully",
+		slog.String("product_id", id),
+		slog.Int("count", len(prices)),
+	)
+
+	return dto.ToPriceHistoryResponse(prices), nil
+}
+
+// ListProducts retrieves all products, followed by the soft-deleted ones when includeDeleted is set
+func (s *ProductService) ListProducts(ctx context.Context, includeDeleted bool) ([]*dto.ProductResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.ListProducts"); err != nil {
+		return nil, err
+	}
+	if includeDeleted {
+		if err := s.authz.AuthorizeOperation(ctx, "ProductService.ListDeletedProducts"); err != nil {
+			return nil, err
+		}
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Bool("product.include_deleted", includeDeleted))
+
+	s.logger.InfoContext(ctx, "Listing all products",
+		slog.Bool("include_deleted", includeDeleted),
+	)
 
 	products, err := s.repo.FindAll(ctx)
 	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, "Failed to retrieve products")
-		s.logger.ErrorContext(ctx, "Failed to list products",
-			slog.String("error", err.Error()),
-		)
-		s.productOperations.Add(ctx, 1,
-			metric.WithAttributes(
-				attribute.String("operation", "list"),
-				attribute.String("result", "failure"),
-			),
-		)
 		return nil, err
 	}
 
+	if includeDeleted {
+		deleted, err := s.repo.FindDeleted(ctx, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, deleted...)
+	}
+
 	span.SetAttributes(attribute.Int("product.count", len(products)))
 
-	s.productOperations.Add(ctx, 1,
-		metric.WithAttributes(
-			attribute.String("operation", "list"),
-			attribute.String("result", "success"),
-		),
+	s.logger.InfoContext(ctx, "Products listed successfully",
+		slog.Int("count", len(products)),
 	)
 
-	s.logger.InfoContext(ctx, "Products listed successfully",
+	return dto.ToProductResponseList(products), nil
+}
+
+// SearchProducts retrieves all products whose name or description matches the query
+func (s *ProductService) SearchProducts(ctx context.Context, query string) ([]*dto.ProductResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.SearchProducts"); err != nil {
+		return nil, err
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("product.search.query", query))
+
+	s.logger.InfoContext(ctx, "Searching products",
+		slog.String("query", query),
+	)
+
+	products, err := s.repo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("product.count", len(products)))
+
+	s.logger.InfoContext(ctx, "Products searched successfully",
+		slog.String("query", query),
 		slog.Int("count", len(products)),
 	)
 
-	span.SetStatus(codes.Ok, "Products listed successfully")
 	return dto.ToProductResponseList(products), nil
 }
+
+// exportPageSize is the number of products read from the repository at a time during an export
+const exportPageSize = 500
+
+// ExportProducts passes every product to fn in ID order, reading the catalogue page by page
+// so it never has to be held in memory at once. It stops at the first error returned by fn.
+func (s *ProductService) ExportProducts(ctx context.Context, fn func(*dto.ProductResponse) error) error {
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.ExportProducts"); err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Exporting products")
+
+	exported := 0
+	after := ""
+	for {
+		products, err := s.repo.FindPage(ctx, after, exportPageSize)
+		if err == nil {
+			for _, product := range products {
+				if err = fn(dto.ToProductResponse(product)); err != nil {
+					break
+				}
+				exported++
+			}
+		}
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to export products",
+				slog.Int("exported", exported),
+				slog.String("error", err.Error()),
+			)
+			return err
+		}
+		if len(products) < exportPageSize {
+			break
+		}
+		after = products[len(products)-1].ID
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("product.count", exported))
+
+	s.logger.InfoContext(ctx, "Products exported successfully",
+		slog.Int("count", exported),
+	)
+
+	return nil
+}
+
+// UpdateProduct replaces the editable fields of a product
+func (s *ProductService) UpdateProduct(ctx context.Context, id string, req *dto.UpdateProductRequest) (*dto.ProductResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.UpdateProduct"); err != nil {
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("product.id", id),
+		attribute.String("product.name", req.Name),
+		attribute.Float64("product.price", req.Price),
+	)
+
+	s.logger.InfoContext(ctx, "Updating product",
+		slog.String("product_id", id),
+		slog.String("name", req.Name),
+		slog.Float64("price", req.Price),
+	)
+
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer s.categoryLock.link()()
+	if err := s.checkCategories(ctx, req.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	// Variants are only replaced when the request carries them, even as an empty list
+	before := product.Clone()
+	if req.Variants != nil {
+		if err := product.SetVariants(dto.ToVariants(req.Variants)); err != nil {
+			return nil, err
+		}
+	}
+	if err := product.Update(req.Name, req.Description, req.Price, req.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, domain.NewAuditEntry(domain.AuditUpdate, before, product))
+
+	s.logger.InfoContext(ctx, "Product updated successfully",
+		slog.String("product_id", id),
+	)
+
+	return dto.ToProductResponse(product), nil
+}
+
+// DeleteProduct soft-deletes a product; it can be restored until it is purged
+func (s *ProductService) DeleteProduct(ctx context.Context, id string) error {
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.DeleteProduct"); err != nil {
+		return err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("product.id", id))
+
+	s.logger.InfoContext(ctx, "Deleting product",
+		slog.String("product_id", id),
+	)
+
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	before := product.Clone()
+	product.MarkDeleted()
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, domain.NewAuditEntry(domain.AuditDelete, before, product))
+
+	s.logger.InfoContext(ctx, "Product deleted successfully",
+		slog.String("product_id", id),
+		slog.Time("purge_after", product.DeletedAt.Add(s.trashRetention)),
+	)
+
+	return nil
+}
+
+// RestoreProduct brings back a soft-deleted product that has not been purged yet
+func (s *ProductService) RestoreProduct(ctx context.Context, id string) (*dto.ProductResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.RestoreProduct"); err != nil {
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("product.id", id))
+
+	s.logger.InfoContext(ctx, "Restoring product",
+		slog.String("product_id", id),
+	)
+
+	// A restored product links to its categories again, so none of them can be deleted meanwhile
+	defer s.categoryLock.link()()
+
+	product, err := s.repo.FindDeletedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	before := product.Clone()
+	if err := product.Restore(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, domain.NewAuditEntry(domain.AuditRestore, before, product))
+
+	s.logger.InfoContext(ctx, "Product restored successfully",
+		slog.String("product_id", id),
+	)
+
+	return dto.ToProductResponse(product), nil
+}
+
+// PurgeDeletedProducts permanently removes the products deleted longer than the trash retention ago
+// and returns how many were removed
+func (s *ProductService) PurgeDeletedProducts(ctx context.Context) (int, error) {
+	before := time.Now().Add(-s.trashRetention)
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("product.deleted_before", before.Format(time.RFC3339)))
+
+	purged, err := s.repo.Purge(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Int("product.count", len(purged)))
+
+	entries := make([]*domain.AuditEntry, len(purged))
+	for i, product := range purged {
+		entries[i] = domain.NewAuditEntry(domain.AuditPurge, product, nil)
+		entries[i].Actor = SystemActor
+	}
+	s.audit.Record(ctx, entries...)
+
+	if len(purged) > 0 {
+		s.logger.InfoContext(ctx, "Deleted products purged",
+			slog.Int("count", len(purged)),
+		)
+	}
+
+	return len(purged), nil
+}
+
+// CreateProducts creates a batch of products with a single repository write.
+// In atomic mode nothing is created when any item is invalid; in best-effort mode the valid items are created.
+// An item with a variant SKU already in use, by a stored product or an earlier item, is invalid.
+func (s *ProductService) CreateProducts(ctx context.Context, reqs []*dto.CreateProductRequest, mode dto.BatchMode) (*dto.BatchCreateResponse, error) {
+	if err := s.authz.AuthorizeOperation(ctx, "ProductService.CreateProducts"); err != nil {
+		return nil, err
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.Int("batch.size", len(reqs)),
+		attribute.String("batch.mode", string(mode)),
+	)
+
+	if len(reqs) == 0 {
+		return nil, domain.ErrEmptyBatch
+	}
+
+	s.logger.InfoContext(ctx, "Creating product batch",
+		slog.Int("size", len(reqs)),
+		slog.String("mode", string(mode)),
+	)
+
+	// The categories of the items cannot be deleted until the batch is stored
+	defer s.categoryLock.link()()
+
+	claimed, err := s.trashedSKUs(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.BatchCreateResponse{Mode: mode, Items: make([]*dto.BatchItemResult, len(reqs))}
+	products := make([]*domain.Product, 0, len(reqs))
+	for i, req := range reqs {
+		product, err := s.prepareProduct(ctx, i, req)
+		if err == nil {
+			err = s.claimSKUs(ctx, product, claimed)
+		}
+		if err != nil {
+			result.Items[i] = &dto.BatchItemResult{Index: i, Status: dto.BatchItemFailed, Error: err.Error()}
+			result.Failed++
+			continue
+		}
+		result.Items[i] = &dto.BatchItemResult{Index: i, Status: dto.BatchItemCreated, Product: dto.ToProductResponse(product)}
+		products = append(products, product)
+	}
+
+	// Atomic batches with an invalid item create nothing
+	if mode == dto.BatchAtomic && result.Failed > 0 {
+		for _, item := range result.Items {
+			if item.Status == dto.BatchItemCreated {
+				item.Status, item.Product = dto.BatchItemSkipped, nil
+			}
+		}
+		s.logger.WarnContext(ctx, "Product batch rejected",
+			slog.Int("failed", result.Failed),
+		)
+		return result, nil
+	}
+
+	if len(products) > 0 {
+		if err := s.repo.CreateMany(ctx, products); err != nil {
+			return nil, err
+		}
+		entries := make([]*domain.AuditEntry, len(products))
+		for i, product := range products {
+			entries[i] = domain.NewAuditEntry(domain.AuditCreate, nil, product)
+		}
+		s.audit.Record(ctx, entries...)
+	}
+	result.Created = len(products)
+
+	span.SetAttributes(
+		attribute.Int("batch.created", result.Created),
+		attribute.Int("batch.failed", result.Failed),
+	)
+
+	s.logger.InfoContext(ctx, "Product batch created",
+		slog.Int("created", result.Created),
+		slog.Int("failed", result.Failed),
+	)
+
+	return result, nil
+}
+
+// prepareProduct validates one batch item and builds its product, in a child span of the batch
+func (s *ProductService) prepareProduct(ctx context.Context, index int, req *dto.CreateProductRequest) (*domain.Product, error) {
+	ctx, span := s.tracer.Start(ctx, "ProductService.PrepareProduct")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("batch.index", index),
+		attribute.String("product.name", req.Name),
+		attribute.Float64("product.price", req.Price),
+	)
+
+	product, err := domain.NewProduct(req.Name, req.Description, req.Price)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Validation failed")
+		return nil, err
+	}
+
+	if err := s.checkCategories(ctx, req.CategoryIDs); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Unknown category")
+		return nil, err
+	}
+	product.CategoryIDs = req.CategoryIDs
+
+	if err := product.SetVariants(dto.ToVariants(req.Variants)); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Invalid variants")
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("product.id", product.ID))
+	span.SetStatus(codes.Ok, "Product prepared")
+	return product, nil
+}
+
+// trashedSKUs returns the variant SKUs held by products in the trash, which stay taken until they are
+// purged, unless no item of the batch has variants
+func (s *ProductService) trashedSKUs(ctx context.Context, reqs []*dto.CreateProductRequest) (map[string]bool, error) {
+	claimed := make(map[string]bool)
+	hasVariants := false
+	for _, req := range reqs {
+		if len(req.Variants) > 0 {
+			hasVariants = true
+			break
+		}
+	}
+	if !hasVariants {
+		return claimed, nil
+	}
+
+	trashed, err := s.repo.FindDeleted(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range trashed {
+		for _, variant := range product.Variants {
+			claimed[variant.SKU] = true
+		}
+	}
+	return claimed, nil
+}
+
+// claimSKUs returns domain.ErrDuplicateSKU if a variant SKU of the batch item is used by a stored product
+// or an earlier item of the batch, and otherwise claims its SKUs for the batch.
+// The repository still checks the SKUs when the batch is stored, as another write may claim one meanwhile.
+func (s *ProductService) claimSKUs(ctx context.Context, product *domain.Product, claimed map[string]bool) error {
+	for _, variant := range product.Variants {
+		if claimed[variant.SKU] {
+			return domain.ErrDuplicateSKU
+		}
+		if _, err := s.repo.FindBySKU(ctx, variant.SKU); err == nil {
+			return domain.ErrDuplicateSKU
+		} else if err != domain.ErrVariantNotFound {
+			return err
+		}
+	}
+	for _, variant := range product.Variants {
+		claimed[variant.SKU] = true
+	}
+	return nil
+}
+
+// checkCategories returns domain.ErrUnknownCategory unless every category exists
+func (s *ProductService) checkCategories(ctx context.Context, categoryIDs []string) error {
+	for _, categoryID := range categoryIDs {
+		if _, err := s.categories.FindByID(ctx, categoryID); err != nil {
+			if err == domain.ErrCategoryNotFound {
+				err = domain.ErrUnknownCategory
+			}
+			s.logger.WarnContext(ctx, "Product references an unknown category",
+				slog.String("category_id", categoryID),
+			)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/repository/memory"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// newTestProductService creates a product service over in-memory repositories, with no authorization or audit
+func newTestProductService() (*ProductService, *memory.ProductRepository) {
+	tracer := tracenoop.NewTracerProvider().Tracer("test")
+	logger := slog.New(slog.DiscardHandler)
+	repo := memory.NewProductRepository(memory.NewOutbox())
+	categories := memory.NewCategoryRepository()
+	return NewProductService(repo, categories, NewCategoryLock(), nil, time.Hour, nil, tracer, logger), repo
+}
+
+// batchItem returns a batch item with one variant per SKU
+func batchItem(name string, skus ...string) *dto.CreateProductRequest {
+	req := &dto.CreateProductRequest{Name: name, Price: 10}
+	for _, sku := range skus {
+		req.Variants = append(req.Variants, &dto.VariantRequest{SKU: sku})
+	}
+	return req
+}
+
+func TestCreateProductsSKUCollisions(t *testing.T) {
+	tests := []struct {
+		name    string
+		stored  string // SKU of a product stored before the batch, if any
+		trashed bool   // whether the stored product is soft-deleted
+		mode    dto.BatchMode
+		items   []*dto.CreateProductRequest
+		want    []string
+	}{
+		{
+			name:   "best effort with a SKU of a stored product",
+			stored: "SKU-1",
+			mode:   dto.BatchBestEffort,
+			items:  []*dto.CreateProductRequest{batchItem("a", "SKU-2"), batchItem("b", "SKU-1"), batchItem("c")},
+			want:   []string{dto.BatchItemCreated, dto.BatchItemFailed, dto.BatchItemCreated},
+		},
+		{
+			name:    "best effort with a SKU of a trashed product",
+			stored:  "SKU-1",
+			trashed: true,
+			mode:    dto.BatchBestEffort,
+			items:   []*dto.CreateProductRequest{batchItem("a", "SKU-1"), batchItem("b", "SKU-2")},
+			want:    []string{dto.BatchItemFailed, dto.BatchItemCreated},
+		},
+		{
+			name:  "best effort with a SKU repeated within the batch",
+			mode:  dto.BatchBestEffort,
+			items: []*dto.CreateProductRequest{batchItem("a", "SKU-1"), batchItem("b", "SKU-2", "SKU-1"), batchItem("c", "SKU-2")},
+			want:  []string{dto.BatchItemCreated, dto.BatchItemFailed, dto.BatchItemCreated},
+		},
+		{
+			name:   "atomic with a SKU of a stored product",
+			stored: "SKU-1",
+			mode:   dto.BatchAtomic,
+			items:  []*dto.CreateProductRequest{batchItem("a", "SKU-2"), batchItem("b", "SKU-1")},
+			want:   []string{dto.BatchItemSkipped, dto.BatchItemFailed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, repo := newTestProductService()
+
+			if tt.stored != "" {
+				product, _ := domain.NewProduct("stored", "", 5)
+				if err := product.SetVariants([]domain.Variant{{SKU: tt.stored}}); err != nil {
+					t.Fatalf("SetVariants: %v", err)
+				}
+				if err := repo.Create(ctx, product); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				if tt.trashed {
+					product.MarkDeleted()
+					if err := repo.Update(ctx, product); err != nil {
+						t.Fatalf("Update: %v", err)
+					}
+				}
+			}
+
+			result, err := svc.CreateProducts(ctx, tt.items, tt.mode)
+			if err != nil {
+				t.Fatalf("CreateProducts: %v", err)
+			}
+
+			created := 0
+			for i, item := range result.Items {
+				if item.Status != tt.want[i] {
+					t.Errorf("item %d: status %q, want %q (%s)", i, item.Status, tt.want[i], item.Error)
+				}
+				if item.Status == dto.BatchItemFailed && item.Error != domain.ErrDuplicateSKU.Error() {
+					t.Errorf("item %d: error %q, want %q", i, item.Error, domain.ErrDuplicateSKU)
+				}
+				if item.Status == dto.BatchItemCreated {
+					created++
+				}
+			}
+			if result.Created != created {
+				t.Errorf("created %d, want %d", result.Created, created)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WebhookUseCases defines the webhook subscription operations, implemented by WebhookService and
+// decorated with tracing, metrics and logs by InstrumentedWebhookService
+type WebhookUseCases interface {
+	CreateSubscription(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, error)
+	GetSubscription(ctx context.Context, id string) (*dto.WebhookResponse, error)
+	ListSubscriptions(ctx context.Context) ([]*dto.WebhookResponse, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, id string) ([]*dto.WebhookDeliveryResponse, error)
+	ListDeadLetters(ctx context.Context) ([]*dto.WebhookDeliveryResponse, error)
+	RetryDelivery(ctx context.Context, deliveryID string) (*dto.WebhookDeliveryResponse, error)
+	// HandleEvent is registered on the in-process event bus rather than called on behalf of a caller
+	HandleEvent(ctx context.Context, event domain.Event) error
+}
+
+// WebhookService handles webhook subscription use cases and queues deliveries for product events.
+// It annotates the span of the calling InstrumentedWebhookService, which records the outcome of each operation.
+type WebhookService struct {
+	repo                 domain.WebhookRepository
+	allowPrivateNetworks bool
+	logger               *slog.Logger
+	queuedDeliveries     metric.Int64Counter
+}
+
+// NewWebhookService creates a new webhook service.
+// Subscriptions to loopback, link-local and private addresses are rejected unless allowPrivateNetworks is set.
+func NewWebhookService(
+	repo domain.WebhookRepository,
+	allowPrivateNetworks bool,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *WebhookService {
+	// Initialize metrics
+	queuedDeliveries, _ := meter.Int64Counter(
+		"webhooks.deliveries.queued",
+		metric.WithDescription("Total number of webhook deliveries queued"),
+	)
+
+	return &WebhookService{
+		repo:                 repo,
+		allowPrivateNetworks: allowPrivateNetworks,
+		logger:               logger,
+		queuedDeliveries:     queuedDeliveries,
+	}
+}
+
+// CreateSubscription registers a new webhook subscription
+func (s *WebhookService) CreateSubscription(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.WebhookResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.StringSlice("webhook.event_types", req.EventTypes))
+
+	eventTypes := make([]domain.EventType, len(req.EventTypes))
+	for i, t := range req.EventTypes {
+		eventTypes[i] = domain.EventType(t)
+	}
+
+	subscription, err := domain.NewWebhookSubscription(req.URL, req.Secret, eventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("webhook.id", subscription.ID))
+
+	// The API must not be usable to reach services that are only reachable from inside its network
+	if !s.allowPrivateNetworks && subscription.TargetsPrivateNetwork() {
+		return nil, domain.ErrPrivateWebhookURL
+	}
+
+	if err := s.repo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Webhook subscription created successfully",
+		slog.String("webhook_id", subscription.ID),
+		slog.String("url", subscription.URL),
+	)
+
+	// The secret is only ever returned when the subscription is created
+	response := dto.ToWebhookResponse(subscription)
+	response.Secret = subscription.Secret
+	return response, nil
+}
+
+// GetSubscription retrieves a webhook subscription by ID
+func (s *WebhookService) GetSubscription(ctx context.Context, id string) (*dto.WebhookResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("webhook.id", id))
+
+	subscription, err := s.repo.FindSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ToWebhookResponse(subscription), nil
+}
+
+// ListSubscriptions retrieves all webhook subscriptions
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]*dto.WebhookResponse, error) {
+	subscriptions, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("webhook.count", len(subscriptions)))
+
+	return dto.ToWebhookResponseList(subscriptions), nil
+}
+
+// DeleteSubscription removes a webhook subscription
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("webhook.id", id))
+
+	if err := s.repo.DeleteSubscription(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Webhook subscription deleted successfully",
+		slog.String("webhook_id", id),
+	)
+
+	return nil
+}
+
+// ListDeliveries retrieves the delivery log of a webhook subscription
+func (s *WebhookService) ListDeliveries(ctx context.Context, id string) ([]*dto.WebhookDeliveryResponse, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("webhook.id", id))
+
+	if _, err := s.repo.FindSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("webhook.delivery.count", len(deliveries)))
+
+	return dto.ToWebhookDeliveryResponseList(deliveries), nil
+}
+
+// ListDeadLetters retrieves every delivery that exhausted its retry attempts
+func (s *WebhookService) ListDeadLetters(ctx context.Context) ([]*dto.WebhookDeliveryResponse, error) {
+	deliveries, err := s.repo.ListDeadLetters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("webhook.delivery.count", len(deliveries)))
+
+	return dto.ToWebhookDeliveryResponseList(deliveries), nil
+}
+
+// RetryDelivery moves a dead-lettered delivery back onto the delivery queue
+func (s *WebhookService) RetryDelivery(ctx context.Context, deliveryID string) (*dto.WebhookDeliveryResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("webhook.delivery.id", deliveryID))
+
+	delivery, err := s.repo.FindDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := delivery.Requeue(); err != nil {
+		return nil, err
+	}
+
+	// Attempts of the retried delivery belong to the trace of the retry request
+	delivery.TraceContext = injectTraceContext(ctx)
+
+	if err := s.repo.SaveDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Webhook delivery requeued",
+		slog.String("delivery_id", deliveryID),
+	)
+
+	return dto.ToWebhookDeliveryResponse(delivery), nil
+}
+
+// HandleEvent queues a delivery of the event for every matching subscription.
+// It is registered as a handler on the in-process event bus.
+func (s *WebhookService) HandleEvent(ctx context.Context, event domain.Event) error {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.String("event.id", event.ID),
+		attribute.String("event.type", string(event.Type)),
+	)
+
+	subscriptions, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(dto.ToEventResponse(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	// Deliveries carry the trace context of this handler so every attempt,
+	// including retries, shows up under the trace that raised it
+	traceContext := injectTraceContext(ctx)
+
+	queued := 0
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(event.Type) {
+			continue
+		}
+
+		delivery := domain.NewWebhookDelivery(subscription.ID, event, payload, traceContext)
+		if err := s.repo.SaveDelivery(ctx, delivery); err != nil {
+			return err
+		}
+		queued++
+	}
+
+	span.SetAttributes(attribute.Int("webhook.delivery.count", queued))
+
+	if queued > 0 {
+		s.queuedDeliveries.Add(ctx, int64(queued),
+			metric.WithAttributes(attribute.String("event.type", string(event.Type))),
+		)
+		s.logger.InfoContext(ctx, "Webhook deliveries queued",
+			slog.String("event_id", event.ID),
+			slog.Int("count", queued),
+		)
+	}
+
+	return nil
+}
+
+// injectTraceContext captures the trace context of ctx so later work can be parented to it
+func injectTraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditOperation identifies the kind of product mutation an audit entry records
+type AuditOperation string
+
+const (
+	AuditCreate  AuditOperation = "create"
+	AuditUpdate  AuditOperation = "update"
+	AuditDelete  AuditOperation = "delete"
+	AuditRestore AuditOperation = "restore"
+	AuditPurge   AuditOperation = "purge"
+)
+
+// AuditEntry records who changed a product, how and when
+type AuditEntry struct {
+	ID        string
+	ProductID string
+	Operation AuditOperation
+	// Actor is the ID of the principal that made the change, "anonymous" without one, or "system" for background work
+	Actor string
+	// Changes lists the fields that differ between the product before and after the mutation
+	Changes    []AuditChange
+	RequestID  string
+	TraceID    string
+	OccurredAt time.Time
+}
+
+// AuditChange is the before and after value of one product field; a nil side means the product did not exist.
+// In entries built by NewAuditEntry the values are the generic types JSON decodes to, e.g. float64 and string.
+type AuditChange struct {
+	Field  string
+	Before any
+	After  any
+}
+
+// NewAuditEntry creates an audit entry for a mutation of a product from before to after,
+// either of which is nil when the product did not exist on that side.
+// The changed values are normalised to JSON types, so an entry reads the same before and after it is
+// stored in a file and loaded again. The actor and request are filled in when the entry is recorded.
+func NewAuditEntry(operation AuditOperation, before, after *Product) *AuditEntry {
+	productID := ""
+	if after != nil {
+		productID = after.ID
+	} else if before != nil {
+		productID = before.ID
+	}
+
+	return &AuditEntry{
+		ID:         uuid.New().String(),
+		ProductID:  productID,
+		Operation:  operation,
+		Changes:    jsonChanges(DiffProducts(before, after)),
+		OccurredAt: time.Now(),
+	}
+}
+
+// jsonChanges replaces the values of changes with what they decode to once encoded as JSON
+func jsonChanges(changes []AuditChange) []AuditChange {
+	for i := range changes {
+		changes[i].Before = jsonValue(changes[i].Before)
+		changes[i].After = jsonValue(changes[i].After)
+	}
+	return changes
+}
+
+// jsonValue round-trips v through JSON; audited values always encode, so v is only kept as is if it can't
+func jsonValue(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return v
+	}
+	return decoded
+}
+
+// DiffProducts returns the audited fields that differ between two versions of a product
+func DiffProducts(before, after *Product) []AuditChange {
+	fields := func(p *Product) map[string]any {
+		if p == nil {
+			return map[string]any{}
+		}
+		values := map[string]any{
+			"name":        p.Name,
+			"description": p.Description,
+			"price":       p.Price,
+		}
+		if len(p.CategoryIDs) > 0 {
+			values["category_ids"] = append([]string(nil), p.CategoryIDs...)
+		}
+		if len(p.Variants) > 0 {
+			values["variants"] = auditVariants(p.Variants)
+		}
+		if p.DeletedAt != nil {
+			values["deleted_at"] = *p.DeletedAt
+		}
+		return values
+	}
+	old, updated := fields(before), fields(after)
+
+	changes := make([]AuditChange, 0)
+	for _, field := range []string{"name", "description", "price", "category_ids", "variants", "deleted_at"} {
+		if !reflect.DeepEqual(old[field], updated[field]) {
+			changes = append(changes, AuditChange{Field: field, Before: old[field], After: updated[field]})
+		}
+	}
+	return changes
+}
+
+// auditVariants converts variants to plain values, so they read the same once stored as JSON
+func auditVariants(variants []Variant) []map[string]any {
+	values := make([]map[string]any, len(variants))
+	for i, variant := range variants {
+		values[i] = map[string]any{"sku": variant.SKU, "stock": variant.Stock}
+		if len(variant.Attributes) > 0 {
+			values[i]["attributes"] = variant.clone().Attributes
+		}
+		if variant.PriceOverride != nil {
+			values[i]["price_override"] = *variant.PriceOverride
+		}
+	}
+	return values
+}
+
+// AuditRepository defines the contract for audit trail storage.
+// Entries are append-only and returned in the order they were appended.
+type AuditRepository interface {
+	Append(ctx context.Context, entries ...*AuditEntry) error
+	FindByProduct(ctx context.Context, productID string) ([]*AuditEntry, error)
+	// FindSince returns up to limit entries that occurred at or after since
+	FindSince(ctx context.Context, since time.Time, limit int) ([]*AuditEntry, error)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewAuditEntryChangesSurviveJSON(t *testing.T) {
+	product, err := NewProduct("Widget", "A widget", 9.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	product.CategoryIDs = []string{"c1"}
+	override := 12.0
+	if err := product.SetVariants([]Variant{{SKU: "W-1", Stock: 3, PriceOverride: &override, Attributes: map[string]string{"size": "M"}}}); err != nil {
+		t.Fatal(err)
+	}
+	deleted := product.Clone()
+	deleted.MarkDeleted()
+	deletedAt := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	deleted.DeletedAt = &deletedAt
+
+	for name, entry := range map[string]*AuditEntry{
+		"create": NewAuditEntry(AuditCreate, nil, product),
+		"delete": NewAuditEntry(AuditDelete, product, deleted),
+		"purge":  NewAuditEntry(AuditPurge, deleted, nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if len(entry.Changes) == 0 {
+				t.Fatal("expected changes")
+			}
+			data, err := json.Marshal(entry.Changes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var reloaded []AuditChange
+			if err := json.Unmarshal(data, &reloaded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(entry.Changes, reloaded) {
+				t.Errorf("changes differ once reloaded:\n%#v\n%#v", entry.Changes, reloaded)
+			}
+		})
+	}
+}
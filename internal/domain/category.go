@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCategoryName   = errors.New("category name is required")
+	ErrInvalidCategoryParent = errors.New("category cannot be its own ancestor")
+	ErrUnknownParentCategory = errors.New("parent category does not exist")
+	ErrCategoryHasChildren   = errors.New("category has child categories")
+	ErrCategoryInUse         = errors.New("category is linked to products")
+	ErrUnknownCategory       = errors.New("product references an unknown category")
+)
+
+// Category represents a node in the product taxonomy
+type Category struct {
+	ID          string
+	Name        string
+	Description string
+	ParentID    string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewCategory creates a new category with validation
+func NewCategory(name, description, parentID string) (*Category, error) {
+	category := &Category{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: description,
+		ParentID:    parentID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// Validate performs business validation on the category
+func (c *Category) Validate() error {
+	if c.Name == "" {
+		return ErrInvalidCategoryName
+	}
+	if c.ParentID != "" && c.ParentID == c.ID {
+		return ErrInvalidCategoryParent
+	}
+	return nil
+}
+
+// IsRoot reports whether the category has no parent
+func (c *Category) IsRoot() bool {
+	return c.ParentID == ""
+}
+
+// Subtree returns the ID of the given category followed by the IDs of all its descendants
+func Subtree(categories []*Category, rootID string) []string {
+	children := make(map[string][]string, len(categories))
+	for _, c := range categories {
+		if !c.IsRoot() {
+			children[c.ParentID] = append(children[c.ParentID], c.ID)
+		}
+	}
+
+	ids := []string{rootID}
+	seen := map[string]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, childID := range children[ids[i]] {
+			if !seen[childID] {
+				seen[childID] = true
+				ids = append(ids, childID)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType identifies what happened to an aggregate
+type EventType string
+
+const (
+	EventProductCreated  EventType = "product.created"
+	EventProductUpdated  EventType = "product.updated"
+	EventProductDeleted  EventType = "product.deleted"
+	EventProductRestored EventType = "product.restored"
+	EventProductPurged   EventType = "product.purged"
+)
+
+// Event is a domain event raised by a product mutation
+type Event struct {
+	ID         string
+	Type       EventType
+	ProductID  string
+	Product    Product
+	OccurredAt time.Time
+}
+
+// newEvent creates a new event of the given type for a product
+func newEvent(eventType EventType, productID string) Event {
+	return Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		ProductID:  productID,
+		OccurredAt: time.Now(),
+	}
+}
+
+// OutboxMessage is an event waiting in the outbox to be delivered to publishers
+type OutboxMessage struct {
+	Event        Event
+	TraceContext map[string]string
+	DeliveredTo  map[string]bool
+	Attempts     int
+	// NextAttemptAt is when a failed message is due for another attempt; zero for messages not attempted yet
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+}
+
+// OutboxRepository defines the contract for reading and acknowledging outbox messages.
+// Messages are appended by the product repository in the same write as the product itself.
+type OutboxRepository interface {
+	// Pending returns up to limit messages due for an attempt at now, oldest first
+	Pending(ctx context.Context, now time.Time, limit int) ([]*OutboxMessage, error)
+	MarkDelivered(ctx context.Context, eventID, publisher string) error
+	// RecordAttempt counts a failed attempt and holds the message back until next
+	RecordAttempt(ctx context.Context, eventID string, next time.Time) error
+	MarkDispatched(ctx context.Context, eventID string) error
+}
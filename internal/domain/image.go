@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImageNotFound        = errors.New("image not found")
+	ErrNoImages             = errors.New("at least one image is required")
+	ErrTooManyImages        = errors.New("too many images for one product")
+	ErrImageTooLarge        = errors.New("image exceeds the maximum size")
+	ErrUnsupportedImageType = errors.New("image must be a JPEG, PNG or GIF")
+	ErrThumbnailNotReady    = errors.New("thumbnail is not ready")
+	ErrBlobNotFound         = errors.New("blob not found")
+)
+
+// ImageStatus is the processing state of an uploaded image
+type ImageStatus string
+
+const (
+	// ImagePending images are stored and waiting for their thumbnail
+	ImagePending ImageStatus = "pending"
+	ImageReady   ImageStatus = "ready"
+	ImageFailed  ImageStatus = "failed"
+)
+
+// Image is a picture of a product. Its content and thumbnail are kept in a BlobStore.
+type Image struct {
+	ID          string
+	ProductID   string
+	Filename    string
+	ContentType string
+	Size        int64
+	Width       int
+	Height      int
+	Status      ImageStatus
+	// Error tells why processing failed
+	Error       string
+	BlobKey     string
+	UploadedAt  time.Time
+	ProcessedAt *time.Time
+}
+
+// NewImage creates a pending image of a product
+func NewImage(productID, filename, contentType string) *Image {
+	id := uuid.New().String()
+	return &Image{
+		ID:          id,
+		ProductID:   productID,
+		Filename:    filename,
+		ContentType: contentType,
+		Status:      ImagePending,
+		BlobKey:     productID + "/" + id,
+		UploadedAt:  time.Now(),
+	}
+}
+
+// ThumbnailKey returns the blob key of the image's PNG thumbnail
+func (i *Image) ThumbnailKey() string {
+	return i.BlobKey + "-thumbnail.png"
+}
+
+// MarkReady records the dimensions of the image once its thumbnail is stored
+func (i *Image) MarkReady(width, height int) {
+	now := time.Now()
+	i.Width, i.Height = width, height
+	i.Status = ImageReady
+	i.Error = ""
+	i.ProcessedAt = &now
+}
+
+// MarkFailed records why the image could not be processed
+func (i *Image) MarkFailed(reason string) {
+	now := time.Now()
+	i.Status = ImageFailed
+	i.Error = reason
+	i.ProcessedAt = &now
+}
+
+// ImageRepository defines the contract for image metadata storage
+type ImageRepository interface {
+	// Replace replaces every image of a product and returns the ones it replaced
+	Replace(ctx context.Context, productID string, images []*Image) ([]*Image, error)
+	FindByProduct(ctx context.Context, productID string) ([]*Image, error)
+	FindByID(ctx context.Context, productID, id string) (*Image, error)
+	// Update stores a processed image, or returns ErrImageNotFound when it was replaced meanwhile
+	Update(ctx context.Context, image *Image) error
+	// DeleteByProduct removes every image of a product and returns them
+	DeleteByProduct(ctx context.Context, productID string) ([]*Image, error)
+}
+
+// BlobStore defines the contract for binary content storage by key
+type BlobStore interface {
+	// Put stores the content under key, replacing any previous content, and returns its size
+	Put(ctx context.Context, key string, content io.Reader) (int64, error)
+	// Get opens the content under key, or returns ErrBlobNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the content under key; missing keys are ignored
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidQuantity       = errors.New("quantity must be positive")
+	ErrInvalidStockLevel     = errors.New("stock level cannot be negative or below reserved quantity")
+	ErrInsufficientStock     = errors.New("insufficient stock available")
+	ErrReservationNotFound   = errors.New("reservation not found")
+	ErrReservationNotPending = errors.New("reservation is no longer pending")
+)
+
+// ReservationStatus is the lifecycle state of a stock reservation
+type ReservationStatus string
+
+const (
+	ReservationPending   ReservationStatus = "pending"
+	ReservationConfirmed ReservationStatus = "confirmed"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// Stock holds the inventory level of a single product
+type Stock struct {
+	ProductID string
+	OnHand    int
+	Reserved  int
+	UpdatedAt time.Time
+}
+
+// Available returns the quantity that can still be reserved
+func (s *Stock) Available() int {
+	return s.OnHand - s.Reserved
+}
+
+// SetOnHand replaces the on-hand quantity, which can never drop below what is already reserved
+func (s *Stock) SetOnHand(quantity int) error {
+	if quantity < 0 || quantity < s.Reserved {
+		return ErrInvalidStockLevel
+	}
+	s.OnHand = quantity
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// Reserve holds quantity for a pending reservation
+func (s *Stock) Reserve(quantity int) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	if s.Available() < quantity {
+		return ErrInsufficientStock
+	}
+	s.Reserved += quantity
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// Commit removes previously reserved quantity from stock
+func (s *Stock) Commit(quantity int) {
+	s.Reserved -= quantity
+	s.OnHand -= quantity
+	s.UpdatedAt = time.Now()
+}
+
+// Free returns previously reserved quantity to the available pool
+func (s *Stock) Free(quantity int) {
+	s.Reserved -= quantity
+	s.UpdatedAt = time.Now()
+}
+
+// Reservation holds stock for a product until it is confirmed, released or expires
+type Reservation struct {
+	ID        string
+	ProductID string
+	Quantity  int
+	Status    ReservationStatus
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewReservation creates a new pending reservation with validation
+func NewReservation(productID string, quantity int, ttl time.Duration) (*Reservation, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	now := time.Now()
+	return &Reservation{
+		ID:        uuid.New().String(),
+		ProductID: productID,
+		Quantity:  quantity,
+		Status:    ReservationPending,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// IsExpired reports whether a pending reservation has outlived its TTL
+func (r *Reservation) IsExpired(now time.Time) bool {
+	return r.Status == ReservationPending && !now.Before(r.ExpiresAt)
+}
+
+// Transition moves a pending reservation to its final status
+func (r *Reservation) Transition(status ReservationStatus) error {
+	if r.Status != ReservationPending {
+		return ErrReservationNotPending
+	}
+	r.Status = status
+	r.UpdatedAt = time.Now()
+	return nil
+}
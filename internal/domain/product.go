@@ -10,6 +10,9 @@ import (
 var (
 	ErrInvalidProductName  = errors.New("product name is required")
 	ErrInvalidProductPrice = errors.New("product price must be positive")
+	ErrEmptyBatch          = errors.New("batch must contain at least one product")
+	ErrBatchTooLarge       = errors.New("batch exceeds the maximum number of products")
+	ErrProductNotDeleted   = errors.New("product is not deleted")
 )
 
 // Product represents the product entity
@@ -18,8 +21,24 @@ type Product struct {
 	Name        string
 	Description string
 	Price       float64
+	CategoryIDs []string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	// DeletedAt is set while the product is soft-deleted, until it is restored or purged
+	DeletedAt *time.Time
+	// PriceHistory holds every price the product has had, oldest first
+	PriceHistory []PriceChange
+	Variants     []Variant
+	// Version counts the stored updates, so that an update based on a stale read can be rejected
+	Version int
+
+	events []Event
+}
+
+// PriceChange is one point of a product's price history: the price in effect from ChangedAt on
+type PriceChange struct {
+	Price     float64
+	ChangedAt time.Time
 }
 
 // NewProduct creates a new product with validation
@@ -37,9 +56,121 @@ func NewProduct(name, description string, price float64) (*Product, error) {
 		return nil, err
 	}
 
+	product.PriceHistory = []PriceChange{{Price: price, ChangedAt: product.CreatedAt}}
+	product.recordEvent(EventProductCreated)
 	return product, nil
 }
 
+// Update replaces the editable fields of the product with validation
+func (p *Product) Update(name, description string, price float64, categoryIDs []string) error {
+	updated := *p
+	updated.Name = name
+	updated.Description = description
+	updated.Price = price
+	updated.CategoryIDs = categoryIDs
+
+	if err := updated.Validate(); err != nil {
+		return err
+	}
+
+	priceChanged := p.Price != price
+	*p = updated
+	p.UpdatedAt = time.Now()
+	if priceChanged {
+		p.PriceHistory = append(p.Prices(), PriceChange{Price: price, ChangedAt: p.UpdatedAt})
+	}
+	p.recordEvent(EventProductUpdated)
+	return nil
+}
+
+// Prices returns the price history, oldest first. Products stored before prices were
+// tracked report their current price since creation.
+func (p *Product) Prices() []PriceChange {
+	if len(p.PriceHistory) == 0 {
+		return []PriceChange{{Price: p.Price, ChangedAt: p.CreatedAt}}
+	}
+	return append([]PriceChange(nil), p.PriceHistory...)
+}
+
+// AsOf returns a copy of the product as it was at t, with the price then in effect, or false
+// if it had not been created yet or was deleted at t. Only the price is versioned; the other
+// fields are the current ones, and deletions that were later restored are not remembered.
+func (p *Product) AsOf(t time.Time) (*Product, bool) {
+	if t.Before(p.CreatedAt) || (p.DeletedAt != nil && !t.Before(*p.DeletedAt)) {
+		return nil, false
+	}
+
+	past := p.Clone()
+	past.DeletedAt = nil
+	for _, change := range p.Prices() {
+		if change.ChangedAt.After(t) {
+			break
+		}
+		past.Price = change.Price
+	}
+	return past, true
+}
+
+// MarkDeleted soft-deletes the product; it stays stored until it is restored or purged
+func (p *Product) MarkDeleted() {
+	now := time.Now()
+	p.DeletedAt = &now
+	p.UpdatedAt = now
+	p.recordEvent(EventProductDeleted)
+}
+
+// Restore brings back a soft-deleted product
+func (p *Product) Restore() error {
+	if !p.IsDeleted() {
+		return ErrProductNotDeleted
+	}
+	p.DeletedAt = nil
+	p.UpdatedAt = time.Now()
+	p.recordEvent(EventProductRestored)
+	return nil
+}
+
+// MarkPurged records that a soft-deleted product was permanently removed
+func (p *Product) MarkPurged() {
+	p.recordEvent(EventProductPurged)
+}
+
+// IsDeleted reports whether the product is soft-deleted
+func (p *Product) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+// PullEvents returns the pending domain events, stamped with the current product
+// state, and clears them. Repositories call it when persisting the product.
+func (p *Product) PullEvents() []Event {
+	events := p.events
+	p.events = nil
+
+	for i := range events {
+		events[i].Product = *p.Clone()
+	}
+	return events
+}
+
+// Clone returns a deep copy of the product without pending events
+func (p *Product) Clone() *Product {
+	clone := *p
+	clone.CategoryIDs = append([]string(nil), p.CategoryIDs...)
+	clone.PriceHistory = append([]PriceChange(nil), p.PriceHistory...)
+	clone.Variants = cloneVariants(p.Variants)
+	if p.DeletedAt != nil {
+		deletedAt := *p.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	clone.events = nil
+	return &clone
+}
+
+// recordEvent appends a pending domain event
+func (p *Product) recordEvent(eventType EventType) {
+	p.events = append(p.events, newEvent(eventType, p.ID))
+}
+
 // Validate performs business validation on the product
 func (p *Product) Validate() error {
 	if p.Name == "" {
@@ -50,3 +181,13 @@ func (p *Product) Validate() error {
 	}
 	return nil
 }
+
+// InCategory reports whether the product is linked to the given category
+func (p *Product) InCategory(categoryID string) bool {
+	for _, id := range p.CategoryIDs {
+		if id == categoryID {
+			return true
+		}
+	}
+	return false
+}
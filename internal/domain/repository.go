@@ -3,15 +3,62 @@ package domain
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var (
-	ErrProductNotFound = errors.New("product not found")
+	ErrProductNotFound  = errors.New("product not found")
+	ErrProductConflict  = errors.New("product was changed by another request, retry with its current state")
+	ErrCategoryNotFound = errors.New("category not found")
 )
 
-// ProductRepository defines the contract for product storage
+// ProductRepository defines the contract for product storage.
+// Create, CreateMany and Update persist the products' pending domain events in the same write.
+// CreateMany stores all products or none.
+// Soft-deleted products are stored with Update and only returned by FindDeletedByID and FindDeleted.
+// Create, CreateMany and Update fail with ErrDuplicateSKU, storing nothing, when a variant SKU is
+// already used by another product, soft-deleted ones included, or by another product of the batch.
+// Update fails with ErrProductConflict, storing nothing, when the product's Version is not the stored
+// one because it was changed since it was read; a stored update increments Version.
 type ProductRepository interface {
 	Create(ctx context.Context, product *Product) error
+	CreateMany(ctx context.Context, products []*Product) error
+	Update(ctx context.Context, product *Product) error
 	FindByID(ctx context.Context, id string) (*Product, error)
 	FindAll(ctx context.Context) ([]*Product, error)
+	// FindPage returns up to limit products with an ID greater than after, ordered by ID
+	FindPage(ctx context.Context, after string, limit int) ([]*Product, error)
+	FindByCategories(ctx context.Context, categoryIDs []string) ([]*Product, error)
+	Search(ctx context.Context, query string) ([]*Product, error)
+	// FindBySKU returns the product with a variant of the given SKU, or ErrVariantNotFound
+	FindBySKU(ctx context.Context, sku string) (*Product, error)
+	// FindDeletedByID returns a soft-deleted product, or ErrProductNotFound when it is missing or not deleted
+	FindDeletedByID(ctx context.Context, id string) (*Product, error)
+	// FindDeleted returns the products soft-deleted before the given time, ordered by deletion time
+	FindDeleted(ctx context.Context, before time.Time) ([]*Product, error)
+	// Purge permanently removes the products soft-deleted before the given time and returns them,
+	// persisting a product.purged event for each in the same write
+	Purge(ctx context.Context, before time.Time) ([]*Product, error)
+}
+
+// CategoryRepository defines the contract for category storage
+type CategoryRepository interface {
+	Create(ctx context.Context, category *Category) error
+	FindByID(ctx context.Context, id string) (*Category, error)
+	FindAll(ctx context.Context) ([]*Category, error)
+	Update(ctx context.Context, category *Category) error
+	Delete(ctx context.Context, id string) error
+}
+
+// InventoryRepository defines the contract for stock and reservation storage.
+// Implementations must apply each operation atomically so stock can never be oversold.
+type InventoryRepository interface {
+	GetStock(ctx context.Context, productID string) (*Stock, error)
+	SetOnHand(ctx context.Context, productID string, quantity int) (*Stock, error)
+	Reserve(ctx context.Context, reservation *Reservation) (*Stock, error)
+	FindReservation(ctx context.Context, id string) (*Reservation, error)
+	CompleteReservation(ctx context.Context, id string, status ReservationStatus) (*Reservation, error)
+	ExpireReservations(ctx context.Context, now time.Time) ([]*Reservation, error)
+	// PurgeReservations removes the reservations completed or expired before the given time and returns how many
+	PurgeReservations(ctx context.Context, before time.Time) (int, error)
 }
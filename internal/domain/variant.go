@@ -0,0 +1,99 @@
+package domain
+
+import "errors"
+
+var (
+	ErrInvalidSKU          = errors.New("variant SKU is required")
+	ErrDuplicateSKU        = errors.New("variant SKU is already in use")
+	ErrInvalidVariantPrice = errors.New("variant price override must be positive")
+	ErrInvalidVariantStock = errors.New("variant stock cannot be negative")
+	ErrVariantNotFound     = errors.New("variant not found")
+)
+
+// Variant is a sellable version of a product, such as a size or colour.
+// Its SKU is unique across all products, which the repository enforces.
+type Variant struct {
+	SKU        string
+	Attributes map[string]string
+	// PriceOverride replaces the product price for this variant when set
+	PriceOverride *float64
+	Stock         int
+}
+
+// Price returns the price of the variant given the price of its product
+func (v Variant) Price(productPrice float64) float64 {
+	if v.PriceOverride != nil {
+		return *v.PriceOverride
+	}
+	return productPrice
+}
+
+// Validate performs business validation on the variant
+func (v Variant) Validate() error {
+	if v.SKU == "" {
+		return ErrInvalidSKU
+	}
+	if v.PriceOverride != nil && *v.PriceOverride <= 0 {
+		return ErrInvalidVariantPrice
+	}
+	if v.Stock < 0 {
+		return ErrInvalidVariantStock
+	}
+	return nil
+}
+
+// clone returns a deep copy of the variant
+func (v Variant) clone() Variant {
+	clone := v
+	if v.Attributes != nil {
+		clone.Attributes = make(map[string]string, len(v.Attributes))
+		for key, value := range v.Attributes {
+			clone.Attributes[key] = value
+		}
+	}
+	if v.PriceOverride != nil {
+		price := *v.PriceOverride
+		clone.PriceOverride = &price
+	}
+	return clone
+}
+
+// SetVariants replaces the variants of the product with validation.
+// SKUs must be unique within the product; uniqueness across products is checked when it is stored.
+func (p *Product) SetVariants(variants []Variant) error {
+	seen := make(map[string]bool, len(variants))
+	for _, variant := range variants {
+		if err := variant.Validate(); err != nil {
+			return err
+		}
+		if seen[variant.SKU] {
+			return ErrDuplicateSKU
+		}
+		seen[variant.SKU] = true
+	}
+
+	p.Variants = cloneVariants(variants)
+	return nil
+}
+
+// Variant returns the variant of the product with the given SKU
+func (p *Product) Variant(sku string) (Variant, bool) {
+	for _, variant := range p.Variants {
+		if variant.SKU == sku {
+			return variant.clone(), true
+		}
+	}
+	return Variant{}, false
+}
+
+// cloneVariants returns a deep copy of a list of variants
+func cloneVariants(variants []Variant) []Variant {
+	if variants == nil {
+		return nil
+	}
+	clones := make([]Variant, len(variants))
+	for i, variant := range variants {
+		clones[i] = variant.clone()
+	}
+	return clones
+}
@@ -0,0 +1,196 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhookURL       = errors.New("webhook url must be an absolute http or https url")
+	ErrPrivateWebhookURL       = errors.New("webhook url must not point to a loopback, link-local or private address")
+	ErrInvalidEventType        = errors.New("unknown event type")
+	ErrDeliveryNotDead         = errors.New("only dead-lettered deliveries can be retried")
+)
+
+// DeliveryStatus is the lifecycle state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryDead      DeliveryStatus = "dead"
+)
+
+// WebhookSubscription is a subscriber URL receiving product events
+type WebhookSubscription struct {
+	ID         string
+	URL        string
+	Secret     string
+	EventTypes []EventType
+	CreatedAt  time.Time
+}
+
+// NewWebhookSubscription creates a new subscription with validation.
+// A random signing secret is generated when none is given, and an empty
+// event type list subscribes to every product event.
+func NewWebhookSubscription(rawURL, secret string, eventTypes []EventType) (*WebhookSubscription, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || !parsed.IsAbs() || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+
+	for _, eventType := range eventTypes {
+		switch eventType {
+		case EventProductCreated, EventProductUpdated, EventProductDeleted, EventProductRestored, EventProductPurged:
+		default:
+			return nil, ErrInvalidEventType
+		}
+	}
+
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	return &WebhookSubscription{
+		ID:         uuid.New().String(),
+		URL:        rawURL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// TargetsPrivateNetwork reports whether the subscription URL names a host that is not publicly routable:
+// localhost or a loopback, link-local, private or unspecified IP address.
+// Names that resolve to such addresses are only caught when the webhook is delivered.
+func (s *WebhookSubscription) TargetsPrivateNetwork() bool {
+	parsed, err := url.Parse(s.URL)
+	if err != nil {
+		return true
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	addr, err := netip.ParseAddr(host)
+	return err == nil && !PublicAddr(addr)
+}
+
+// PublicAddr reports whether an IP address is publicly routable, the only kind webhooks are delivered to
+// unless private networks are allowed
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !addr.IsLoopback() && !addr.IsLinkLocalUnicast()
+}
+
+// Matches reports whether the subscription wants events of the given type
+func (s *WebhookSubscription) Matches(eventType EventType) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryAttempt records the outcome of one attempt to deliver a webhook
+type DeliveryAttempt struct {
+	Attempt     int
+	StatusCode  int
+	Error       string
+	Duration    time.Duration
+	AttemptedAt time.Time
+}
+
+// WebhookDelivery is a single event queued for delivery to one subscription
+type WebhookDelivery struct {
+	ID             string
+	SubscriptionID string
+	EventID        string
+	EventType      EventType
+	Payload        []byte
+	TraceContext   map[string]string
+	Status         DeliveryStatus
+	Attempts       []DeliveryAttempt
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// NewWebhookDelivery creates a new pending delivery due immediately
+func NewWebhookDelivery(subscriptionID string, event Event, payload []byte, traceContext map[string]string) *WebhookDelivery {
+	now := time.Now()
+	return &WebhookDelivery{
+		ID:             uuid.New().String(),
+		SubscriptionID: subscriptionID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+		TraceContext:   traceContext,
+		Status:         DeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// RecordAttempt appends an attempt to the delivery log and moves the delivery to
+// succeeded, back to pending with the given retry delay, or to the dead-letter list
+func (d *WebhookDelivery) RecordAttempt(attempt DeliveryAttempt, succeeded bool, maxAttempts int, retryAfter time.Duration) {
+	d.Attempts = append(d.Attempts, attempt)
+	d.UpdatedAt = attempt.AttemptedAt
+
+	switch {
+	case succeeded:
+		d.Status = DeliverySucceeded
+	case len(d.Attempts) >= maxAttempts:
+		d.Status = DeliveryDead
+	default:
+		d.Status = DeliveryPending
+		d.NextAttemptAt = attempt.AttemptedAt.Add(retryAfter)
+	}
+}
+
+// Requeue moves a dead-lettered delivery back to pending for another round of attempts
+func (d *WebhookDelivery) Requeue() error {
+	if d.Status != DeliveryDead {
+		return ErrDeliveryNotDead
+	}
+	d.Status = DeliveryPending
+	d.Attempts = nil
+	d.NextAttemptAt = time.Now()
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
+// WebhookRepository defines the contract for webhook subscription and delivery storage
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *WebhookSubscription) error
+	FindSubscription(ctx context.Context, id string) (*WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	SaveDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	FindDelivery(ctx context.Context, id string) (*WebhookDelivery, error)
+	// DueDeliveries returns, for every subscription, up to perSubscription of its pending deliveries
+	// due at now, oldest first, so a backlog for one subscription never crowds out the others
+	DueDeliveries(ctx context.Context, now time.Time, perSubscription int) ([]*WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID string) ([]*WebhookDelivery, error)
+	ListDeadLetters(ctx context.Context) ([]*WebhookDelivery, error)
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "testing"
+
+func TestWebhookSubscriptionTargetsPrivateNetwork(t *testing.T) {
+	for url, want := range map[string]bool{
+		"https://example.com/hook":          false,
+		"http://93.184.216.34/hook":         false,
+		"http://[2606:4700::1111]/hook":     false,
+		"http://localhost:8080/hook":        true,
+		"http://api.localhost/hook":         true,
+		"http://127.0.0.1/hook":             true,
+		"http://10.0.0.5/hook":              true,
+		"http://192.168.1.10/hook":          true,
+		"http://169.254.169.254/latest":     true,
+		"http://0.0.0.0/hook":               true,
+		"http://[::1]/hook":                 true,
+		"http://[fe80::1]/hook":             true,
+		"http://[fd00::1]/hook":             true,
+		"http://[::ffff:127.0.0.1]/hook":    true,
+		"https://LOCALHOST./hook":           true,
+		"http://internal.example.com/hook":  false,
+		"http://172.16.0.1:9000/hook":       true,
+		"http://100.64.0.1/hook":            false,
+		"http://224.0.0.1/hook":             true,
+		"http://255.255.255.255/hook":       true,
+		"http://[ff02::1]/hook":             true,
+		"http://203.0.113.7/hook?x=1":       false,
+		"http://198.51.100.1:8443/callback": false,
+	} {
+		subscription, err := NewWebhookSubscription(url, "secret", nil)
+		if err != nil {
+			t.Fatalf("NewWebhookSubscription(%q): %v", url, err)
+		}
+		if got := subscription.TargetsPrivateNetwork(); got != want {
+			t.Errorf("TargetsPrivateNetwork(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
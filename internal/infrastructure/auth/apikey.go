@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+)
+
+// APIKeyAuthenticator authenticates static API keys.
+// Keys are stored as SHA-256 digests so the plaintext never stays in memory.
+type APIKeyAuthenticator struct {
+	principals map[[sha256.Size]byte]string
+}
+
+// NewAPIKeyAuthenticator creates an API key authenticator from "key:principal" entries,
+// given inline (comma-separated) and/or in a file with one entry per line.
+// Blank lines and lines starting with # are ignored in the file.
+func NewAPIKeyAuthenticator(inline string, path string) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{principals: make(map[[sha256.Size]byte]string)}
+
+	for _, entry := range strings.Split(inline, ",") {
+		if err := a.add(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open API key file: %w", err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			if err := a.add(line); err != nil {
+				return nil, err
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read API key file: %w", err)
+		}
+	}
+
+	return a, nil
+}
+
+// add registers one "key:principal" entry; empty entries are skipped
+func (a *APIKeyAuthenticator) add(entry string) error {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return nil
+	}
+
+	key, principal, ok := strings.Cut(entry, ":")
+	key, principal = strings.TrimSpace(key), strings.TrimSpace(principal)
+	if !ok || key == "" || principal == "" {
+		return fmt.Errorf("invalid API key entry, expected key:principal")
+	}
+
+	a.principals[sha256.Sum256([]byte(key))] = principal
+	return nil
+}
+
+// Len returns the number of configured keys
+func (a *APIKeyAuthenticator) Len() int {
+	return len(a.principals)
+}
+
+// Principal returns the principal the API key belongs to, if the key is configured
+func (a *APIKeyAuthenticator) Principal(key string) (string, bool) {
+	id, ok := a.principals[sha256.Sum256([]byte(key))]
+	return id, ok
+}
+
+// Authenticate returns the principal the API key belongs to
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, credentials Credentials) (*auth.Principal, error) {
+	id, ok := a.Principal(credentials.APIKey)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	return &auth.Principal{ID: id, Method: auth.MethodAPIKey}, nil
+}
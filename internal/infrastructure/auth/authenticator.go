@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidAPIKey      = errors.New("invalid API key")
+	ErrInvalidToken       = errors.New("invalid bearer token")
+)
+
+// Credentials are the caller credentials extracted by a transport (HTTP headers, gRPC metadata)
+type Credentials struct {
+	APIKey      string
+	BearerToken string
+}
+
+// Authenticator identifies the principal behind a set of credentials
+type Authenticator interface {
+	Authenticate(ctx context.Context, credentials Credentials) (*auth.Principal, error)
+}
+
+// Chain authenticates API keys and bearer tokens with their own authenticator.
+// Either authenticator may be nil when that method is not configured.
+// The principal is added to the current span as enduser.id.
+type Chain struct {
+	apiKeys  Authenticator
+	tokens   Authenticator
+	attempts metric.Int64Counter
+}
+
+// NewChain creates a new authenticator chain
+func NewChain(apiKeys, tokens Authenticator, meter metric.Meter) *Chain {
+	// Initialize metrics
+	attempts, _ := meter.Int64Counter(
+		"auth.attempts",
+		metric.WithDescription("Total number of authentication attempts"),
+	)
+
+	return &Chain{
+		apiKeys:  apiKeys,
+		tokens:   tokens,
+		attempts: attempts,
+	}
+}
+
+// Authenticate uses the API key when one is given, the bearer token otherwise
+func (c *Chain) Authenticate(ctx context.Context, credentials Credentials) (*auth.Principal, error) {
+	method := "none"
+	switch {
+	case credentials.APIKey != "":
+		method = string(auth.MethodAPIKey)
+	case credentials.BearerToken != "":
+		method = string(auth.MethodJWT)
+	}
+
+	principal, err := c.authenticate(ctx, credentials)
+	if err != nil {
+		c.attempts.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("method", method),
+			attribute.String("result", "failure"),
+		))
+		return nil, err
+	}
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("enduser.id", principal.ID),
+		attribute.String("enduser.auth_method", string(principal.Method)),
+	)
+	c.attempts.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("method", method),
+		attribute.String("result", "success"),
+	))
+	return principal, nil
+}
+
+// authenticate dispatches the credentials to the configured authenticator
+func (c *Chain) authenticate(ctx context.Context, credentials Credentials) (*auth.Principal, error) {
+	switch {
+	case credentials.APIKey != "" && c.apiKeys != nil:
+		return c.apiKeys.Authenticate(ctx, credentials)
+	case credentials.APIKey != "":
+		return nil, ErrInvalidAPIKey
+	case credentials.BearerToken != "" && c.tokens != nil:
+		return c.tokens.Authenticate(ctx, credentials)
+	case credentials.BearerToken != "":
+		return nil, ErrInvalidToken
+	default:
+		return nil, ErrMissingCredentials
+	}
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+)
+
+// JWTAuthenticator authenticates bearer tokens signed by a key of a JWKS.
+// The token subject becomes the principal ID and the roles claim its roles.
+type JWTAuthenticator struct {
+	jwks       *JWKS
+	parser     *jwt.Parser
+	rolesClaim string
+}
+
+// NewJWTAuthenticator creates a JWT authenticator; issuer and audience are only checked when set.
+// rolesClaim names the claim holding the principal's roles, as an array or a space-separated string.
+func NewJWTAuthenticator(jwks *JWKS, issuer, audience, rolesClaim string) *JWTAuthenticator {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+	}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	return &JWTAuthenticator{
+		jwks:       jwks,
+		parser:     jwt.NewParser(options...),
+		rolesClaim: rolesClaim,
+	}
+}
+
+// Authenticate verifies the bearer token and returns its subject
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, credentials Credentials) (*auth.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(credentials.BearerToken, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return a.jwks.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	return &auth.Principal{ID: subject, Method: auth.MethodJWT, Roles: roles(claims[a.rolesClaim])}, nil
+}
+
+// roles reads a roles claim given as an array of strings or a space-separated string
+func roles(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		var names []string
+		for _, item := range value {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	default:
+		return nil
+	}
+}
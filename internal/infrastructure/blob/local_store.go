@@ -0,0 +1,137 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errInvalidKey = errors.New("blob key must be a relative path within the store")
+
+// LocalStore is a domain.BlobStore keeping each blob in a file under a directory.
+// Content is written to a temporary file and renamed into place, so readers never see a partial blob.
+type LocalStore struct {
+	dir    string
+	tracer trace.Tracer
+}
+
+// NewLocalStore creates a blob store in dir, creating the directory if needed
+func NewLocalStore(dir string, tracer trace.Tracer) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &LocalStore{dir: dir, tracer: tracer}, nil
+}
+
+// Put stores the content under key
+func (s *LocalStore) Put(ctx context.Context, key string, content io.Reader) (int64, error) {
+	_, span := s.start(ctx, "BlobStore.Put", key)
+	defer span.End()
+
+	path, err := s.path(key)
+	if err != nil {
+		return 0, fail(span, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fail(span, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, fail(span, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return size, fail(span, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return size, fail(span, err)
+	}
+
+	span.SetAttributes(attribute.Int64("blob.size", size))
+	span.SetStatus(codes.Ok, "")
+	return size, nil
+}
+
+// Get opens the content under key
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	_, span := s.start(ctx, "BlobStore.Get", key)
+	defer span.End()
+
+	path, err := s.path(key)
+	if err != nil {
+		return nil, fail(span, err)
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fail(span, domain.ErrBlobNotFound)
+	}
+	if err != nil {
+		return nil, fail(span, err)
+	}
+
+	if info, err := file.Stat(); err == nil {
+		span.SetAttributes(attribute.Int64("blob.size", info.Size()))
+	}
+	span.SetStatus(codes.Ok, "")
+	return file, nil
+}
+
+// Delete removes the content under key
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	_, span := s.start(ctx, "BlobStore.Delete", key)
+	defer span.End()
+
+	path, err := s.path(key)
+	if err != nil {
+		return fail(span, err)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fail(span, err)
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+// path maps a key to its file, rejecting keys that would escape the store directory
+func (s *LocalStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", errInvalidKey
+	}
+	return filepath.Join(s.dir, clean), nil
+}
+
+// start opens a client span for one blob operation
+func (s *LocalStore) start(ctx context.Context, name, key string) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("blob.store", "local"),
+			attribute.String("blob.key", key),
+		),
+	)
+}
+
+// fail records err on the span and returns it
+func fail(span trace.Span, err error) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	return err
+}
@@ -1,17 +1,64 @@
 package config
 
 import (
+	"log/slog"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 type Config struct {
-	Server ServerConfig
-	OTLP   OTLPConfig
+	Log         LogConfig
+	Reload      ReloadConfig
+	Server      ServerConfig
+	GRPC        GRPCConfig
+	OTLP        OTLPConfig
+	Inventory   InventoryConfig
+	Events      EventsConfig
+	Webhooks    WebhooksConfig
+	Stream      StreamConfig
+	GraphQL     GraphQLConfig
+	Auth        AuthConfig
+	Authz       AuthzConfig
+	RateLimit   RateLimitConfig
+	Idempotency IdempotencyConfig
+	Batch       BatchConfig
+	Import      ImportConfig
+	Persistence PersistenceConfig
+	Cache       CacheConfig
+	Trash       TrashConfig
+	Audit       AuditConfig
+	Images      ImagesConfig
+
+	// file is the config file the configuration was loaded from, if any
+	file string
+	// sources records where each setting set by something other than its default came from
+	sources map[string]string
+}
+
+type LogConfig struct {
+	Level slog.Level
+}
+
+type ReloadConfig struct {
+	// WatchInterval is how often the config file is checked for changes
+	WatchInterval time.Duration
 }
 
 type ServerConfig struct {
-	Port string
-	Host string
+	Port              string
+	Host              string
+	OpenAPIValidation bool
+	// DisabledRoutes lists the "METHOD /route" patterns answered with 503 instead of being served
+	DisabledRoutes []string
+	// ShutdownTimeout bounds how long in-flight requests may take to finish on shutdown
+	ShutdownTimeout time.Duration
+}
+
+type GRPCConfig struct {
+	Enabled bool
+	Host    string
+	Port    string
 }
 
 type OTLPConfig struct {
@@ -19,34 +66,239 @@ type OTLPConfig struct {
 	Endpoint    string
 	ServiceName string
 	Environment string
+	// SamplerRatio is the share of new traces that are sampled; child spans follow their parent
+	SamplerRatio float64
 }
 
-// LoadConfig loads configuration from environment variables
-func LoadConfig() *Config {
-	return &Config{
-		Server: ServerConfig{
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
-			Port: getEnv("SERVER_PORT", "8080"),
-		},
-		OTLP: OTLPConfig{
-			Enabled:     getEnvBool("OTEL_ENABLED", true),
-			Endpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
-			ServiceName: getEnv("OTEL_SERVICE_NAME", "products-api"),
-			Environment: getEnv("OTEL_ENVIRONMENT", "development"),
-		},
-	}
+type InventoryConfig struct {
+	ReservationTTL time.Duration
+	// MaxReservationTTL caps the TTL a caller can ask for
+	MaxReservationTTL time.Duration
+	ExpiryInterval    time.Duration
+	// ReservationRetention is how long confirmed, released and expired reservations are kept
+	ReservationRetention time.Duration
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+type EventsConfig struct {
+	DispatchInterval time.Duration
+	BatchSize        int
+	MaxAttempts      int
+	WebhookURL       string
+	WebhookTimeout   time.Duration
+	FilePath         string
+}
+
+type WebhooksConfig struct {
+	Timeout      time.Duration
+	PollInterval time.Duration
+	MaxAttempts  int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	// ReceiverConcurrency is how many deliveries can be in flight to one subscription at a time
+	ReceiverConcurrency int
+	// AllowPrivateNetworks lets subscriptions target loopback, link-local and private addresses
+	AllowPrivateNetworks bool
+}
+
+type StreamConfig struct {
+	HistorySize       int
+	ClientBuffer      int
+	HeartbeatInterval time.Duration
+}
+
+type GraphQLConfig struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+type AuthConfig struct {
+	Enabled             bool
+	APIKeys             string
+	APIKeysFile         string
+	JWKSFile            string
+	JWKSURL             string
+	JWKSRefreshInterval time.Duration
+	JWTIssuer           string
+	JWTAudience         string
+	ExemptPaths         []string
+}
+
+type AuthzConfig struct {
+	Enabled       bool
+	RolesClaim    string
+	RoleBindings  string
+	DefaultRole   string
+	RoutePolicy   string
+	ServicePolicy string
+}
+
+type RateLimitConfig struct {
+	Enabled     bool
+	Key         string
+	Rate        float64
+	Burst       int
+	Routes      string
+	ExemptPaths []string
+}
+
+type IdempotencyConfig struct {
+	TTL         time.Duration
+	MaxBodySize int
+}
+
+type BatchConfig struct {
+	MaxSize int
+}
+
+type PersistenceConfig struct {
+	Dir              string
+	SnapshotInterval time.Duration
+	SyncWrites       bool
+}
+
+type CacheConfig struct {
+	Enabled    bool
+	MaxEntries int
+	TTL        time.Duration
+}
+
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+type AuditConfig struct {
+	// FilePath is the JSON lines file the audit trail is appended to; empty keeps it in memory
+	FilePath string
+}
+
+type ImagesConfig struct {
+	// Dir is where the local blob store keeps image content and thumbnails
+	Dir           string
+	MaxSize       int
+	MaxCount      int
+	ThumbnailSize int
+	QueueSize     int
 }
 
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		return value == "true" || value == "1" || value == "yes"
+type ImportConfig struct {
+	MaxRows      int
+	SyncMaxRows  int
+	ChunkSize    int
+	QueueSize    int
+	JobRetention time.Duration
+}
+
+// settings binds every configurable field of c to its config file key, flag and environment variable, with its default
+func (c *Config) settings() []*setting {
+	return []*setting{
+		{key: "log.level", env: "LOG_LEVEL", value: levelVar(&c.Log.Level, slog.LevelInfo)},
+
+		{key: "reload.watch_interval", env: "CONFIG_WATCH_INTERVAL", value: durationVar(&c.Reload.WatchInterval, 5*time.Second)},
+
+		{key: "server.host", env: "SERVER_HOST", value: stringVar(&c.Server.Host, "0.0.0.0")},
+		{key: "server.port", env: "SERVER_PORT", value: stringVar(&c.Server.Port, "8080")},
+		{key: "server.openapi_validation", env: "OPENAPI_VALIDATION", value: boolVar(&c.Server.OpenAPIValidation, false)},
+		{key: "server.disabled_routes", env: "DISABLED_ROUTES", value: listVar(&c.Server.DisabledRoutes)},
+		{key: "server.shutdown_timeout", env: "SERVER_SHUTDOWN_TIMEOUT", value: durationVar(&c.Server.ShutdownTimeout, 10*time.Second)},
+
+		{key: "grpc.enabled", env: "GRPC_ENABLED", value: boolVar(&c.GRPC.Enabled, true)},
+		{key: "grpc.host", env: "GRPC_HOST", value: stringVar(&c.GRPC.Host, "0.0.0.0")},
+		{key: "grpc.port", env: "GRPC_PORT", value: stringVar(&c.GRPC.Port, "50051")},
+
+		{key: "otlp.enabled", env: "OTEL_ENABLED", value: boolVar(&c.OTLP.Enabled, true)},
+		{key: "otlp.endpoint", env: "OTEL_EXPORTER_OTLP_ENDPOINT", value: stringVar(&c.OTLP.Endpoint, "localhost:4317")},
+		{key: "otlp.service_name", env: "OTEL_SERVICE_NAME", value: stringVar(&c.OTLP.ServiceName, "products-api")},
+		{key: "otlp.environment", env: "OTEL_ENVIRONMENT", value: stringVar(&c.OTLP.Environment, "development")},
+		{key: "otlp.sampler_ratio", env: "OTEL_SAMPLER_RATIO", value: ratioVar(&c.OTLP.SamplerRatio, 1)},
+
+		{key: "inventory.reservation_ttl", env: "INVENTORY_RESERVATION_TTL", value: durationVar(&c.Inventory.ReservationTTL, 15*time.Minute)},
+		{key: "inventory.max_reservation_ttl", env: "INVENTORY_MAX_RESERVATION_TTL", value: durationVar(&c.Inventory.MaxReservationTTL, 24*time.Hour)},
+		{key: "inventory.expiry_interval", env: "INVENTORY_EXPIRY_INTERVAL", value: durationVar(&c.Inventory.ExpiryInterval, 30*time.Second)},
+		{key: "inventory.reservation_retention", env: "INVENTORY_RESERVATION_RETENTION", value: durationVar(&c.Inventory.ReservationRetention, time.Hour)},
+
+		{key: "events.dispatch_interval", env: "EVENTS_DISPATCH_INTERVAL", value: durationVar(&c.Events.DispatchInterval, time.Second)},
+		{key: "events.batch_size", env: "EVENTS_BATCH_SIZE", value: intVar(&c.Events.BatchSize, 100)},
+		{key: "events.max_attempts", env: "EVENTS_MAX_ATTEMPTS", value: intVar(&c.Events.MaxAttempts, 5)},
+		{key: "events.webhook_url", env: "EVENTS_WEBHOOK_URL", value: stringVar(&c.Events.WebhookURL, ""), secret: true},
+		{key: "events.webhook_timeout", env: "EVENTS_WEBHOOK_TIMEOUT", value: durationVar(&c.Events.WebhookTimeout, 5*time.Second)},
+		{key: "events.file_path", env: "EVENTS_FILE_PATH", value: stringVar(&c.Events.FilePath, "")},
+
+		{key: "webhooks.timeout", env: "WEBHOOKS_TIMEOUT", value: durationVar(&c.Webhooks.Timeout, 5*time.Second)},
+		{key: "webhooks.poll_interval", env: "WEBHOOKS_POLL_INTERVAL", value: durationVar(&c.Webhooks.PollInterval, time.Second)},
+		{key: "webhooks.max_attempts", env: "WEBHOOKS_MAX_ATTEMPTS", value: intVar(&c.Webhooks.MaxAttempts, 6)},
+		{key: "webhooks.backoff_base", env: "WEBHOOKS_BACKOFF_BASE", value: durationVar(&c.Webhooks.BackoffBase, time.Second)},
+		{key: "webhooks.backoff_max", env: "WEBHOOKS_BACKOFF_MAX", value: durationVar(&c.Webhooks.BackoffMax, 5*time.Minute)},
+		{key: "webhooks.receiver_concurrency", env: "WEBHOOKS_RECEIVER_CONCURRENCY", value: intVar(&c.Webhooks.ReceiverConcurrency, 2)},
+		{key: "webhooks.allow_private_networks", env: "WEBHOOKS_ALLOW_PRIVATE_NETWORKS", value: boolVar(&c.Webhooks.AllowPrivateNetworks, false)},
+
+		{key: "stream.history_size", env: "STREAM_HISTORY_SIZE", value: intVar(&c.Stream.HistorySize, 1000)},
+		{key: "stream.client_buffer", env: "STREAM_CLIENT_BUFFER", value: intVar(&c.Stream.ClientBuffer, 64)},
+		{key: "stream.heartbeat_interval", env: "STREAM_HEARTBEAT_INTERVAL", value: durationVar(&c.Stream.HeartbeatInterval, 15*time.Second)},
+
+		{key: "graphql.max_depth", env: "GRAPHQL_MAX_DEPTH", value: intVar(&c.GraphQL.MaxDepth, 10)},
+		{key: "graphql.max_complexity", env: "GRAPHQL_MAX_COMPLEXITY", value: intVar(&c.GraphQL.MaxComplexity, 1000)},
+
+		{key: "auth.enabled", env: "AUTH_ENABLED", value: boolVar(&c.Auth.Enabled, false)},
+		{key: "auth.api_keys", env: "AUTH_API_KEYS", value: stringVar(&c.Auth.APIKeys, ""), secret: true},
+		{key: "auth.api_keys_file", env: "AUTH_API_KEYS_FILE", value: stringVar(&c.Auth.APIKeysFile, "")},
+		{key: "auth.jwks_file", env: "AUTH_JWKS_FILE", value: stringVar(&c.Auth.JWKSFile, "")},
+		{key: "auth.jwks_url", env: "AUTH_JWKS_URL", value: stringVar(&c.Auth.JWKSURL, "")},
+		{key: "auth.jwks_refresh_interval", env: "AUTH_JWKS_REFRESH_INTERVAL", value: durationVar(&c.Auth.JWKSRefreshInterval, 10*time.Minute)},
+		{key: "auth.jwt_issuer", env: "AUTH_JWT_ISSUER", value: stringVar(&c.Auth.JWTIssuer, "")},
+		{key: "auth.jwt_audience", env: "AUTH_JWT_AUDIENCE", value: stringVar(&c.Auth.JWTAudience, "")},
+		{key: "auth.exempt_paths", env: "AUTH_EXEMPT_PATHS", value: listVar(&c.Auth.ExemptPaths, "/health", "/metrics")},
+
+		{key: "authz.enabled", env: "AUTHZ_ENABLED", value: boolVar(&c.Authz.Enabled, false)},
+		{key: "authz.roles_claim", env: "AUTHZ_ROLES_CLAIM", value: stringVar(&c.Authz.RolesClaim, "roles")},
+		{key: "authz.role_bindings", env: "AUTHZ_ROLE_BINDINGS", value: stringVar(&c.Authz.RoleBindings, "")},
+		{key: "authz.default_role", env: "AUTHZ_DEFAULT_ROLE", value: stringVar(&c.Authz.DefaultRole, "")},
+		{key: "authz.route_policy", env: "AUTHZ_ROUTE_POLICY", value: stringVar(&c.Authz.RoutePolicy,
+			"* /admin/*=admin,GET /webhooks/*=admin,GET /*=viewer,POST /graphql=viewer,POST /webhooks/*=admin,DELETE /*=admin,POST /*=editor,PUT /*=editor")},
+		{key: "authz.service_policy", env: "AUTHZ_SERVICE_POLICY", value: stringVar(&c.Authz.ServicePolicy,
+			"ProductService.GetProductByID=viewer,ProductService.ListProducts=viewer,ProductService.SearchProducts=viewer,"+
+				"ProductService.GetProductAsOf=viewer,ProductService.GetPriceHistory=viewer,ProductService.GetProductBySKU=viewer,"+
+				"ProductService.ExportProducts=viewer,ProductService.CreateProduct=editor,ProductService.CreateProducts=editor,"+
+				"ProductService.UpdateProduct=editor,ProductService.DeleteProduct=admin,ProductService.RestoreProduct=admin,"+
+				"ProductService.ListDeletedProducts=editor,AuditService.GetProductHistory=editor,AuditService.ListEntries=admin,"+
+				"ImportService.ImportProducts=editor,ImportService.GetImportJob=editor,"+
+				"ImageService.ReplaceImages=editor,ImageService.ListImages=viewer,ImageService.GetImageContent=viewer")},
+
+		{key: "rate_limit.enabled", env: "RATE_LIMIT_ENABLED", value: boolVar(&c.RateLimit.Enabled, false)},
+		{key: "rate_limit.key", env: "RATE_LIMIT_KEY", value: stringVar(&c.RateLimit.Key, "ip")},
+		{key: "rate_limit.rate", env: "RATE_LIMIT_RATE", value: floatVar(&c.RateLimit.Rate, 10)},
+		{key: "rate_limit.burst", env: "RATE_LIMIT_BURST", value: intVar(&c.RateLimit.Burst, 20)},
+		{key: "rate_limit.routes", env: "RATE_LIMIT_ROUTES", value: stringVar(&c.RateLimit.Routes, "")},
+		{key: "rate_limit.exempt_paths", env: "RATE_LIMIT_EXEMPT_PATHS", value: listVar(&c.RateLimit.ExemptPaths, "/health", "/metrics")},
+
+		{key: "idempotency.ttl", env: "IDEMPOTENCY_TTL", value: durationVar(&c.Idempotency.TTL, 24*time.Hour)},
+		{key: "idempotency.max_body_size", env: "IDEMPOTENCY_MAX_BODY_SIZE", value: intVar(&c.Idempotency.MaxBodySize, 10<<20)},
+
+		{key: "batch.max_size", env: "BATCH_MAX_SIZE", value: intVar(&c.Batch.MaxSize, 1000)},
+
+		{key: "import.max_rows", env: "IMPORT_MAX_ROWS", value: intVar(&c.Import.MaxRows, 100000)},
+		{key: "import.sync_max_rows", env: "IMPORT_SYNC_MAX_ROWS", value: intVar(&c.Import.SyncMaxRows, 500)},
+		{key: "import.chunk_size", env: "IMPORT_CHUNK_SIZE", value: intVar(&c.Import.ChunkSize, 100)},
+		{key: "import.queue_size", env: "IMPORT_QUEUE_SIZE", value: intVar(&c.Import.QueueSize, 10)},
+		{key: "import.job_retention", env: "IMPORT_JOB_RETENTION", value: durationVar(&c.Import.JobRetention, time.Hour)},
+
+		{key: "persistence.dir", env: "PERSISTENCE_DIR", value: stringVar(&c.Persistence.Dir, "")},
+		{key: "persistence.snapshot_interval", env: "PERSISTENCE_SNAPSHOT_INTERVAL", value: durationVar(&c.Persistence.SnapshotInterval, 5*time.Minute)},
+		{key: "persistence.sync_writes", env: "PERSISTENCE_SYNC_WRITES", value: boolVar(&c.Persistence.SyncWrites, true)},
+
+		{key: "cache.enabled", env: "CACHE_ENABLED", value: boolVar(&c.Cache.Enabled, false)},
+		{key: "cache.max_entries", env: "CACHE_MAX_ENTRIES", value: intVar(&c.Cache.MaxEntries, 1000)},
+		{key: "cache.ttl", env: "CACHE_TTL", value: durationVar(&c.Cache.TTL, time.Minute)},
+
+		{key: "trash.retention", env: "TRASH_RETENTION", value: durationVar(&c.Trash.Retention, 7*24*time.Hour)},
+		{key: "trash.purge_interval", env: "TRASH_PURGE_INTERVAL", value: durationVar(&c.Trash.PurgeInterval, time.Hour)},
+
+		{key: "audit.file_path", env: "AUDIT_FILE", value: stringVar(&c.Audit.FilePath, "")},
+
+		{key: "images.dir", env: "IMAGES_DIR", value: stringVar(&c.Images.Dir, filepath.Join(os.TempDir(), "products-api", "images"))},
+		{key: "images.max_size", env: "IMAGE_MAX_SIZE", value: intVar(&c.Images.MaxSize, 5<<20)},
+		{key: "images.max_count", env: "IMAGE_MAX_COUNT", value: intVar(&c.Images.MaxCount, 10)},
+		{key: "images.thumbnail_size", env: "IMAGE_THUMBNAIL_SIZE", value: intVar(&c.Images.ThumbnailSize, 128)},
+		{key: "images.queue_size", env: "IMAGE_QUEUE_SIZE", value: intVar(&c.Images.QueueSize, 100)},
 	}
-	return defaultValue
 }
@@ -0,0 +1,406 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// Where a setting got its value from, lowest precedence first
+const (
+	sourceDefault = "default"
+	sourceFile    = "file"
+	sourceEnv     = "env"
+	sourceFlag    = "flag"
+)
+
+// setting binds one configuration field to its key in the config file, which is also its flag name,
+// and to its environment variable
+type setting struct {
+	key    string
+	env    string
+	value  value
+	secret bool
+}
+
+// value is a typed configuration field that can be set from text
+type value interface {
+	Set(raw string) error
+	String() string
+	// reset sets the field back to its default
+	reset()
+}
+
+// Load builds the configuration from, in increasing order of precedence, the defaults, a YAML config file,
+// environment variables and the command-line flags in args.
+// The config file is named by the -config flag or CONFIG_FILE; without one only the other sources apply.
+// Every invalid value is reported, joined in one error, rather than replaced by its default.
+func Load(args []string) (*Config, error) {
+	c := &Config{sources: make(map[string]string)}
+	settings := c.settings()
+	for _, s := range settings {
+		s.value.reset()
+	}
+
+	flags, path, err := parseFlags(settings, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	fileValues := map[string]string{}
+	c.file = path
+	if path != "" {
+		if fileValues, err = readFile(path, settings); err != nil {
+			if fileValues == nil {
+				return nil, err
+			}
+			errs = append(errs, err)
+		}
+	}
+
+	for _, s := range settings {
+		if raw, ok := fileValues[s.key]; ok {
+			errs = append(errs, c.apply(s, raw, sourceFile, path))
+		}
+		if raw := os.Getenv(s.env); raw != "" {
+			errs = append(errs, c.apply(s, raw, sourceEnv, s.env))
+		}
+		if raw, ok := flags[s.key]; ok {
+			errs = append(errs, c.apply(s, raw, sourceFlag, "-"+s.key))
+		}
+	}
+	errs = append(errs, c.validate()...)
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// File returns the config file the configuration was loaded from, or "" without one
+func (c *Config) File() string {
+	return c.file
+}
+
+// Diff returns the keys of the settings whose values differ between c and other, in declaration order
+func (c *Config) Diff(other *Config) []string {
+	var keys []string
+	ours, theirs := c.settings(), other.settings()
+	for i, s := range ours {
+		if s.value.String() != theirs[i].value.String() {
+			keys = append(keys, s.key)
+		}
+	}
+	return keys
+}
+
+// apply sets a setting from one source, naming the origin of the value if it is invalid
+func (c *Config) apply(s *setting, raw, source, origin string) error {
+	if err := s.value.Set(raw); err != nil {
+		if s.secret {
+			raw = maskedValue
+		}
+		return fmt.Errorf("%s: invalid value %q from %s: %w", s.key, raw, origin, err)
+	}
+	c.sources[s.key] = source
+	return nil
+}
+
+// parseFlags returns the raw value of every flag set in args, keyed by setting, and the config file path
+func parseFlags(settings []*setting, args []string) (map[string]string, string, error) {
+	fs := flag.NewFlagSet("products-api", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML config file (env CONFIG_FILE)")
+
+	flags := make(map[string]string)
+	for _, s := range settings {
+		_, isBool := s.value.(*boolValue)
+		fs.Var(&flagRecorder{flags: flags, key: s.key, def: s.value.String(), isBool: isBool}, s.key, "env `"+s.env+"`")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, "", err
+	}
+	if fs.NArg() > 0 {
+		return nil, "", fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	return flags, *path, nil
+}
+
+// flagRecorder keeps the raw value of a flag, so it can be applied after the file and the environment
+type flagRecorder struct {
+	flags  map[string]string
+	key    string
+	def    string
+	isBool bool
+}
+
+func (f *flagRecorder) Set(raw string) error {
+	f.flags[f.key] = raw
+	return nil
+}
+
+func (f *flagRecorder) String() string   { return f.def }
+func (f *flagRecorder) IsBoolFlag() bool { return f.isBool }
+
+// readFile reads the raw values of a YAML config file, a mapping of sections to mappings of settings.
+// Unknown sections and settings are errors; the known values are still returned alongside them.
+func readFile(path string, settings []*setting) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var sections map[string]map[string]interface{}
+	if err := yaml.UnmarshalStrict(data, &sections); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	known := make(map[string]bool, len(settings))
+	for _, s := range settings {
+		known[s.key] = true
+	}
+
+	fileValues := make(map[string]interface{})
+	for section, fields := range sections {
+		for name, v := range fields {
+			fileValues[section+"."+name] = v
+		}
+	}
+
+	values := make(map[string]string)
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(fileValues)) {
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("%s: unknown setting in %s", key, path))
+			continue
+		}
+		raw, err := fileValue(fileValues[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w in %s", key, err, path))
+			continue
+		}
+		values[key] = raw
+	}
+	return values, errors.Join(errs...)
+}
+
+// fileValue converts a YAML value to the text form settings are parsed from; lists become comma-separated
+func fileValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			switch item.(type) {
+			case []interface{}, map[interface{}]interface{}:
+				return "", errors.New("list items must be values")
+			}
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ","), nil
+	case map[interface{}]interface{}:
+		return "", errors.New("must be a value or a list, not a mapping")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// validate checks the rules that involve more than one value or go beyond the type of a value
+func (c *Config) validate() []error {
+	var errs []error
+	for _, port := range []struct{ key, value string }{{"server.port", c.Server.Port}, {"grpc.port", c.GRPC.Port}} {
+		if p, err := strconv.Atoi(port.value); err != nil || p < 1 || p > 65535 {
+			errs = append(errs, fmt.Errorf("%s: %q is not a port number", port.key, port.value))
+		}
+	}
+	if c.Authz.Enabled && !c.Auth.Enabled {
+		errs = append(errs, errors.New("authz.enabled: authorization requires auth.enabled"))
+	}
+	if c.OTLP.Enabled && c.OTLP.Endpoint == "" {
+		errs = append(errs, errors.New("otlp.endpoint: required when otlp.enabled is set"))
+	}
+	if c.Inventory.ReservationTTL > c.Inventory.MaxReservationTTL {
+		errs = append(errs, errors.New("inventory.reservation_ttl: must not exceed inventory.max_reservation_ttl"))
+	}
+	for _, route := range c.Server.DisabledRoutes {
+		if method, pattern, ok := strings.Cut(route, " "); !ok || method == "" || !strings.HasPrefix(pattern, "/") {
+			errs = append(errs, fmt.Errorf("server.disabled_routes: invalid route %q, expected METHOD /route", route))
+		}
+	}
+	return errs
+}
+
+type stringValue struct {
+	p   *string
+	def string
+}
+
+func stringVar(p *string, def string) *stringValue { return &stringValue{p: p, def: def} }
+
+func (v *stringValue) Set(raw string) error {
+	*v.p = raw
+	return nil
+}
+
+func (v *stringValue) String() string { return *v.p }
+func (v *stringValue) reset()         { *v.p = v.def }
+
+type boolValue struct {
+	p   *bool
+	def bool
+}
+
+func boolVar(p *bool, def bool) *boolValue { return &boolValue{p: p, def: def} }
+
+// Set accepts the forms of strconv.ParseBool and yes or no; anything else is an error rather than false
+func (v *boolValue) Set(raw string) error {
+	switch strings.ToLower(raw) {
+	case "yes":
+		*v.p = true
+		return nil
+	case "no":
+		*v.p = false
+		return nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return errors.New("must be true or false")
+	}
+	*v.p = b
+	return nil
+}
+
+func (v *boolValue) String() string { return strconv.FormatBool(*v.p) }
+func (v *boolValue) reset()         { *v.p = v.def }
+
+type intValue struct {
+	p   *int
+	def int
+}
+
+func intVar(p *int, def int) *intValue { return &intValue{p: p, def: def} }
+
+func (v *intValue) Set(raw string) error {
+	i, err := strconv.Atoi(raw)
+	if err != nil || i <= 0 {
+		return errors.New("must be a positive integer")
+	}
+	*v.p = i
+	return nil
+}
+
+func (v *intValue) String() string { return strconv.Itoa(*v.p) }
+func (v *intValue) reset()         { *v.p = v.def }
+
+type floatValue struct {
+	p   *float64
+	def float64
+}
+
+func floatVar(p *float64, def float64) *floatValue { return &floatValue{p: p, def: def} }
+
+func (v *floatValue) Set(raw string) error {
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil || f <= 0 {
+		return errors.New("must be a positive number")
+	}
+	*v.p = f
+	return nil
+}
+
+func (v *floatValue) String() string { return strconv.FormatFloat(*v.p, 'g', -1, 64) }
+func (v *floatValue) reset()         { *v.p = v.def }
+
+// ratioValue is a fraction between 0 and 1 inclusive
+type ratioValue struct {
+	p   *float64
+	def float64
+}
+
+func ratioVar(p *float64, def float64) *ratioValue { return &ratioValue{p: p, def: def} }
+
+func (v *ratioValue) Set(raw string) error {
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil || f < 0 || f > 1 {
+		return errors.New("must be a number between 0 and 1")
+	}
+	*v.p = f
+	return nil
+}
+
+func (v *ratioValue) String() string { return strconv.FormatFloat(*v.p, 'g', -1, 64) }
+func (v *ratioValue) reset()         { *v.p = v.def }
+
+type levelValue struct {
+	p   *slog.Level
+	def slog.Level
+}
+
+func levelVar(p *slog.Level, def slog.Level) *levelValue { return &levelValue{p: p, def: def} }
+
+// Set accepts the slog level names debug, info, warn and error, in any case
+func (v *levelValue) Set(raw string) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(raw)); err != nil {
+		return errors.New("must be debug, info, warn or error")
+	}
+	*v.p = level
+	return nil
+}
+
+func (v *levelValue) String() string { return strings.ToLower(v.p.String()) }
+func (v *levelValue) reset()         { *v.p = v.def }
+
+type durationValue struct {
+	p   *time.Duration
+	def time.Duration
+}
+
+func durationVar(p *time.Duration, def time.Duration) *durationValue {
+	return &durationValue{p: p, def: def}
+}
+
+func (v *durationValue) Set(raw string) error {
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return errors.New("must be a positive duration such as 30s or 5m")
+	}
+	*v.p = d
+	return nil
+}
+
+func (v *durationValue) String() string { return v.p.String() }
+func (v *durationValue) reset()         { *v.p = v.def }
+
+// listValue is a comma-separated list; setting it replaces the whole list
+type listValue struct {
+	p   *[]string
+	def []string
+}
+
+func listVar(p *[]string, def ...string) *listValue { return &listValue{p: p, def: def} }
+
+func (v *listValue) Set(raw string) error {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	*v.p = values
+	return nil
+}
+
+func (v *listValue) String() string { return strings.Join(*v.p, ",") }
+func (v *listValue) reset()         { *v.p = append([]string(nil), v.def...) }
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// maskedValue replaces secrets when the configuration is printed or reported
+const maskedValue = "********"
+
+// Print writes the effective configuration as a YAML config file, each value commented with the source it came from.
+// Secrets that are set are masked.
+func (c *Config) Print(w io.Writer) error {
+	section := ""
+	for _, s := range c.settings() {
+		name, field, _ := strings.Cut(s.key, ".")
+		if name != section {
+			if section != "" {
+				if _, err := fmt.Fprintln(w); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintf(w, "%s:\n", name); err != nil {
+				return err
+			}
+			section = name
+		}
+
+		source := c.sources[s.key]
+		if source == "" {
+			source = sourceDefault
+		}
+		if _, err := fmt.Fprintf(w, "  %s: %s # %s (%s)\n", field, render(s), source, s.env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render formats the value of a setting as YAML
+func render(s *setting) string {
+	switch v := s.value.(type) {
+	case *stringValue:
+		if s.secret && *v.p != "" {
+			return quote(maskedValue)
+		}
+		return quote(*v.p)
+	case *listValue:
+		items := make([]string, len(*v.p))
+		for i, item := range *v.p {
+			items[i] = quote(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return v.String()
+	}
+}
+
+// quote formats a string as a YAML scalar, quoted only when it would otherwise read as another type
+func quote(value string) string {
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%q", value)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
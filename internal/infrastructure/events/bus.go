@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// Handler consumes events delivered by the in-process bus
+type Handler func(ctx context.Context, event domain.Event) error
+
+// Bus is an in-process publisher that fans events out to registered handlers
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewBus creates a new in-process event bus
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Subscribe registers a named handler, replacing any previous handler with the same name
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[name] = handler
+}
+
+// Name identifies the publisher
+func (b *Bus) Name() string {
+	return "inprocess"
+}
+
+// Publish synchronously invokes every handler with the event
+func (b *Bus) Publish(ctx context.Context, event domain.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var errs []error
+	for name, handler := range b.handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("handler %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,210 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Dispatcher delivers outbox messages to the registered publishers.
+// Delivery is at-least-once per publisher: a message stays in the outbox until every
+// publisher has accepted it or it runs out of attempts.
+type Dispatcher struct {
+	outbox      domain.OutboxRepository
+	notify      <-chan struct{}
+	publishers  []Publisher
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	tracer      trace.Tracer
+	logger      *slog.Logger
+	published   metric.Int64Counter
+	lag         metric.Float64Histogram
+}
+
+// NewDispatcher creates a new outbox dispatcher.
+// notify may be nil, in which case the outbox is only polled every interval.
+func NewDispatcher(
+	outbox domain.OutboxRepository,
+	notify <-chan struct{},
+	publishers []Publisher,
+	interval time.Duration,
+	batchSize int,
+	maxAttempts int,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *Dispatcher {
+	// Initialize metrics
+	published, _ := meter.Int64Counter(
+		"events.published",
+		metric.WithDescription("Total number of event deliveries to publishers"),
+	)
+
+	lag, _ := meter.Float64Histogram(
+		"events.dispatch.lag",
+		metric.WithDescription("Time between an event occurring and its delivery to all publishers"),
+		metric.WithUnit("s"),
+	)
+
+	return &Dispatcher{
+		outbox:      outbox,
+		notify:      notify,
+		publishers:  publishers,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		tracer:      tracer,
+		logger:      logger,
+		published:   published,
+		lag:         lag,
+	}
+}
+
+// Run blocks, dispatching pending messages when notified or on every tick until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	publishers := make([]string, len(d.publishers))
+	for i, p := range d.publishers {
+		publishers[i] = p.Name()
+	}
+	d.logger.Info("Event dispatcher started",
+		slog.Any("publishers", publishers),
+		slog.String("interval", d.interval.String()),
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info("Event dispatcher stopped")
+			return
+		case <-d.notify:
+			d.dispatchPending(ctx)
+		case <-ticker.C:
+			d.dispatchPending(ctx)
+		}
+	}
+}
+
+// dispatchPending delivers one batch of the outbox messages due for an attempt
+func (d *Dispatcher) dispatchPending(ctx context.Context) {
+	messages, err := d.outbox.Pending(ctx, time.Now(), d.batchSize)
+	if err != nil {
+		d.logger.ErrorContext(ctx, "Failed to read outbox",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	for _, message := range messages {
+		d.dispatch(ctx, message)
+	}
+}
+
+// dispatch delivers a single message in its own trace, linked to the producing request
+func (d *Dispatcher) dispatch(ctx context.Context, message *domain.OutboxMessage) {
+	event := message.Event
+	producer := trace.SpanContextFromContext(
+		otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(message.TraceContext)),
+	)
+
+	opts := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "outbox"),
+			attribute.String("messaging.operation.type", "process"),
+			attribute.String("messaging.message.id", event.ID),
+			attribute.String("event.type", string(event.Type)),
+			attribute.String("product.id", event.ProductID),
+			attribute.Int("event.attempt", message.Attempts+1),
+		),
+	}
+	if producer.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: producer}))
+	}
+
+	ctx, span := d.tracer.Start(ctx, "EventDispatcher.Dispatch", opts...)
+	defer span.End()
+
+	failed := 0
+	for _, publisher := range d.publishers {
+		if message.DeliveredTo[publisher.Name()] {
+			continue
+		}
+		if err := d.publish(ctx, publisher, event); err != nil {
+			failed++
+			continue
+		}
+		_ = d.outbox.MarkDelivered(ctx, event.ID, publisher.Name())
+	}
+
+	if failed == 0 {
+		_ = d.outbox.MarkDispatched(ctx, event.ID)
+		d.lag.Record(ctx, time.Since(event.OccurredAt).Seconds(),
+			metric.WithAttributes(attribute.String("event.type", string(event.Type))),
+		)
+		span.SetStatus(codes.Ok, "Event dispatched")
+		return
+	}
+
+	// Failed messages are retried after an interval, leaving the batches in between to newer messages
+	_ = d.outbox.RecordAttempt(ctx, event.ID, time.Now().Add(d.interval))
+	if message.Attempts+1 >= d.maxAttempts {
+		_ = d.outbox.MarkDispatched(ctx, event.ID)
+		span.SetStatus(codes.Error, "Event discarded after max attempts")
+		d.logger.ErrorContext(ctx, "Event discarded after max delivery attempts",
+			slog.String("event_id", event.ID),
+			slog.String("event_type", string(event.Type)),
+			slog.Int("attempts", message.Attempts+1),
+		)
+		return
+	}
+
+	span.SetStatus(codes.Error, "Event delivery failed, will retry")
+}
+
+// publish delivers an event to one publisher inside a child span
+func (d *Dispatcher) publish(ctx context.Context, publisher Publisher, event domain.Event) error {
+	ctx, span := d.tracer.Start(ctx, "EventPublisher.Publish",
+		trace.WithAttributes(
+			attribute.String("event.publisher", publisher.Name()),
+			attribute.String("event.type", string(event.Type)),
+		),
+	)
+	defer span.End()
+
+	result := "success"
+	err := publisher.Publish(ctx, event)
+	if err != nil {
+		result = "failure"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Publish failed")
+		d.logger.WarnContext(ctx, "Failed to publish event",
+			slog.String("event_id", event.ID),
+			slog.String("publisher", publisher.Name()),
+			slog.String("error", err.Error()),
+		)
+	} else {
+		span.SetStatus(codes.Ok, "Event published")
+	}
+
+	d.published.Add(ctx, 1,
+		metric.WithAttributes(
+			attribute.String("publisher", publisher.Name()),
+			attribute.String("event.type", string(event.Type)),
+			attribute.String("result", result),
+		),
+	)
+	return err
+}
@@ -0,0 +1,15 @@
+package events
+
+import (
+	"context"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// Publisher delivers domain events to a destination
+type Publisher interface {
+	// Name identifies the publisher in delivery bookkeeping, spans and metrics
+	Name() string
+	// Publish delivers a single event; a returned error causes the event to be retried
+	Publish(ctx context.Context, event domain.Event) error
+}
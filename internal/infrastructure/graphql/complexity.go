@@ -0,0 +1,38 @@
+package graphql
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// budget counts the cost of an operation while graphql-go executes it.
+// Every resolved field costs 1, so the fields under a list are counted once per item
+// actually returned, which the resolvers cap at maxPageSize per page.
+type budget struct {
+	max    int64
+	spent  atomic.Int64
+	cancel context.CancelFunc
+}
+
+// newBudget returns a budget of max that cancels the execution once it is spent
+func newBudget(max int, cancel context.CancelFunc) *budget {
+	return &budget{max: int64(max), cancel: cancel}
+}
+
+// charge spends the cost of one field and stops the execution when the budget is exceeded,
+// so graphql-go calls no further resolvers
+func (b *budget) charge() {
+	if b.spent.Add(1) > b.max {
+		b.cancel()
+	}
+}
+
+// exceeded reports whether the operation went over the budget
+func (b *budget) exceeded() bool {
+	return b.spent.Load() > b.max
+}
+
+// complexity returns the cost counted so far
+func (b *budget) complexity() int {
+	return int(b.spent.Load())
+}
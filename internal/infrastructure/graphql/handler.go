@@ -0,0 +1,167 @@
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/config"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// GraphQLRequest is a GraphQL-over-HTTP request body
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// Handler serves GraphQL requests over HTTP
+type Handler struct {
+	schema        *graphqlgo.Schema
+	maxComplexity int
+	logger        *slog.Logger
+	operations    metric.Int64Counter
+	duration      metric.Float64Histogram
+}
+
+// NewHandler creates a new GraphQL handler over the product and category services
+func NewHandler(
+	cfg *config.GraphQLConfig,
+	products service.ProductUseCases,
+	categories service.CategoryUseCases,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *Handler {
+	// Initialize metrics
+	operations, _ := meter.Int64Counter(
+		"graphql.operations",
+		metric.WithDescription("Total number of GraphQL operations"),
+	)
+
+	duration, _ := meter.Float64Histogram(
+		"graphql.operation.duration",
+		metric.WithDescription("Duration of GraphQL operations"),
+		metric.WithUnit("s"),
+	)
+
+	return &Handler{
+		schema: graphqlgo.MustParseSchema(schema, NewResolver(products, categories),
+			graphqlgo.Tracer(NewTracer(tracer)),
+			graphqlgo.MaxDepth(cfg.MaxDepth),
+			graphqlgo.UseStringDescriptions(),
+		),
+		maxComplexity: cfg.MaxComplexity,
+		logger:        logger,
+		operations:    operations,
+		duration:      duration,
+	}
+}
+
+// ServeHTTP handles POST /graphql
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+
+	var req GraphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(ctx, "Failed to decode GraphQL request body",
+			slog.String("error", err.Error()),
+		)
+		writeErrors(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// graphql-go stops calling resolvers once the budget cancels the context
+	execCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	op := &operation{budget: newBudget(h.maxComplexity, cancel)}
+
+	result := h.schema.Exec(withOperation(execCtx, op), req.Query, req.OperationName, req.Variables)
+
+	// Make the operation visible on the HTTP server span and metrics
+	name, kind := op.describe()
+	if name == "" {
+		name = req.OperationName
+	}
+	if kind == "" {
+		kind = "query"
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("graphql.operation.name", name),
+		attribute.String("graphql.operation.type", kind),
+	}
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attrs...)
+	span.SetAttributes(attribute.Int("graphql.complexity", op.budget.complexity()))
+	if labeler, ok := otelhttp.LabelerFromContext(ctx); ok {
+		labeler.Add(attrs...)
+	}
+
+	if op.budget.exceeded() {
+		span.AddEvent("graphql.complexity_exceeded", trace.WithAttributes(
+			attribute.Int("graphql.complexity.max", h.maxComplexity),
+		))
+		h.logger.WarnContext(ctx, "GraphQL operation stopped, complexity limit exceeded",
+			slog.String("operation_name", name),
+			slog.String("operation_type", kind),
+			slog.Int("max_complexity", h.maxComplexity),
+		)
+		h.record(ctx, attrs, "rejected", start)
+
+		// A query changed nothing, so its partial data is dropped; a mutation keeps the
+		// data of the fields that ran so the client can tell what was applied
+		exceeded := &errors.QueryError{Message: fmt.Sprintf("operation complexity exceeds the limit of %d", h.maxComplexity)}
+		if kind != "mutation" {
+			response.JSON(w, http.StatusBadRequest, &graphqlgo.Response{Errors: []*errors.QueryError{exceeded}})
+			return
+		}
+		response.JSON(w, http.StatusOK, &graphqlgo.Response{Data: result.Data, Errors: []*errors.QueryError{exceeded}})
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		h.logger.WarnContext(ctx, "GraphQL operation completed with errors",
+			slog.String("operation_name", name),
+			slog.Int("error_count", len(result.Errors)),
+			slog.String("error", result.Errors[0].Message),
+		)
+		h.record(ctx, attrs, "error", start)
+	} else {
+		h.logger.InfoContext(ctx, "GraphQL operation completed successfully",
+			slog.String("operation_name", name),
+			slog.String("operation_type", kind),
+		)
+		h.record(ctx, attrs, "success", start)
+	}
+
+	response.JSON(w, http.StatusOK, result)
+}
+
+// record increments the operations counter and records the operation duration
+func (h *Handler) record(ctx context.Context, attrs []attribute.KeyValue, result string, start time.Time) {
+	h.operations.Add(ctx, 1, metric.WithAttributes(append(attrs, attribute.String("result", result))...))
+	h.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+}
+
+// writeErrors writes a GraphQL response carrying only errors
+func writeErrors(w http.ResponseWriter, status int, message string) {
+	response.JSON(w, status, &graphqlgo.Response{
+		Errors: []*errors.QueryError{{Message: message}},
+	})
+}
@@ -0,0 +1,419 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// maxPageSize caps the number of products returned by a single connection field
+const maxPageSize = 100
+
+var (
+	errInvalidPageSize = errors.New("first must be between 0 and 100")
+	errInvalidCursor   = errors.New("invalid cursor")
+)
+
+// Resolver is the root resolver, mapping queries and mutations onto the product service
+type Resolver struct {
+	products   service.ProductUseCases
+	categories service.CategoryUseCases
+}
+
+// NewResolver creates a new root resolver
+func NewResolver(products service.ProductUseCases, categories service.CategoryUseCases) *Resolver {
+	return &Resolver{
+		products:   products,
+		categories: categories,
+	}
+}
+
+// Product resolves Query.product
+func (r *Resolver) Product(ctx context.Context, args struct{ ID graphqlgo.ID }) (*productResolver, error) {
+	product, err := r.products.GetProductByID(ctx, string(args.ID))
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return &productResolver{product: product, categories: r.categories}, nil
+}
+
+// Products resolves Query.products
+func (r *Resolver) Products(ctx context.Context, args struct {
+	First int32
+	After *string
+}) (*connectionResolver, error) {
+	products, err := r.products.ListProducts(ctx, false)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return r.paginate(products, args.First, args.After)
+}
+
+// SearchProducts resolves Query.searchProducts
+func (r *Resolver) SearchProducts(ctx context.Context, args struct {
+	Query string
+	First int32
+	After *string
+}) (*connectionResolver, error) {
+	products, err := r.products.SearchProducts(ctx, args.Query)
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return r.paginate(products, args.First, args.After)
+}
+
+// productInput holds the fields shared by CreateProductInput and UpdateProductInput
+type productInput struct {
+	Name        string
+	Description string
+	Price       float64
+	CategoryIDs *[]graphqlgo.ID
+	Variants    *[]variantInput
+}
+
+// variantInput holds the fields of VariantInput
+type variantInput struct {
+	SKU           string
+	Attributes    *[]attributeInput
+	PriceOverride *float64
+	Stock         int32
+}
+
+// attributeInput holds the fields of VariantAttributeInput
+type attributeInput struct {
+	Name  string
+	Value string
+}
+
+// categoryIDs returns the input category IDs as strings
+func (i productInput) categoryIDs() []string {
+	if i.CategoryIDs == nil {
+		return nil
+	}
+	ids := make([]string, len(*i.CategoryIDs))
+	for j, id := range *i.CategoryIDs {
+		ids[j] = string(id)
+	}
+	return ids
+}
+
+// variants returns the input variants as requests, or nil when they were omitted
+func (i productInput) variants() []*dto.VariantRequest {
+	if i.Variants == nil {
+		return nil
+	}
+	reqs := make([]*dto.VariantRequest, len(*i.Variants))
+	for j, v := range *i.Variants {
+		var attributes map[string]string
+		if v.Attributes != nil {
+			attributes = make(map[string]string, len(*v.Attributes))
+			for _, attribute := range *v.Attributes {
+				attributes[attribute.Name] = attribute.Value
+			}
+		}
+		reqs[j] = &dto.VariantRequest{
+			SKU:           v.SKU,
+			Attributes:    attributes,
+			PriceOverride: v.PriceOverride,
+			Stock:         int(v.Stock),
+		}
+	}
+	return reqs
+}
+
+// CreateProduct resolves Mutation.createProduct
+func (r *Resolver) CreateProduct(ctx context.Context, args struct{ Input productInput }) (*productResolver, error) {
+	product, err := r.products.CreateProduct(ctx, &dto.CreateProductRequest{
+		Name:        args.Input.Name,
+		Description: args.Input.Description,
+		Price:       args.Input.Price,
+		CategoryIDs: args.Input.categoryIDs(),
+		Variants:    args.Input.variants(),
+	})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return &productResolver{product: product, categories: r.categories}, nil
+}
+
+// UpdateProduct resolves Mutation.updateProduct
+func (r *Resolver) UpdateProduct(ctx context.Context, args struct {
+	ID    graphqlgo.ID
+	Input productInput
+}) (*productResolver, error) {
+	product, err := r.products.UpdateProduct(ctx, string(args.ID), &dto.UpdateProductRequest{
+		Name:        args.Input.Name,
+		Description: args.Input.Description,
+		Price:       args.Input.Price,
+		CategoryIDs: args.Input.categoryIDs(),
+		Variants:    args.Input.variants(),
+	})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return &productResolver{product: product, categories: r.categories}, nil
+}
+
+// DeleteProduct resolves Mutation.deleteProduct
+func (r *Resolver) DeleteProduct(ctx context.Context, args struct{ ID graphqlgo.ID }) (bool, error) {
+	if err := r.products.DeleteProduct(ctx, string(args.ID)); err != nil {
+		return false, toError(err)
+	}
+
+	return true, nil
+}
+
+// paginate orders products by creation time and returns the page after the cursor
+func (r *Resolver) paginate(products []*dto.ProductResponse, first int32, after *string) (*connectionResolver, error) {
+	if first < 0 || first > maxPageSize {
+		return nil, &resolverError{err: errInvalidPageSize, code: codeBadUserInput}
+	}
+
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].CreatedAt.Equal(products[j].CreatedAt) {
+			return products[i].ID < products[j].ID
+		}
+		return products[i].CreatedAt.Before(products[j].CreatedAt)
+	})
+
+	start := 0
+	if after != nil {
+		id, err := decodeCursor(*after)
+		if err != nil {
+			return nil, &resolverError{err: errInvalidCursor, code: codeBadUserInput}
+		}
+		start = -1
+		for i, product := range products {
+			if product.ID == id {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, &resolverError{err: errInvalidCursor, code: codeBadUserInput}
+		}
+	}
+
+	end := start + int(first)
+	if end > len(products) {
+		end = len(products)
+	}
+
+	return &connectionResolver{
+		page:       products[start:end],
+		total:      len(products),
+		hasNext:    end < len(products),
+		categories: r.categories,
+	}, nil
+}
+
+// productResolver resolves the Product type
+type productResolver struct {
+	product    *dto.ProductResponse
+	categories service.CategoryUseCases
+}
+
+func (r *productResolver) ID() graphqlgo.ID    { return graphqlgo.ID(r.product.ID) }
+func (r *productResolver) Name() string        { return r.product.Name }
+func (r *productResolver) Description() string { return r.product.Description }
+func (r *productResolver) Price() float64      { return r.product.Price }
+func (r *productResolver) CreatedAt() graphqlgo.Time {
+	return graphqlgo.Time{Time: r.product.CreatedAt}
+}
+func (r *productResolver) UpdatedAt() graphqlgo.Time {
+	return graphqlgo.Time{Time: r.product.UpdatedAt}
+}
+
+// CategoryIDs resolves Product.categoryIds
+func (r *productResolver) CategoryIDs() []graphqlgo.ID {
+	ids := make([]graphqlgo.ID, len(r.product.CategoryIDs))
+	for i, id := range r.product.CategoryIDs {
+		ids[i] = graphqlgo.ID(id)
+	}
+	return ids
+}
+
+// Variants resolves Product.variants
+func (r *productResolver) Variants() []*variantResolver {
+	variants := make([]*variantResolver, len(r.product.Variants))
+	for i, variant := range r.product.Variants {
+		variants[i] = &variantResolver{variant: variant}
+	}
+	return variants
+}
+
+// Categories resolves Product.categories with one category service call per category
+func (r *productResolver) Categories(ctx context.Context) ([]*categoryResolver, error) {
+	categories := make([]*categoryResolver, 0, len(r.product.CategoryIDs))
+	for _, id := range r.product.CategoryIDs {
+		category, err := r.categories.GetCategoryByID(ctx, id)
+		if err != nil {
+			return nil, toError(err)
+		}
+		categories = append(categories, &categoryResolver{category: category})
+	}
+	return categories, nil
+}
+
+// variantResolver resolves the Variant type
+type variantResolver struct {
+	variant *dto.VariantResponse
+}
+
+func (r *variantResolver) SKU() string             { return r.variant.SKU }
+func (r *variantResolver) Price() float64          { return r.variant.Price }
+func (r *variantResolver) PriceOverride() *float64 { return r.variant.PriceOverride }
+func (r *variantResolver) Stock() int32            { return int32(r.variant.Stock) }
+
+// Attributes resolves Variant.attributes, ordered by name
+func (r *variantResolver) Attributes() []*attributeResolver {
+	names := make([]string, 0, len(r.variant.Attributes))
+	for name := range r.variant.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	attributes := make([]*attributeResolver, len(names))
+	for i, name := range names {
+		attributes[i] = &attributeResolver{name: name, value: r.variant.Attributes[name]}
+	}
+	return attributes
+}
+
+// attributeResolver resolves the VariantAttribute type
+type attributeResolver struct {
+	name  string
+	value string
+}
+
+func (r *attributeResolver) Name() string  { return r.name }
+func (r *attributeResolver) Value() string { return r.value }
+
+// categoryResolver resolves the Category type
+type categoryResolver struct {
+	category *dto.CategoryResponse
+}
+
+func (r *categoryResolver) ID() graphqlgo.ID    { return graphqlgo.ID(r.category.ID) }
+func (r *categoryResolver) Name() string        { return r.category.Name }
+func (r *categoryResolver) Description() string { return r.category.Description }
+
+// ParentID resolves Category.parentId, which is null for root categories
+func (r *categoryResolver) ParentID() *graphqlgo.ID {
+	if r.category.ParentID == "" {
+		return nil
+	}
+	id := graphqlgo.ID(r.category.ParentID)
+	return &id
+}
+
+// connectionResolver resolves the ProductConnection type
+type connectionResolver struct {
+	page       []*dto.ProductResponse
+	total      int
+	hasNext    bool
+	categories service.CategoryUseCases
+}
+
+// Edges resolves ProductConnection.edges
+func (r *connectionResolver) Edges() []*edgeResolver {
+	edges := make([]*edgeResolver, len(r.page))
+	for i, product := range r.page {
+		edges[i] = &edgeResolver{node: &productResolver{product: product, categories: r.categories}}
+	}
+	return edges
+}
+
+// PageInfo resolves ProductConnection.pageInfo
+func (r *connectionResolver) PageInfo() *pageInfoResolver {
+	info := &pageInfoResolver{hasNextPage: r.hasNext}
+	if len(r.page) > 0 {
+		cursor := encodeCursor(r.page[len(r.page)-1].ID)
+		info.endCursor = &cursor
+	}
+	return info
+}
+
+// TotalCount resolves ProductConnection.totalCount
+func (r *connectionResolver) TotalCount() int32 { return int32(r.total) }
+
+// edgeResolver resolves the ProductEdge type
+type edgeResolver struct {
+	node *productResolver
+}
+
+func (r *edgeResolver) Cursor() string         { return encodeCursor(r.node.product.ID) }
+func (r *edgeResolver) Node() *productResolver { return r.node }
+
+// pageInfoResolver resolves the PageInfo type
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (r *pageInfoResolver) HasNextPage() bool  { return r.hasNextPage }
+func (r *pageInfoResolver) EndCursor() *string { return r.endCursor }
+
+// encodeCursor returns the opaque cursor pointing at a product
+func encodeCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// decodeCursor returns the product ID a cursor points at
+func decodeCursor(cursor string) (string, error) {
+	id, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+const (
+	codeNotFound     = "NOT_FOUND"
+	codeBadUserInput = "BAD_USER_INPUT"
+	codeForbidden    = "FORBIDDEN"
+	codeConflict     = "CONFLICT"
+	codeInternal     = "INTERNAL"
+)
+
+// resolverError carries an error code in the GraphQL error extensions
+type resolverError struct {
+	err  error
+	code string
+}
+
+func (e *resolverError) Error() string { return e.err.Error() }
+
+// Extensions is read by graphql-go to populate the "extensions" field of the error
+func (e *resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// toError maps domain errors to GraphQL error codes, mirroring the HTTP handler mapping
+func toError(err error) error {
+	switch err {
+	case domain.ErrProductNotFound, domain.ErrCategoryNotFound:
+		return &resolverError{err: err, code: codeNotFound}
+	case domain.ErrInvalidProductName, domain.ErrInvalidProductPrice, domain.ErrUnknownCategory,
+		domain.ErrInvalidSKU, domain.ErrInvalidVariantPrice, domain.ErrInvalidVariantStock:
+		return &resolverError{err: err, code: codeBadUserInput}
+	case domain.ErrDuplicateSKU, domain.ErrProductConflict:
+		return &resolverError{err: err, code: codeConflict}
+	case auth.ErrForbidden:
+		return &resolverError{err: err, code: codeForbidden}
+	default:
+		return &resolverError{err: err, code: codeInternal}
+	}
+}
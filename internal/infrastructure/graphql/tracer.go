@@ -0,0 +1,164 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// operationKey is the context key for the operation being executed
+type operationKey struct{}
+
+// operation describes the GraphQL operation of a request. It is filled in by the tracer
+// while graphql-go executes it, since only graphql-go parses the query.
+type operation struct {
+	mu     sync.Mutex
+	name   string
+	kind   string
+	budget *budget
+}
+
+// withOperation returns a context carrying the operation being executed
+func withOperation(ctx context.Context, op *operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// operationFromContext returns the operation being executed, or nil outside the handler
+func operationFromContext(ctx context.Context) *operation {
+	op, _ := ctx.Value(operationKey{}).(*operation)
+	return op
+}
+
+// describe returns the operation name and type
+func (op *operation) describe() (string, string) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	return op.name, op.kind
+}
+
+// setName records the operation name resolved by graphql-go
+func (op *operation) setName(name string) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	op.name = name
+}
+
+// setKind records the operation type the first time a root field is resolved, reporting whether it was unknown until now
+func (op *operation) setKind(kind string) bool {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.kind != "" {
+		return false
+	}
+	op.kind = kind
+	return true
+}
+
+// rootTypes maps the schema's root types to their operation type
+var rootTypes = map[string]string{
+	"Query":    "query",
+	"Mutation": "mutation",
+}
+
+// Tracer implements the graphql-go tracer with one span per operation and per resolver
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer creates a new GraphQL tracer
+func NewTracer(tracer trace.Tracer) *Tracer {
+	return &Tracer{tracer: tracer}
+}
+
+// TraceQuery starts the operation span, named "<type> <operation name>"
+func (t *Tracer) TraceQuery(
+	ctx context.Context,
+	queryString string,
+	operationName string,
+	variables map[string]interface{},
+	varTypes map[string]*introspection.Type,
+) (context.Context, func([]*errors.QueryError)) {
+	// The operation type is only known once its first root field is resolved, see TraceField
+	op := operationFromContext(ctx)
+	if op != nil {
+		op.setName(operationName)
+	}
+
+	ctx, span := t.tracer.Start(ctx, strings.TrimSpace("operation "+operationName),
+		trace.WithAttributes(
+			attribute.String("graphql.operation.name", operationName),
+		),
+	)
+
+	return ctx, func(errs []*errors.QueryError) {
+		defer span.End()
+
+		if op != nil {
+			span.SetAttributes(attribute.Int("graphql.complexity", op.budget.complexity()))
+		}
+
+		if len(errs) > 0 {
+			span.SetAttributes(attribute.Int("graphql.error.count", len(errs)))
+			for _, err := range errs {
+				span.RecordError(err)
+			}
+			span.SetStatus(codes.Error, errs[0].Message)
+			return
+		}
+		span.SetStatus(codes.Ok, "Operation executed successfully")
+	}
+}
+
+// TraceField charges every field to the operation's complexity budget and starts a span
+// named "<parent type>.<field>" for every non-trivial resolver.
+// Trivial resolvers are plain getters and would only add noise to the trace.
+func (t *Tracer) TraceField(
+	ctx context.Context,
+	label string,
+	typeName string,
+	fieldName string,
+	trivial bool,
+	args map[string]interface{},
+) (context.Context, func(*errors.QueryError)) {
+	if op := operationFromContext(ctx); op != nil {
+		op.budget.charge()
+
+		// The first root field reveals the operation type; name the operation span after it
+		if kind, ok := rootTypes[typeName]; ok && op.setKind(kind) {
+			name, _ := op.describe()
+			span := trace.SpanFromContext(ctx)
+			span.SetName(strings.TrimSpace(fmt.Sprintf("%s %s", kind, name)))
+			span.SetAttributes(attribute.String("graphql.operation.type", kind))
+		}
+	}
+
+	if trivial {
+		return ctx, func(*errors.QueryError) {}
+	}
+
+	ctx, span := t.tracer.Start(ctx, fmt.Sprintf("%s.%s", typeName, fieldName),
+		trace.WithAttributes(
+			attribute.String("graphql.field.name", fieldName),
+			attribute.String("graphql.field.alias", label),
+			attribute.String("graphql.field.parent_type", typeName),
+		),
+	)
+
+	return ctx, func(err *errors.QueryError) {
+		defer span.End()
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Message)
+			return
+		}
+		span.SetStatus(codes.Ok, "Field resolved successfully")
+	}
+}
@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	infraauth "github.com/mrops-br/testing-otlp-api/internal/infrastructure/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// healthServicePrefix is exempt from authentication so probes keep working
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// authenticator authenticates RPCs from the x-api-key or authorization metadata
+type authenticator struct {
+	authenticator infraauth.Authenticator
+	logger        *slog.Logger
+}
+
+// authenticate returns a context carrying the principal of the RPC
+func (a *authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if strings.HasPrefix(fullMethod, healthServicePrefix) {
+		return ctx, nil
+	}
+
+	var credentials infraauth.Credentials
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		credentials.APIKey = values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		scheme, token, ok := strings.Cut(values[0], " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			credentials.BearerToken = strings.TrimSpace(token)
+		}
+	}
+
+	principal, err := a.authenticator.Authenticate(ctx, credentials)
+	if err != nil {
+		a.logger.WarnContext(ctx, "RPC authentication failed",
+			slog.String("rpc.method", fullMethod),
+			slog.String("error", err.Error()),
+		)
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// unary is the unary server interceptor
+func (a *authenticator) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// stream is the streaming server interceptor
+func (a *authenticator) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream overrides the stream context with the authenticated one
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,174 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+
+	productv1 "github.com/mrops-br/testing-otlp-api/api/product/v1"
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ProductServer implements the products.v1.ProductService gRPC API on top of the product service
+type ProductServer struct {
+	productv1.UnimplementedProductServiceServer
+	service service.ProductUseCases
+	logger  *slog.Logger
+}
+
+// NewProductServer creates a new product gRPC server
+func NewProductServer(service service.ProductUseCases, logger *slog.Logger) *ProductServer {
+	return &ProductServer{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateProduct handles products.v1.ProductService/CreateProduct
+func (s *ProductServer) CreateProduct(ctx context.Context, req *productv1.CreateProductRequest) (*productv1.Product, error) {
+	product, err := s.service.CreateProduct(ctx, &dto.CreateProductRequest{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Price:       req.GetPrice(),
+		CategoryIDs: req.GetCategoryIds(),
+		Variants:    fromProtoVariants(req.GetVariants()),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(product), nil
+}
+
+// GetProduct handles products.v1.ProductService/GetProduct
+func (s *ProductServer) GetProduct(ctx context.Context, req *productv1.GetProductRequest) (*productv1.Product, error) {
+	product, err := s.service.GetProductByID(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(product), nil
+}
+
+// ListProducts handles products.v1.ProductService/ListProducts, streaming one message per product
+func (s *ProductServer) ListProducts(req *productv1.ListProductsRequest, stream grpc.ServerStreamingServer[productv1.Product]) error {
+	ctx := stream.Context()
+
+	products, err := s.service.ListProducts(ctx, false)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	for _, product := range products {
+		if err := stream.Send(toProto(product)); err != nil {
+			s.logger.WarnContext(ctx, "Failed to stream product",
+				slog.String("product_id", product.ID),
+				slog.String("error", err.Error()),
+			)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateProduct handles products.v1.ProductService/UpdateProduct; variants are kept unless the request sets them
+func (s *ProductServer) UpdateProduct(ctx context.Context, req *productv1.UpdateProductRequest) (*productv1.Product, error) {
+	var variants []*dto.VariantRequest
+	if req.GetVariants() != nil {
+		variants = append([]*dto.VariantRequest{}, fromProtoVariants(req.GetVariants().GetVariants())...)
+	}
+
+	product, err := s.service.UpdateProduct(ctx, req.GetId(), &dto.UpdateProductRequest{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Price:       req.GetPrice(),
+		CategoryIDs: req.GetCategoryIds(),
+		Variants:    variants,
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(product), nil
+}
+
+// DeleteProduct handles products.v1.ProductService/DeleteProduct
+func (s *ProductServer) DeleteProduct(ctx context.Context, req *productv1.DeleteProductRequest) (*productv1.DeleteProductResponse, error) {
+	if err := s.service.DeleteProduct(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &productv1.DeleteProductResponse{}, nil
+}
+
+// toStatus maps product domain errors to gRPC status codes, mirroring the HTTP handler mapping
+func toStatus(err error) error {
+	switch err {
+	case domain.ErrProductNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case domain.ErrInvalidProductName, domain.ErrInvalidProductPrice, domain.ErrUnknownCategory,
+		domain.ErrInvalidSKU, domain.ErrInvalidVariantPrice, domain.ErrInvalidVariantStock:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case domain.ErrDuplicateSKU:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case domain.ErrProductConflict:
+		return status.Error(codes.Aborted, err.Error())
+	case auth.ErrForbidden:
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// toProto converts a ProductResponse to its protobuf message
+func toProto(p *dto.ProductResponse) *productv1.Product {
+	return &productv1.Product{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       p.Price,
+		CategoryIds: p.CategoryIDs,
+		CreatedAt:   timestamppb.New(p.CreatedAt),
+		UpdatedAt:   timestamppb.New(p.UpdatedAt),
+		Variants:    toProtoVariants(p.Variants),
+	}
+}
+
+// toProtoVariants converts variant responses to their protobuf messages
+func toProtoVariants(variants []*dto.VariantResponse) []*productv1.Variant {
+	messages := make([]*productv1.Variant, len(variants))
+	for i, v := range variants {
+		messages[i] = &productv1.Variant{
+			Sku:           v.SKU,
+			Attributes:    v.Attributes,
+			Price:         v.Price,
+			PriceOverride: v.PriceOverride,
+			Stock:         int32(v.Stock),
+		}
+	}
+	return messages
+}
+
+// fromProtoVariants converts protobuf variants to variant requests, ignoring their price
+func fromProtoVariants(messages []*productv1.Variant) []*dto.VariantRequest {
+	if len(messages) == 0 {
+		return nil
+	}
+	reqs := make([]*dto.VariantRequest, len(messages))
+	for i, m := range messages {
+		reqs[i] = &dto.VariantRequest{
+			SKU:           m.GetSku(),
+			Attributes:    m.GetAttributes(),
+			PriceOverride: m.PriceOverride,
+			Stock:         int(m.GetStock()),
+		}
+	}
+	return reqs
+}
@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+
+	productv1 "github.com/mrops-br/testing-otlp-api/api/product/v1"
+	infraauth "github.com/mrops-br/testing-otlp-api/internal/infrastructure/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/config"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/telemetry"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server represents the gRPC server
+type Server struct {
+	server *grpc.Server
+	health *health.Server
+	config *config.GRPCConfig
+	logger *slog.Logger
+}
+
+// NewServer creates a new gRPC server with otelgrpc instrumentation, health checking and reflection.
+// When an authenticator is given, every RPC except health checks must be authenticated.
+func NewServer(
+	cfg *config.GRPCConfig,
+	products *ProductServer,
+	authn infraauth.Authenticator,
+	logger *slog.Logger,
+	telem *telemetry.Telemetry,
+) *Server {
+	// otelgrpc provides rpc.server.* metrics and a server span per RPC
+	options := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler(
+			otelgrpc.WithTracerProvider(telem.TracerProvider),
+			otelgrpc.WithMeterProvider(telem.MeterProvider),
+		)),
+	}
+	if authn != nil {
+		interceptor := &authenticator{authenticator: authn, logger: logger}
+		options = append(options,
+			grpc.ChainUnaryInterceptor(interceptor.unary),
+			grpc.ChainStreamInterceptor(interceptor.stream),
+		)
+	}
+	server := grpc.NewServer(options...)
+
+	productv1.RegisterProductServiceServer(server, products)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	healthServer.SetServingStatus(productv1.ProductService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	reflection.Register(server)
+
+	return &Server{
+		server: server,
+		health: healthServer,
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Start starts the gRPC server
+func (s *Server) Start() error {
+	addr := fmt.Sprintf("%s:%s", s.config.Host, s.config.Port)
+	s.logger.Info("Starting gRPC server",
+		slog.String("address", addr),
+	)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	return s.server.Serve(listener)
+}
+
+// Stop marks every service as not serving and waits for in-flight RPCs to finish
+func (s *Server) Stop() {
+	s.health.Shutdown()
+	s.server.GracefulStop()
+}
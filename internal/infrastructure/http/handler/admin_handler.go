@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/telemetry"
+)
+
+var errInvalidLogLevel = errors.New("level must be debug, info, warn or error")
+
+// LogLevelRequest changes the global log level or, with a component, the level of the component's logger.
+// An empty level for a component removes its own level, so it follows the global level again.
+type LogLevelRequest struct {
+	Component string `json:"component,omitempty"`
+	Level     string `json:"level"`
+}
+
+// LogLevelsResponse is the global log level and the level in force for each component logger
+type LogLevelsResponse struct {
+	Level      string               `json:"level"`
+	Components []*ComponentLogLevel `json:"components"`
+}
+
+// ComponentLogLevel is the level in force for a component logger; inherited levels follow the global level
+type ComponentLogLevel struct {
+	Component string `json:"component"`
+	Level     string `json:"level"`
+	Inherited bool   `json:"inherited"`
+}
+
+// AdminHandler handles HTTP requests for operating the API at runtime
+type AdminHandler struct {
+	levels *telemetry.LogLevels
+	logger *slog.Logger
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(levels *telemetry.LogLevels, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{
+		levels: levels,
+		logger: logger,
+	}
+}
+
+// GetLogLevels handles GET /admin/loglevel
+func (h *AdminHandler) GetLogLevels(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, h.logLevels())
+}
+
+// SetLogLevel handles PUT /admin/loglevel; the change takes effect immediately and is logged
+func (h *AdminHandler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req LogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to decode request body",
+			slog.String("error", err.Error()),
+		)
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var level *slog.Level
+	if req.Level != "" || req.Component == "" {
+		level = new(slog.Level)
+		if err := level.UnmarshalText([]byte(req.Level)); err != nil {
+			response.Error(w, http.StatusBadRequest, errInvalidLogLevel)
+			return
+		}
+	}
+
+	if req.Component == "" {
+		h.levels.SetGlobal(r.Context(), *level, "api")
+	} else if err := h.levels.SetComponent(r.Context(), req.Component, level, "api"); err != nil {
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, h.logLevels())
+}
+
+// logLevels describes the levels in force
+func (h *AdminHandler) logLevels() *LogLevelsResponse {
+	overrides := h.levels.Overrides()
+	components := make([]*ComponentLogLevel, 0, len(h.levels.Components()))
+	for _, component := range h.levels.Components() {
+		_, overridden := overrides[component]
+		components = append(components, &ComponentLogLevel{
+			Component: component,
+			Level:     levelName(h.levels.Level(component)),
+			Inherited: !overridden,
+		})
+	}
+	return &LogLevelsResponse{
+		Level:      levelName(h.levels.Global()),
+		Components: components,
+	}
+}
+
+// levelName formats a level the way it is configured, e.g. "warn"
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
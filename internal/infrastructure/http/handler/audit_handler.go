@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+)
+
+// defaultAuditLimit is the number of audit entries returned when ?limit is not set
+const defaultAuditLimit = 100
+
+var (
+	errInvalidSince = errors.New("since must be an RFC 3339 timestamp")
+	errInvalidLimit = errors.New("limit must be an integer")
+)
+
+// AuditHandler handles HTTP requests for the audit trail of products
+type AuditHandler struct {
+	service service.AuditUseCases
+	logger  *slog.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(service service.AuditUseCases, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetProductHistory handles GET /products/{id}/history
+func (h *AuditHandler) GetProductHistory(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.service.GetProductHistory(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			response.Error(w, http.StatusNotFound, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, entries)
+}
+
+// ListEntries handles GET /audit; ?since= (RFC 3339) skips older entries and ?limit= caps the result
+func (h *AuditHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var since time.Time
+	if value := query.Get("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			response.Error(w, http.StatusBadRequest, errInvalidSince)
+			return
+		}
+	}
+
+	limit := defaultAuditLimit
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			response.Error(w, http.StatusBadRequest, errInvalidLimit)
+			return
+		}
+	}
+
+	entries, err := h.service.ListEntries(r.Context(), since, limit)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidAuditLimit:
+			response.Error(w, http.StatusBadRequest, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, entries)
+}
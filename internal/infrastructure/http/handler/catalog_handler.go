@@ -0,0 +1,370 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+)
+
+var (
+	errInvalidExportFormat = errors.New("format must be csv or ndjson")
+	errInvalidImportFormat = errors.New("format must be csv or ndjson, given by ?format or a text/csv or application/x-ndjson Content-Type")
+	errImportTooLarge      = errors.New("import exceeds the maximum number of rows")
+)
+
+// csvColumns are the columns of a CSV export; imports read name, description, price, category_ids and variants.
+// variants holds the product's variants as a JSON array, in the form they are created with, and is empty without any.
+var csvColumns = []string{"id", "name", "description", "price", "category_ids", "created_at", "updated_at", "variants"}
+
+// exportFlushEvery is the number of exported products written between flushes
+const exportFlushEvery = 100
+
+// maxImportLine bounds the length of one NDJSON import line
+const maxImportLine = 1 << 20
+
+// CatalogHandler handles HTTP requests importing and exporting the product catalogue
+type CatalogHandler struct {
+	products service.ProductUseCases
+	imports  service.ImportUseCases
+	maxRows  int
+	logger   *slog.Logger
+}
+
+// NewCatalogHandler creates a new catalog handler; imports are limited to maxRows rows
+func NewCatalogHandler(products service.ProductUseCases, imports service.ImportUseCases, maxRows int, logger *slog.Logger) *CatalogHandler {
+	return &CatalogHandler{
+		products: products,
+		imports:  imports,
+		maxRows:  maxRows,
+		logger:   logger,
+	}
+}
+
+// ExportProducts handles GET /products/export.
+// ?format=csv (default) or ?format=ndjson; products are streamed as they are read from the repository.
+func (h *CatalogHandler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rc := http.NewResponseController(w)
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = dto.FormatCSV
+	}
+
+	var contentType string
+	var start func() error
+	var write func(*dto.ProductResponse) error
+	var flush func() error
+	switch format {
+	case dto.FormatCSV:
+		writer := csv.NewWriter(w)
+		contentType = "text/csv"
+		start = func() error { return writer.Write(csvColumns) }
+		write = func(p *dto.ProductResponse) error {
+			variants, err := csvVariants(p.Variants)
+			if err != nil {
+				return err
+			}
+			return writer.Write([]string{
+				p.ID,
+				p.Name,
+				p.Description,
+				strconv.FormatFloat(p.Price, 'f', -1, 64),
+				strings.Join(p.CategoryIDs, ";"),
+				p.CreatedAt.Format(time.RFC3339Nano),
+				p.UpdatedAt.Format(time.RFC3339Nano),
+				variants,
+			})
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case dto.FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		contentType = "application/x-ndjson"
+		start = func() error { return nil }
+		write = func(p *dto.ProductResponse) error { return encoder.Encode(p) }
+		flush = func() error { return nil }
+	default:
+		response.Error(w, http.StatusBadRequest, errInvalidExportFormat)
+		return
+	}
+
+	// The status is only sent with the first product, so errors before it still get a proper response
+	started := false
+	begin := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"products.%s\"", format))
+		w.WriteHeader(http.StatusOK)
+		return start()
+	}
+
+	exported := 0
+	err := h.products.ExportProducts(ctx, func(p *dto.ProductResponse) error {
+		if !started {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+		if err := write(p); err != nil {
+			return err
+		}
+		exported++
+		if exported%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			_ = rc.Flush()
+		}
+		return nil
+	})
+	if err == nil && !started {
+		err = begin()
+	}
+	if err == nil {
+		err = flush()
+	}
+
+	if err != nil {
+		if !started {
+			if err == auth.ErrForbidden {
+				response.Error(w, http.StatusForbidden, err)
+			} else {
+				response.Error(w, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		h.logger.ErrorContext(ctx, "Product export interrupted",
+			slog.Int("exported", exported),
+			slog.String("error", err.Error()),
+		)
+		// Abort the response so the client sees a truncated download rather than a complete one
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// ImportProducts handles POST /products/import.
+// The body is CSV with a header row (name and price required, description, category_ids and variants
+// optional, category IDs separated by ";" and variants as a JSON array) or NDJSON with one product per
+// line. Rows that fail to parse or validate are reported with their line number; the others are created. Imports small enough to run
+// within the request get 200 with the report, larger ones get 202 and a job to poll.
+func (h *CatalogHandler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		switch mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType {
+		case "text/csv":
+			format = dto.FormatCSV
+		case "application/x-ndjson":
+			format = dto.FormatNDJSON
+		}
+	}
+
+	var rows []*dto.ImportRow
+	var err error
+	switch format {
+	case dto.FormatCSV:
+		rows, err = parseCSVImport(r.Body, h.maxRows)
+	case dto.FormatNDJSON:
+		rows, err = parseNDJSONImport(r.Body, h.maxRows)
+	default:
+		err = errInvalidImportFormat
+	}
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to parse import body",
+			slog.String("format", format),
+			slog.String("error", err.Error()),
+		)
+		if err == errImportTooLarge {
+			response.Error(w, http.StatusRequestEntityTooLarge, err)
+		} else {
+			response.Error(w, http.StatusBadRequest, err)
+		}
+		return
+	}
+
+	job, err := h.imports.ImportProducts(ctx, format, rows)
+	if err != nil {
+		switch err {
+		case domain.ErrEmptyBatch:
+			response.Error(w, http.StatusBadRequest, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		case service.ErrImportQueueFull:
+			w.Header().Set("Retry-After", "30")
+			response.Error(w, http.StatusServiceUnavailable, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if job.Status == dto.ImportJobPending {
+		w.Header().Set("Location", "/products/import/jobs/"+job.ID)
+		response.JSON(w, http.StatusAccepted, job)
+		return
+	}
+	response.JSON(w, http.StatusOK, job)
+}
+
+// GetImportJob handles GET /products/import/jobs/{jobID}
+func (h *CatalogHandler) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "jobID")
+
+	job, err := h.imports.GetImportJob(r.Context(), id)
+	if err != nil {
+		switch err {
+		case service.ErrImportJobNotFound:
+			response.Error(w, http.StatusNotFound, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, job)
+}
+
+// parseCSVImport reads the rows of a CSV import, stopping as soon as it exceeds maxRows.
+// Unknown columns are ignored, so an export can be imported as is.
+func parseCSVImport(body io.Reader, maxRows int) ([]*dto.ImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []*dto.ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if len(rows) == maxRows {
+			return nil, errImportTooLarge
+		}
+
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount):
+			rows = append(rows, &dto.ImportRow{Line: parseErr.StartLine, Error: "wrong number of fields"})
+			continue
+		case err != nil:
+			// Anything else, such as a broken quote, leaves the reader unable to find the next row
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		price, err := strconv.ParseFloat(field(record, "price"), 64)
+		if err != nil {
+			rows = append(rows, &dto.ImportRow{Line: line, Error: "price must be a number"})
+			continue
+		}
+
+		var categoryIDs []string
+		for _, id := range strings.Split(field(record, "category_ids"), ";") {
+			if id = strings.TrimSpace(id); id != "" {
+				categoryIDs = append(categoryIDs, id)
+			}
+		}
+
+		var variants []*dto.VariantRequest
+		if raw := field(record, "variants"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &variants); err != nil {
+				rows = append(rows, &dto.ImportRow{Line: line, Error: "variants must be a JSON array of variants: " + err.Error()})
+				continue
+			}
+		}
+
+		rows = append(rows, &dto.ImportRow{Line: line, Product: &dto.CreateProductRequest{
+			Name:        field(record, "name"),
+			Description: field(record, "description"),
+			Price:       price,
+			CategoryIDs: categoryIDs,
+			Variants:    variants,
+		}})
+	}
+}
+
+// csvVariants encodes variants for the variants column as the JSON array they are created from
+func csvVariants(variants []*dto.VariantResponse) (string, error) {
+	if len(variants) == 0 {
+		return "", nil
+	}
+	reqs := make([]*dto.VariantRequest, len(variants))
+	for i, v := range variants {
+		reqs[i] = &dto.VariantRequest{SKU: v.SKU, Attributes: v.Attributes, PriceOverride: v.PriceOverride, Stock: v.Stock}
+	}
+	encoded, err := json.Marshal(reqs)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// parseNDJSONImport reads the rows of an NDJSON import, stopping as soon as it exceeds maxRows.
+// Blank lines are skipped and unknown fields are ignored, so an export can be imported as is.
+func parseNDJSONImport(body io.Reader, maxRows int) ([]*dto.ImportRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+
+	var rows []*dto.ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(rows) == maxRows {
+			return nil, errImportTooLarge
+		}
+
+		var req dto.CreateProductRequest
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			rows = append(rows, &dto.ImportRow{Line: line, Error: "invalid JSON: " + err.Error()})
+			continue
+		}
+		rows = append(rows, &dto.ImportRow{Line: line, Product: &req})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+)
+
+// CategoryHandler handles HTTP requests for categories
+type CategoryHandler struct {
+	service service.CategoryUseCases
+	logger  *slog.Logger
+}
+
+// NewCategoryHandler creates a new category handler
+func NewCategoryHandler(service service.CategoryUseCases, logger *slog.Logger) *CategoryHandler {
+	return &CategoryHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateCategory handles POST /categories
+func (h *CategoryHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to decode request body",
+			slog.String("error", err.Error()),
+		)
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	category, err := h.service.CreateCategory(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, category)
+}
+
+// GetCategory handles GET /categories/{id}
+func (h *CategoryHandler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	category, err := h.service.GetCategoryByID(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, category)
+}
+
+// ListCategories handles GET /categories
+func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.service.ListCategories(r.Context())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, categories)
+}
+
+// UpdateCategory handles PUT /categories/{id}
+func (h *CategoryHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req dto.UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to decode request body",
+			slog.String("error", err.Error()),
+		)
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	category, err := h.service.UpdateCategory(r.Context(), id, &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, category)
+}
+
+// DeleteCategory handles DELETE /categories/{id}
+func (h *CategoryHandler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := h.service.DeleteCategory(r.Context(), id); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCategoryProducts handles GET /categories/{id}/products
+func (h *CategoryHandler) ListCategoryProducts(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	products, err := h.service.ListCategoryProducts(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, products)
+}
+
+// writeError maps category domain errors to HTTP status codes
+func (h *CategoryHandler) writeError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrCategoryNotFound:
+		response.Error(w, http.StatusNotFound, err)
+	case domain.ErrInvalidCategoryName, domain.ErrInvalidCategoryParent, domain.ErrUnknownParentCategory:
+		response.Error(w, http.StatusBadRequest, err)
+	case domain.ErrCategoryHasChildren, domain.ErrCategoryInUse:
+		response.Error(w, http.StatusConflict, err)
+	default:
+		response.Error(w, http.StatusInternalServerError, err)
+	}
+}
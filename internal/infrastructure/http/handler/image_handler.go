@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+)
+
+// imageFormField is the multipart form field carrying the uploaded images, repeated once per image
+const imageFormField = "images"
+
+// maxImageFormMemory is the part of a multipart upload kept in memory; the rest is spooled to disk
+const maxImageFormMemory = 8 << 20
+
+var (
+	errUploadTooLarge = errors.New("upload exceeds the maximum size")
+	errNotMultipart   = errors.New("body must be multipart/form-data with the images in the \"images\" field")
+)
+
+// ImageHandler handles HTTP requests for product images
+type ImageHandler struct {
+	service       service.ImageUseCases
+	maxUploadSize int64
+	logger        *slog.Logger
+}
+
+// NewImageHandler creates a new image handler; upload bodies are limited to maxUploadSize bytes
+func NewImageHandler(service service.ImageUseCases, maxUploadSize int64, logger *slog.Logger) *ImageHandler {
+	return &ImageHandler{
+		service:       service,
+		maxUploadSize: maxUploadSize,
+		logger:        logger,
+	}
+}
+
+// ReplaceImages handles PUT /products/{id}/images, a multipart/form-data upload replacing every image of the product
+func (h *ImageHandler) ReplaceImages(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadSize)
+	if err := r.ParseMultipartForm(maxImageFormMemory); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to parse image upload",
+			slog.String("error", err.Error()),
+		)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(w, http.StatusRequestEntityTooLarge, errUploadTooLarge)
+		} else {
+			response.Error(w, http.StatusBadRequest, errNotMultipart)
+		}
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	files := r.MultipartForm.File[imageFormField]
+	uploads := make([]*dto.ImageUpload, 0, len(files))
+	for _, header := range files {
+		file, err := header.Open()
+		if err != nil {
+			response.Error(w, http.StatusInternalServerError, err)
+			return
+		}
+		defer file.Close()
+		uploads = append(uploads, &dto.ImageUpload{Filename: header.Filename, Content: file})
+	}
+
+	images, err := h.service.ReplaceImages(r.Context(), chi.URLParam(r, "id"), uploads)
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			response.Error(w, http.StatusNotFound, err)
+		case domain.ErrNoImages, domain.ErrTooManyImages:
+			response.Error(w, http.StatusBadRequest, err)
+		case domain.ErrImageTooLarge:
+			response.Error(w, http.StatusRequestEntityTooLarge, err)
+		case domain.ErrUnsupportedImageType:
+			response.Error(w, http.StatusUnsupportedMediaType, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, images)
+}
+
+// ListImages handles GET /products/{id}/images
+func (h *ImageHandler) ListImages(w http.ResponseWriter, r *http.Request) {
+	images, err := h.service.ListImages(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			response.Error(w, http.StatusNotFound, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, images)
+}
+
+// GetImage handles GET /products/{id}/images/{imageID}
+func (h *ImageHandler) GetImage(w http.ResponseWriter, r *http.Request) {
+	h.serveImage(w, r, false)
+}
+
+// GetThumbnail handles GET /products/{id}/images/{imageID}/thumbnail
+func (h *ImageHandler) GetThumbnail(w http.ResponseWriter, r *http.Request) {
+	h.serveImage(w, r, true)
+}
+
+// serveImage streams the content of an image or its thumbnail
+func (h *ImageHandler) serveImage(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	content, err := h.service.GetImageContent(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "imageID"), thumbnail)
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound, domain.ErrImageNotFound, domain.ErrThumbnailNotReady:
+			response.Error(w, http.StatusNotFound, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	defer content.Content.Close()
+
+	w.Header().Set("Content-Type", content.ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content.Content); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to send image",
+			slog.String("error", err.Error()),
+		)
+	}
+}
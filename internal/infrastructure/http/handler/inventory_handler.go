@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+)
+
+// InventoryHandler handles HTTP requests for stock and reservations
+type InventoryHandler struct {
+	service service.InventoryUseCases
+	logger  *slog.Logger
+}
+
+// NewInventoryHandler creates a new inventory handler
+func NewInventoryHandler(service service.InventoryUseCases, logger *slog.Logger) *InventoryHandler {
+	return &InventoryHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetStock handles GET /products/{id}/stock
+func (h *InventoryHandler) GetStock(w http.ResponseWriter, r *http.Request) {
+	stock, err := h.service.GetStock(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, stock)
+}
+
+// SetStock handles PUT /products/{id}/stock
+func (h *InventoryHandler) SetStock(w http.ResponseWriter, r *http.Request) {
+	var req dto.SetStockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to decode request body",
+			slog.String("error", err.Error()),
+		)
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	stock, err := h.service.SetStock(r.Context(), chi.URLParam(r, "id"), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, stock)
+}
+
+// CreateReservation handles POST /products/{id}/reservations
+func (h *InventoryHandler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to decode request body",
+			slog.String("error", err.Error()),
+		)
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	reservation, err := h.service.ReserveStock(r.Context(), chi.URLParam(r, "id"), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, reservation)
+}
+
+// GetReservation handles GET /products/{id}/reservations/{reservationID}
+func (h *InventoryHandler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, err := h.service.GetReservation(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "reservationID"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, reservation)
+}
+
+// ConfirmReservation handles POST /products/{id}/reservations/{reservationID}/confirm
+func (h *InventoryHandler) ConfirmReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, err := h.service.ConfirmReservation(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "reservationID"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, reservation)
+}
+
+// ReleaseReservation handles POST /products/{id}/reservations/{reservationID}/release
+func (h *InventoryHandler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	reservation, err := h.service.ReleaseReservation(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "reservationID"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, reservation)
+}
+
+// writeError maps inventory domain errors to HTTP status codes
+func (h *InventoryHandler) writeError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrProductNotFound, domain.ErrReservationNotFound:
+		response.Error(w, http.StatusNotFound, err)
+	case domain.ErrInvalidQuantity, domain.ErrInvalidStockLevel:
+		response.Error(w, http.StatusBadRequest, err)
+	case domain.ErrInsufficientStock, domain.ErrReservationNotPending:
+		response.Error(w, http.StatusConflict, err)
+	default:
+		response.Error(w, http.StatusInternalServerError, err)
+	}
+}
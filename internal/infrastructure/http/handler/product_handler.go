@@ -2,27 +2,42 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"mime"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
 	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
 	"github.com/mrops-br/testing-otlp-api/internal/app/service"
 	"github.com/mrops-br/testing-otlp-api/internal/domain"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
 )
 
+var (
+	errInvalidBatchMode = errors.New("mode must be atomic or best_effort")
+	errBatchNotArray    = errors.New("batch body must be a JSON array or NDJSON")
+	errInvalidInclude   = errors.New("include_deleted must be true or false")
+	errInvalidAsOf      = errors.New("as_of must be an RFC 3339 timestamp")
+)
+
 // ProductHandler handles HTTP requests for products
 type ProductHandler struct {
-	service *service.ProductService
-	logger  *slog.Logger
+	service      service.ProductUseCases
+	maxBatchSize int
+	logger       *slog.Logger
 }
 
-// NewProductHandler creates a new product handler
-func NewProductHandler(service *service.ProductService, logger *slog.Logger) *ProductHandler {
+// NewProductHandler creates a new product handler; batches are limited to maxBatchSize products
+func NewProductHandler(service service.ProductUseCases, maxBatchSize int, logger *slog.Logger) *ProductHandler {
 	return &ProductHandler{
-		service: service,
-		logger:  logger,
+		service:      service,
+		maxBatchSize: maxBatchSize,
+		logger:       logger,
 	}
 }
 
@@ -40,8 +55,13 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	product, err := h.service.CreateProduct(r.Context(), &req)
 	if err != nil {
 		switch err {
-		case domain.ErrInvalidProductName, domain.ErrInvalidProductPrice:
+		case domain.ErrInvalidProductName, domain.ErrInvalidProductPrice, domain.ErrUnknownCategory,
+			domain.ErrInvalidSKU, domain.ErrInvalidVariantPrice, domain.ErrInvalidVariantStock:
 			response.Error(w, http.StatusBadRequest, err)
+		case domain.ErrDuplicateSKU:
+			response.Error(w, http.StatusConflict, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
 		default:
 			response.Error(w, http.StatusInternalServerError, err)
 		}
@@ -51,15 +71,113 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusCreated, product)
 }
 
-// GetProduct handles GET /products/{id}
+// CreateProducts handles POST /products:batch.
+// The body is a JSON array or, with Content-Type application/x-ndjson, one product per line.
+// ?mode=atomic (default) creates all products or none; ?mode=best_effort creates the valid ones.
+func (h *ProductHandler) CreateProducts(w http.ResponseWriter, r *http.Request) {
+	mode := dto.BatchMode(r.URL.Query().Get("mode"))
+	switch mode {
+	case "":
+		mode = dto.BatchAtomic
+	case dto.BatchAtomic, dto.BatchBestEffort:
+	default:
+		response.Error(w, http.StatusBadRequest, errInvalidBatchMode)
+		return
+	}
+
+	reqs, err := decodeBatch(r, h.maxBatchSize)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to decode batch request body",
+			slog.String("error", err.Error()),
+		)
+		if err == domain.ErrBatchTooLarge {
+			response.Error(w, http.StatusRequestEntityTooLarge, err)
+		} else {
+			response.Error(w, http.StatusBadRequest, err)
+		}
+		return
+	}
+
+	result, err := h.service.CreateProducts(r.Context(), reqs, mode)
+	if err != nil {
+		switch err {
+		case domain.ErrEmptyBatch:
+			response.Error(w, http.StatusBadRequest, err)
+		case domain.ErrDuplicateSKU:
+			response.Error(w, http.StatusConflict, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	switch {
+	case result.Failed == 0:
+		response.JSON(w, http.StatusCreated, result)
+	case mode == dto.BatchAtomic:
+		response.JSON(w, http.StatusBadRequest, result)
+	default:
+		response.JSON(w, http.StatusMultiStatus, result)
+	}
+}
+
+// decodeBatch reads the products of a batch body, stopping as soon as it exceeds maxSize
+func decodeBatch(r *http.Request, maxSize int) ([]*dto.CreateProductRequest, error) {
+	decoder := json.NewDecoder(r.Body)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	ndjson := mediaType == "application/x-ndjson"
+	if !ndjson {
+		if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+			return nil, errBatchNotArray
+		}
+	}
+
+	var reqs []*dto.CreateProductRequest
+	for decoder.More() {
+		if len(reqs) == maxSize {
+			return nil, domain.ErrBatchTooLarge
+		}
+		var req dto.CreateProductRequest
+		if err := decoder.Decode(&req); err != nil {
+			return nil, fmt.Errorf("item %d: %w", len(reqs), err)
+		}
+		reqs = append(reqs, &req)
+	}
+
+	if !ndjson {
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return reqs, nil
+}
+
+// GetProduct handles GET /products/{id}; ?as_of= (RFC 3339) returns the product as it was at that time
 func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	product, err := h.service.GetProductByID(r.Context(), id)
+	var product *dto.ProductResponse
+	var err error
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		asOf, parseErr := time.Parse(time.RFC3339, value)
+		if parseErr != nil {
+			response.Error(w, http.StatusBadRequest, errInvalidAsOf)
+			return
+		}
+		product, err = h.service.GetProductAsOf(r.Context(), id, asOf)
+	} else {
+		product, err = h.service.GetProductByID(r.Context(), id)
+	}
 	if err != nil {
-		if err == domain.ErrProductNotFound {
+		switch err {
+		case domain.ErrProductNotFound:
 			response.Error(w, http.StatusNotFound, err)
-		} else {
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
 			response.Error(w, http.StatusInternalServerError, err)
 		}
 		return
@@ -68,13 +186,139 @@ func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, product)
 }
 
-// ListProducts handles GET /products
+// GetProductBySKU handles GET /skus/{sku}
+func (h *ProductHandler) GetProductBySKU(w http.ResponseWriter, r *http.Request) {
+	result, err := h.service.GetProductBySKU(r.Context(), chi.URLParam(r, "sku"))
+	if err != nil {
+		switch err {
+		case domain.ErrVariantNotFound:
+			response.Error(w, http.StatusNotFound, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, result)
+}
+
+// GetPriceHistory handles GET /products/{id}/prices
+func (h *ProductHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	prices, err := h.service.GetPriceHistory(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			response.Error(w, http.StatusNotFound, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, prices)
+}
+
+// ListProducts handles GET /products; ?include_deleted=true also lists the soft-deleted products
 func (h *ProductHandler) ListProducts(w http.ResponseWriter, r *http.Request) {
-	products, err := h.service.ListProducts(r.Context())
+	includeDeleted := false
+	if value := r.URL.Query().Get("include_deleted"); value != "" {
+		var err error
+		if includeDeleted, err = strconv.ParseBool(value); err != nil {
+			response.Error(w, http.StatusBadRequest, errInvalidInclude)
+			return
+		}
+	}
+
+	products, err := h.service.ListProducts(r.Context(), includeDeleted)
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, err)
+		if err == auth.ErrForbidden {
+			response.Error(w, http.StatusForbidden, err)
+		} else {
+			response.Error(w, http.StatusInternalServerError, err)
+		}
 		return
 	}
 
 	response.JSON(w, http.StatusOK, products)
 }
+
+// UpdateProduct handles PUT /products/{id}
+func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req dto.UpdateProductRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to decode request body",
+			slog.String("error", err.Error()),
+		)
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	product, err := h.service.UpdateProduct(r.Context(), id, &req)
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			response.Error(w, http.StatusNotFound, err)
+		case domain.ErrInvalidProductName, domain.ErrInvalidProductPrice, domain.ErrUnknownCategory,
+			domain.ErrInvalidSKU, domain.ErrInvalidVariantPrice, domain.ErrInvalidVariantStock:
+			response.Error(w, http.StatusBadRequest, err)
+		case domain.ErrDuplicateSKU, domain.ErrProductConflict:
+			response.Error(w, http.StatusConflict, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, product)
+}
+
+// DeleteProduct handles DELETE /products/{id}; the product is soft-deleted and can be restored until it is purged
+func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if err := h.service.DeleteProduct(r.Context(), id); err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			response.Error(w, http.StatusNotFound, err)
+		case domain.ErrProductConflict:
+			response.Error(w, http.StatusConflict, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreProduct handles POST /products/{id}:restore
+func (h *ProductHandler) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	product, err := h.service.RestoreProduct(r.Context(), id)
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			response.Error(w, http.StatusNotFound, err)
+		case domain.ErrProductConflict:
+			response.Error(w, http.StatusConflict, err)
+		case auth.ErrForbidden:
+			response.Error(w, http.StatusForbidden, err)
+		default:
+			response.Error(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	response.JSON(w, http.StatusOK, product)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/stream"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StreamHandler serves product changes as Server-Sent Events
+type StreamHandler struct {
+	broker    *stream.Broker
+	heartbeat time.Duration
+	logger    *slog.Logger
+}
+
+// NewStreamHandler creates a new stream handler
+func NewStreamHandler(broker *stream.Broker, heartbeat time.Duration, logger *slog.Logger) *StreamHandler {
+	return &StreamHandler{
+		broker:    broker,
+		heartbeat: heartbeat,
+		logger:    logger,
+	}
+}
+
+// StreamProducts handles GET /products/stream
+func (h *StreamHandler) StreamProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rc := http.NewResponseController(w)
+
+	// Resume after the last event the client saw, if it tells us
+	var lastID uint64
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		if id, err := strconv.ParseUint(header, 10, 64); err == nil {
+			lastID = id
+		}
+	}
+
+	sub, backlog, resync := h.broker.Subscribe(ctx, lastID)
+	defer h.broker.Unsubscribe(ctx, sub)
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		attribute.Int64("stream.last_event_id", int64(lastID)),
+		attribute.Int("stream.backlog", len(backlog)),
+		attribute.Bool("stream.resync", resync),
+	)
+
+	h.logger.InfoContext(ctx, "Product stream opened",
+		slog.Uint64("last_event_id", lastID),
+		slog.Int("backlog", len(backlog)),
+	)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Tell the client it missed events that are no longer buffered and should refetch
+	if resync {
+		_, _ = fmt.Fprint(w, "event: resync\ndata: {}\n\n")
+	}
+	for _, message := range backlog {
+		writeMessage(w, message)
+	}
+	if err := rc.Flush(); err != nil {
+		h.logger.ErrorContext(ctx, "Streaming not supported by response writer",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	sent := len(backlog)
+	for {
+		select {
+		case <-ctx.Done():
+			h.logger.InfoContext(ctx, "Product stream closed by client",
+				slog.Int("events_sent", sent),
+			)
+			return
+		case message, ok := <-sub.C:
+			if !ok && h.broker.Closed() {
+				h.logger.InfoContext(ctx, "Product stream closed, server shutting down",
+					slog.Int("events_sent", sent),
+				)
+				return
+			}
+			if !ok {
+				span.AddEvent("stream.subscriber_too_slow")
+				h.logger.WarnContext(ctx, "Product stream closed, subscriber too slow",
+					slog.Int("events_sent", sent),
+				)
+				return
+			}
+			writeMessage(w, message)
+			sent++
+		case <-heartbeat.C:
+			_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeMessage writes a message in the text/event-stream format
+func writeMessage(w http.ResponseWriter, message stream.Message) {
+	_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", message.ID, message.Event, message.Data)
+}
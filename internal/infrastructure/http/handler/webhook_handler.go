@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions and deliveries
+type WebhookHandler struct {
+	service service.WebhookUseCases
+	logger  *slog.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(service service.WebhookUseCases, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreateWebhook handles POST /webhooks
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to decode request body",
+			slog.String("error", err.Error()),
+		)
+		response.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	webhook, err := h.service.CreateSubscription(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, webhook)
+}
+
+// GetWebhook handles GET /webhooks/{id}
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, err := h.service.GetSubscription(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, webhook)
+}
+
+// ListWebhooks handles GET /webhooks
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.ListSubscriptions(r.Context())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, webhooks)
+}
+
+// DeleteWebhook handles DELETE /webhooks/{id}
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteSubscription(r.Context(), chi.URLParam(r, "id")); err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /webhooks/{id}/deliveries
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.service.ListDeliveries(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, deliveries)
+}
+
+// ListDeadLetters handles GET /webhooks/dead-letters
+func (h *WebhookHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.service.ListDeadLetters(r.Context())
+	if err != nil {
+		response.Error(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, deliveries)
+}
+
+// RetryDelivery handles POST /webhooks/deliveries/{deliveryID}/retry
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.service.RetryDelivery(r.Context(), chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, delivery)
+}
+
+// writeError maps webhook domain errors to HTTP status codes
+func (h *WebhookHandler) writeError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrWebhookNotFound, domain.ErrWebhookDeliveryNotFound:
+		response.Error(w, http.StatusNotFound, err)
+	case domain.ErrInvalidWebhookURL, domain.ErrPrivateWebhookURL, domain.ErrInvalidEventType:
+		response.Error(w, http.StatusBadRequest, err)
+	case domain.ErrDeliveryNotDead:
+		response.Error(w, http.StatusConflict, err)
+	default:
+		response.Error(w, http.StatusInternalServerError, err)
+	}
+}
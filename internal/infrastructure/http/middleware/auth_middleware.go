@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	infraauth "github.com/mrops-br/testing-otlp-api/internal/infrastructure/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+)
+
+// Authentication requires every request to carry an X-API-Key header or an
+// Authorization: Bearer token, and stores the authenticated principal in the request context.
+// Requests to exemptPaths (e.g. /health, /metrics) stay anonymous.
+func Authentication(authenticator infraauth.Authenticator, exemptPaths []string, logger *slog.Logger) func(next http.Handler) http.Handler {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			principal, err := authenticator.Authenticate(ctx, credentialsFromRequest(r))
+			if err != nil {
+				logger.WarnContext(ctx, "Request authentication failed",
+					slog.String("error", err.Error()),
+				)
+				w.Header().Set("WWW-Authenticate", `Bearer realm="products-api"`)
+				response.Error(w, http.StatusUnauthorized, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(ctx, principal)))
+		})
+	}
+}
+
+// credentialsFromRequest extracts the API key and bearer token from the request headers
+func credentialsFromRequest(r *http.Request) infraauth.Credentials {
+	credentials := infraauth.Credentials{APIKey: r.Header.Get("X-API-Key")}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		credentials.BearerToken = strings.TrimSpace(token)
+	}
+
+	return credentials
+}
+
+// Authorization enforces the role the authorization policy requires for the route.
+// It must run after Authentication; requests without a principal are exempt routes and pass through.
+func Authorization(authorizer *auth.Authorizer) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := authorizer.AuthorizeRoute(r.Context(), r.Method, r.URL.Path); err != nil {
+				response.Error(w, http.StatusForbidden, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
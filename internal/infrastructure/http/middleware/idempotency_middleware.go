@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/idempotency"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header
+const maxIdempotencyKeyLength = 255
+
+var (
+	errInvalidIdempotencyKey  = errors.New("idempotency key must be at most 255 characters")
+	errIdempotentBodyTooLarge = errors.New("request body exceeds the maximum size for idempotent requests")
+)
+
+// Idempotency makes requests carrying an Idempotency-Key header safe to retry.
+// The first response (except 5xx) is stored with a fingerprint of the request and replayed for
+// duplicates; reusing the key for a different request gets 422 and a duplicate sent while the
+// first request is still running gets 409. Keys are scoped to the authenticated principal.
+// Bodies are buffered to be fingerprinted, so those over maxBodySize bytes get 413.
+func Idempotency(store idempotency.Store, maxBodySize int64, meter metric.Meter, logger *slog.Logger) func(next http.Handler) http.Handler {
+	// Initialize metrics
+	requests, _ := meter.Int64Counter(
+		"idempotency.requests",
+		metric.WithDescription("Total number of requests carrying an Idempotency-Key, by outcome"),
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			span := trace.SpanFromContext(ctx)
+			record := func(result string) {
+				span.SetAttributes(attribute.String("idempotency.result", result))
+				requests.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+			}
+
+			if len(key) > maxIdempotencyKeyLength {
+				response.Error(w, http.StatusBadRequest, errInvalidIdempotencyKey)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					response.Error(w, http.StatusRequestEntityTooLarge, errIdempotentBodyTooLarge)
+					return
+				}
+				response.Error(w, http.StatusBadRequest, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if principal, ok := auth.PrincipalFromContext(ctx); ok {
+				key = principal.ID + ":" + key
+			}
+			digest := sha256.Sum256(append([]byte(r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery+"\n"), body...))
+			fingerprint := hex.EncodeToString(digest[:])
+
+			stored, err := store.Begin(ctx, key, fingerprint)
+			switch {
+			case errors.Is(err, idempotency.ErrFingerprintMismatch):
+				record("mismatch")
+				response.Error(w, http.StatusUnprocessableEntity, err)
+				return
+			case errors.Is(err, idempotency.ErrInFlight):
+				record("in_flight")
+				response.Error(w, http.StatusConflict, err)
+				return
+			case err != nil:
+				logger.ErrorContext(ctx, "Failed to check idempotency key",
+					slog.String("error", err.Error()),
+				)
+				response.Error(w, http.StatusInternalServerError, err)
+				return
+			case stored != nil:
+				record("replayed")
+				span.SetAttributes(attribute.Bool("idempotency.replayed", true))
+				logger.InfoContext(ctx, "Replaying stored response for idempotency key",
+					slog.Int("status", stored.Status),
+				)
+				for name, values := range stored.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(stored.Status)
+				_, _ = w.Write(stored.Body)
+				return
+			}
+
+			record("new")
+			rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				// Release the key if the handler panicked so the request can be retried
+				if !completed {
+					_ = store.Release(ctx, key)
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+
+			completed = true
+			if rw.status >= http.StatusInternalServerError {
+				_ = store.Release(ctx, key)
+				return
+			}
+
+			header := http.Header{}
+			if contentType := rw.Header().Get("Content-Type"); contentType != "" {
+				header.Set("Content-Type", contentType)
+			}
+			if err := store.Complete(ctx, key, &idempotency.Response{
+				Status: rw.status,
+				Header: header,
+				Body:   rw.body.Bytes(),
+			}); err != nil {
+				logger.ErrorContext(ctx, "Failed to store idempotent response",
+					slog.String("error", err.Error()),
+				)
+			}
+		})
+	}
+}
+
+// recordingWriter records the status code and body of a response
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
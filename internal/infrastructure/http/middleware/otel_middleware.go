@@ -39,7 +39,8 @@ func TracingMiddleware(tracer trace.Tracer) func(next http.Handler) http.Handler
 
 // ActiveRequestsMiddleware tracks active HTTP requests using OpenTelemetry metrics
 // This middleware should be registered AFTER routing middleware to have access to route patterns
-func ActiveRequestsMiddleware(meter metric.Meter) func(next http.Handler) http.Handler {
+// Requests to excludedPaths (e.g. long-lived streams) are not counted so they don't stay active forever
+func ActiveRequestsMiddleware(meter metric.Meter, excludedPaths ...string) func(next http.Handler) http.Handler {
 	// Create an UpDownCounter for tracking active requests
 	activeRequests, err := meter.Int64UpDownCounter(
 		"http.server.active_requests",
@@ -53,8 +54,18 @@ func ActiveRequestsMiddleware(meter metric.Meter) func(next http.Handler) http.H
 		}
 	}
 
+	excluded := make(map[string]bool, len(excludedPaths))
+	for _, path := range excludedPaths {
+		excluded[path] = true
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if excluded[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Create a custom response writer to extract route after handler processes request
 			wrapper := &routeAwareWriter{
 				ResponseWriter: w,
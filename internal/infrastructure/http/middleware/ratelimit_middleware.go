@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	infraauth "github.com/mrops-br/testing-otlp-api/internal/infrastructure/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/ratelimit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errRateLimited = errors.New("rate limit exceeded")
+
+// RateLimitKey returns the function identifying the client of a request:
+// "ip" (client address), "api_key" (the principal of the X-API-Key header, client address
+// when the key is not one of apiKeys) or "header:<name>" (the header value, client address without one).
+// Unknown keys fall back to the address so that sending a new key per request can't buy a fresh bucket.
+func RateLimitKey(mode string, apiKeys *infraauth.APIKeyAuthenticator) (func(r *http.Request) string, error) {
+	switch {
+	case mode == "ip":
+		return clientIP, nil
+	case mode == "api_key":
+		return func(r *http.Request) string {
+			if key := r.Header.Get("X-API-Key"); key != "" && apiKeys != nil {
+				if principal, ok := apiKeys.Principal(key); ok {
+					return "principal:" + principal
+				}
+			}
+			return clientIP(r)
+		}, nil
+	case strings.HasPrefix(mode, "header:") && len(mode) > len("header:"):
+		name := strings.TrimPrefix(mode, "header:")
+		return func(r *http.Request) string {
+			if value := r.Header.Get(name); value != "" {
+				return "header:" + value
+			}
+			return clientIP(r)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit key %q, expected ip, api_key or header:<name>", mode)
+	}
+}
+
+// clientIP returns the address of the client without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// RateLimit limits each client to the token bucket rule of the route it calls, under the rules in force.
+// The route pattern is resolved up front, since top-level middleware runs before chi routing.
+// Responses carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers;
+// limited requests get 429 with Retry-After. Requests to exemptPaths are not limited.
+func RateLimit(
+	limiter ratelimit.Limiter,
+	rules *ratelimit.LiveRules,
+	key func(r *http.Request) string,
+	exemptPaths []string,
+	meter metric.Meter,
+	logger *slog.Logger,
+) func(next http.Handler) http.Handler {
+	// Initialize metrics
+	requests, _ := meter.Int64Counter(
+		"ratelimit.requests",
+		metric.WithDescription("Total number of requests checked by the rate limiter"),
+	)
+
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			route := routePattern(r)
+
+			rule := rules.For(r.Method, route)
+			decision, err := limiter.Allow(ctx, r.Method+" "+route+"|"+key(r), rule)
+			if err != nil {
+				// Fail open: an unavailable limiter must not take the API down
+				logger.ErrorContext(ctx, "Rate limiter failed, allowing request",
+					slog.String("error", err.Error()),
+				)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.ResetAfter)))
+
+			result := "allowed"
+			if !decision.Allowed {
+				result = "limited"
+			}
+			requests.Add(ctx, 1, metric.WithAttributes(
+				attribute.String("http.route", route),
+				attribute.String("result", result),
+			))
+
+			if !decision.Allowed {
+				trace.SpanFromContext(ctx).AddEvent("ratelimit.limited", trace.WithAttributes(
+					attribute.Float64("ratelimit.rate", rule.Rate),
+					attribute.Int("ratelimit.burst", rule.Burst),
+					attribute.String("ratelimit.retry_after", decision.RetryAfter.String()),
+				))
+				logger.WarnContext(ctx, "Request rate limited",
+					slog.String("route", route),
+					slog.Duration("retry_after", decision.RetryAfter),
+				)
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+				response.Error(w, http.StatusTooManyRequests, errRateLimited)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// unmatchedRoute labels requests matching no route, so that scanning for paths
+// adds neither metric series nor limiter buckets
+const unmatchedRoute = "unmatched"
+
+// routePattern returns the chi route pattern the request will match, or unmatchedRoute when none matches
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.Routes != nil {
+		if match := chi.NewRouteContext(); rctx.Routes.Match(match, r.Method, r.URL.Path) {
+			return match.RoutePattern()
+		}
+	}
+	return unmatchedRoute
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+)
+
+// RequestIDContext passes the request ID assigned by chimiddleware.RequestID on to the services,
+// which record it in the audit trail. It must run after chimiddleware.RequestID.
+func RequestIDContext() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := chimiddleware.GetReqID(r.Context())
+			if id == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(service.WithRequestID(r.Context(), id)))
+		})
+	}
+}
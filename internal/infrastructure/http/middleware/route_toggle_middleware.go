@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errRouteDisabled = errors.New("route is disabled")
+
+// RouteToggles holds the routes that are switched off; the set can be swapped while requests are served
+type RouteToggles struct {
+	disabled atomic.Pointer[map[string]bool]
+}
+
+// NewRouteToggles creates route toggles with the given "METHOD /route" chi patterns switched off
+func NewRouteToggles(disabled []string) *RouteToggles {
+	t := &RouteToggles{}
+	t.Swap(disabled)
+	return t
+}
+
+// Swap replaces the routes that are switched off
+func (t *RouteToggles) Swap(disabled []string) {
+	routes := make(map[string]bool, len(disabled))
+	for _, route := range disabled {
+		method, pattern, _ := strings.Cut(route, " ")
+		routes[strings.ToUpper(method)+" "+strings.TrimSpace(pattern)] = true
+	}
+	t.disabled.Store(&routes)
+}
+
+// Disabled reports whether the route pattern is switched off for the method
+func (t *RouteToggles) Disabled(method, route string) bool {
+	return (*t.disabled.Load())[method+" "+route]
+}
+
+// RouteToggle answers requests to switched off routes with 503 Service Unavailable before any other work is done
+func RouteToggle(toggles *RouteToggles, meter metric.Meter, logger *slog.Logger) func(next http.Handler) http.Handler {
+	// Initialize metrics
+	rejected, _ := meter.Int64Counter(
+		"http.routes.disabled.requests",
+		metric.WithDescription("Total number of requests rejected because their route is disabled"),
+	)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := routePattern(r)
+			if !toggles.Disabled(r.Method, route) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			rejected.Add(ctx, 1, metric.WithAttributes(attribute.String("http.route", route)))
+			trace.SpanFromContext(ctx).AddEvent("route.disabled")
+			logger.WarnContext(ctx, "Request to disabled route rejected",
+				slog.String("route", route),
+			)
+			response.Error(w, http.StatusServiceUnavailable, errRouteDisabled)
+		})
+	}
+}
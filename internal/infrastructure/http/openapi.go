@@ -0,0 +1,226 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/graphql"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/handler"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/openapi"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+)
+
+// errorBody is the body of every error response
+var errorBody = (*response.ErrorResponse)(nil)
+
+// operations documents every route registered in setupRoutes.
+// Keep this list next to the routes; routes missing here are reported at startup.
+func operations() []openapi.Operation {
+	product := (*dto.ProductResponse)(nil)
+	products := []*dto.ProductResponse(nil)
+	batch := (*dto.BatchCreateResponse)(nil)
+	importJob := (*dto.ImportJobResponse)(nil)
+	category := (*dto.CategoryResponse)(nil)
+	stock := (*dto.StockResponse)(nil)
+	reservation := (*dto.ReservationResponse)(nil)
+	webhook := (*dto.WebhookResponse)(nil)
+	deliveries := []*dto.WebhookDeliveryResponse(nil)
+	auditEntries := []*dto.AuditEntryResponse(nil)
+	images := []*dto.ImageResponse(nil)
+	imageTypes := []string{"image/jpeg", "image/png", "image/gif"}
+
+	return []openapi.Operation{
+		// Products
+		{ID: "createProduct", Method: http.MethodPost, Path: "/products", Tag: "products", Summary: "Create a product",
+			Request:   (*dto.CreateProductRequest)(nil),
+			Headers:   []string{"Idempotency-Key"},
+			Responses: map[int]any{201: product, 400: errorBody, 409: errorBody, 422: errorBody, 500: errorBody}},
+		{ID: "createProducts", Method: http.MethodPost, Path: "/products:batch", Tag: "products",
+			Summary:             "Create a batch of products from a JSON array or NDJSON",
+			Request:             []*dto.CreateProductRequest(nil),
+			RequestContentTypes: []string{"application/x-ndjson"},
+			Query:               []string{"mode"},
+			Headers:             []string{"Idempotency-Key"},
+			Responses:           map[int]any{201: batch, 207: batch, 400: batch, 409: errorBody, 413: errorBody, 422: errorBody, 500: errorBody}},
+		{ID: "exportProducts", Method: http.MethodGet, Path: "/products/export", Tag: "products",
+			Summary:              "Export the catalogue as CSV or NDJSON",
+			Query:                []string{"format"},
+			ContentType:          "text/csv",
+			ResponseContentTypes: []string{"application/x-ndjson"},
+			Responses:            map[int]any{200: "", 400: errorBody, 500: errorBody}},
+		{ID: "importProducts", Method: http.MethodPost, Path: "/products/import", Tag: "products",
+			Summary:             "Import products from CSV or NDJSON, in the background for large files",
+			RequestContentTypes: []string{"text/csv", "application/x-ndjson"},
+			Query:               []string{"format"},
+			Responses:           map[int]any{200: importJob, 202: importJob, 400: errorBody, 413: errorBody, 500: errorBody, 503: errorBody}},
+		{ID: "getImportJob", Method: http.MethodGet, Path: "/products/import/jobs/{jobID}", Tag: "products",
+			Summary:   "Get the progress of an import job",
+			Responses: map[int]any{200: importJob, 404: errorBody, 500: errorBody}},
+		{ID: "listProducts", Method: http.MethodGet, Path: "/products", Tag: "products", Summary: "List all products",
+			Query:     []string{"include_deleted"},
+			Responses: map[int]any{200: products, 400: errorBody, 500: errorBody}},
+		{ID: "streamProducts", Method: http.MethodGet, Path: "/products/stream", Tag: "products",
+			Summary:     "Stream product changes as Server-Sent Events",
+			ContentType: "text/event-stream",
+			Responses:   map[int]any{200: ""}},
+		{ID: "getProduct", Method: http.MethodGet, Path: "/products/{id}", Tag: "products", Summary: "Get a product",
+			Query:     []string{"as_of"},
+			Responses: map[int]any{200: product, 400: errorBody, 404: errorBody, 500: errorBody}},
+		{ID: "getPriceHistory", Method: http.MethodGet, Path: "/products/{id}/prices", Tag: "products",
+			Summary:   "List the prices a product has had, oldest first",
+			Responses: map[int]any{200: []*dto.PriceChangeResponse(nil), 404: errorBody, 500: errorBody}},
+		{ID: "updateProduct", Method: http.MethodPut, Path: "/products/{id}", Tag: "products", Summary: "Update a product",
+			Request:   (*dto.UpdateProductRequest)(nil),
+			Responses: map[int]any{200: product, 400: errorBody, 404: errorBody, 409: errorBody, 500: errorBody}},
+		{ID: "deleteProduct", Method: http.MethodDelete, Path: "/products/{id}", Tag: "products", Summary: "Soft-delete a product",
+			Responses: map[int]any{204: nil, 404: errorBody, 409: errorBody, 500: errorBody}},
+		{ID: "restoreProduct", Method: http.MethodPost, Path: "/products/{id}:restore", Tag: "products", Summary: "Restore a soft-deleted product",
+			Responses: map[int]any{200: product, 404: errorBody, 409: errorBody, 500: errorBody}},
+
+		// Variants
+		{ID: "getProductBySKU", Method: http.MethodGet, Path: "/skus/{sku}", Tag: "products",
+			Summary:   "Get a variant and its product by SKU",
+			Responses: map[int]any{200: (*dto.SKUResponse)(nil), 404: errorBody, 500: errorBody}},
+
+		// Images
+		{ID: "replaceImages", Method: http.MethodPut, Path: "/products/{id}/images", Tag: "images",
+			Summary:             "Replace the images of a product with a multipart upload of the \"images\" field",
+			RequestContentTypes: []string{"multipart/form-data"},
+			Responses:           map[int]any{200: images, 400: errorBody, 404: errorBody, 413: errorBody, 415: errorBody, 500: errorBody}},
+		{ID: "listImages", Method: http.MethodGet, Path: "/products/{id}/images", Tag: "images", Summary: "List the images of a product",
+			Responses: map[int]any{200: images, 404: errorBody, 500: errorBody}},
+		{ID: "getImage", Method: http.MethodGet, Path: "/products/{id}/images/{imageID}", Tag: "images", Summary: "Download an image",
+			ResponseContentTypes: imageTypes,
+			Responses:            map[int]any{200: "", 404: errorBody, 500: errorBody}},
+		{ID: "getThumbnail", Method: http.MethodGet, Path: "/products/{id}/images/{imageID}/thumbnail", Tag: "images",
+			Summary:              "Download the PNG thumbnail of an image once it is ready",
+			ResponseContentTypes: []string{"image/png"},
+			Responses:            map[int]any{200: "", 404: errorBody, 500: errorBody}},
+
+		// Audit trail
+		{ID: "getProductHistory", Method: http.MethodGet, Path: "/products/{id}/history", Tag: "audit",
+			Summary:   "List the audit entries of a product, oldest first",
+			Responses: map[int]any{200: auditEntries, 404: errorBody, 500: errorBody}},
+		{ID: "listAuditEntries", Method: http.MethodGet, Path: "/audit", Tag: "audit",
+			Summary:   "List the audit entries since a point in time, oldest first",
+			Query:     []string{"since", "limit"},
+			Responses: map[int]any{200: auditEntries, 400: errorBody, 500: errorBody}},
+
+		// Administration
+		{ID: "getLogLevels", Method: http.MethodGet, Path: "/admin/loglevel", Tag: "admin",
+			Summary:   "Show the global log level and the level of each component logger",
+			Responses: map[int]any{200: (*handler.LogLevelsResponse)(nil)}},
+		{ID: "setLogLevel", Method: http.MethodPut, Path: "/admin/loglevel", Tag: "admin",
+			Summary:   "Change the global log level, or the level of the repository, service or http logger",
+			Request:   (*handler.LogLevelRequest)(nil),
+			Responses: map[int]any{200: (*handler.LogLevelsResponse)(nil), 400: errorBody}},
+
+		// Inventory
+		{ID: "getStock", Method: http.MethodGet, Path: "/products/{id}/stock", Tag: "inventory", Summary: "Get the stock level of a product",
+			Responses: map[int]any{200: stock, 404: errorBody, 500: errorBody}},
+		{ID: "setStock", Method: http.MethodPut, Path: "/products/{id}/stock", Tag: "inventory", Summary: "Set the on-hand stock of a product",
+			Request:   (*dto.SetStockRequest)(nil),
+			Responses: map[int]any{200: stock, 400: errorBody, 404: errorBody, 500: errorBody}},
+		{ID: "createReservation", Method: http.MethodPost, Path: "/products/{id}/reservations", Tag: "inventory", Summary: "Reserve stock",
+			Request:   (*dto.CreateReservationRequest)(nil),
+			Responses: map[int]any{201: reservation, 400: errorBody, 404: errorBody, 409: errorBody, 500: errorBody}},
+		{ID: "getReservation", Method: http.MethodGet, Path: "/products/{id}/reservations/{reservationID}", Tag: "inventory", Summary: "Get a reservation",
+			Responses: map[int]any{200: reservation, 404: errorBody, 500: errorBody}},
+		{ID: "confirmReservation", Method: http.MethodPost, Path: "/products/{id}/reservations/{reservationID}/confirm", Tag: "inventory", Summary: "Confirm a reservation",
+			Responses: map[int]any{200: reservation, 404: errorBody, 409: errorBody, 500: errorBody}},
+		{ID: "releaseReservation", Method: http.MethodPost, Path: "/products/{id}/reservations/{reservationID}/release", Tag: "inventory", Summary: "Release a reservation",
+			Responses: map[int]any{200: reservation, 404: errorBody, 409: errorBody, 500: errorBody}},
+
+		// Categories
+		{ID: "createCategory", Method: http.MethodPost, Path: "/categories", Tag: "categories", Summary: "Create a category",
+			Request:   (*dto.CreateCategoryRequest)(nil),
+			Responses: map[int]any{201: category, 400: errorBody, 500: errorBody}},
+		{ID: "listCategories", Method: http.MethodGet, Path: "/categories", Tag: "categories", Summary: "List all categories",
+			Responses: map[int]any{200: []*dto.CategoryResponse(nil), 500: errorBody}},
+		{ID: "getCategory", Method: http.MethodGet, Path: "/categories/{id}", Tag: "categories", Summary: "Get a category",
+			Responses: map[int]any{200: category, 404: errorBody, 500: errorBody}},
+		{ID: "updateCategory", Method: http.MethodPut, Path: "/categories/{id}", Tag: "categories", Summary: "Update a category",
+			Request:   (*dto.UpdateCategoryRequest)(nil),
+			Responses: map[int]any{200: category, 400: errorBody, 404: errorBody, 500: errorBody}},
+		{ID: "deleteCategory", Method: http.MethodDelete, Path: "/categories/{id}", Tag: "categories", Summary: "Delete a category",
+			Responses: map[int]any{204: nil, 404: errorBody, 409: errorBody, 500: errorBody}},
+		{ID: "listCategoryProducts", Method: http.MethodGet, Path: "/categories/{id}/products", Tag: "categories",
+			Summary:   "List the products of a category and its descendants",
+			Responses: map[int]any{200: products, 404: errorBody, 500: errorBody}},
+
+		// Webhooks
+		{ID: "createWebhook", Method: http.MethodPost, Path: "/webhooks", Tag: "webhooks", Summary: "Subscribe a webhook",
+			Request:   (*dto.CreateWebhookRequest)(nil),
+			Responses: map[int]any{201: webhook, 400: errorBody, 500: errorBody}},
+		{ID: "listWebhooks", Method: http.MethodGet, Path: "/webhooks", Tag: "webhooks", Summary: "List webhook subscriptions",
+			Responses: map[int]any{200: []*dto.WebhookResponse(nil), 500: errorBody}},
+		{ID: "listDeadLetters", Method: http.MethodGet, Path: "/webhooks/dead-letters", Tag: "webhooks", Summary: "List dead-lettered deliveries",
+			Responses: map[int]any{200: deliveries, 500: errorBody}},
+		{ID: "retryDelivery", Method: http.MethodPost, Path: "/webhooks/deliveries/{deliveryID}/retry", Tag: "webhooks", Summary: "Requeue a dead-lettered delivery",
+			Responses: map[int]any{202: (*dto.WebhookDeliveryResponse)(nil), 404: errorBody, 409: errorBody, 500: errorBody}},
+		{ID: "getWebhook", Method: http.MethodGet, Path: "/webhooks/{id}", Tag: "webhooks", Summary: "Get a webhook subscription",
+			Responses: map[int]any{200: webhook, 404: errorBody, 500: errorBody}},
+		{ID: "deleteWebhook", Method: http.MethodDelete, Path: "/webhooks/{id}", Tag: "webhooks", Summary: "Delete a webhook subscription",
+			Responses: map[int]any{204: nil, 404: errorBody, 500: errorBody}},
+		{ID: "listDeliveries", Method: http.MethodGet, Path: "/webhooks/{id}/deliveries", Tag: "webhooks", Summary: "List the deliveries of a webhook",
+			Responses: map[int]any{200: deliveries, 404: errorBody, 500: errorBody}},
+
+		// GraphQL and operational endpoints
+		{ID: "graphql", Method: http.MethodPost, Path: "/graphql", Tag: "graphql", Summary: "Execute a GraphQL operation",
+			Request:   (*graphql.GraphQLRequest)(nil),
+			Responses: map[int]any{200: map[string]any(nil), 400: map[string]any(nil)}},
+		{ID: "health", Method: http.MethodGet, Path: "/health", Tag: "operations", Summary: "Health check",
+			ContentType: "text/plain", Responses: map[int]any{200: ""}},
+		{ID: "metrics", Method: http.MethodGet, Path: "/metrics", Tag: "operations", Summary: "Prometheus metrics",
+			ContentType: "text/plain", Responses: map[int]any{200: ""}},
+		{ID: "openapi", Method: http.MethodGet, Path: "/openapi.json", Tag: "operations", Summary: "This OpenAPI document",
+			Responses: map[int]any{200: map[string]any(nil)}},
+		{ID: "docs", Method: http.MethodGet, Path: "/docs", Tag: "operations", Summary: "Swagger UI",
+			ContentType: "text/html", Responses: map[int]any{200: ""}},
+	}
+}
+
+// newDocument builds the OpenAPI document from the documented operations
+func newDocument() *openapi.Builder {
+	builder := openapi.NewBuilder("Products API", "1.0.0")
+	for _, op := range operations() {
+		// Any route can answer 401, 403 and 429 when authentication, authorization and rate limiting are enabled,
+		// and 503 while it is disabled in the configuration
+		op.Responses[401] = errorBody
+		op.Responses[403] = errorBody
+		op.Responses[429] = errorBody
+		op.Responses[503] = errorBody
+		builder.Add(op)
+	}
+	return builder
+}
+
+// checkDocumented warns about registered routes that have no documented operation
+// and adds a bare operation for them, so the document always lists every route
+func (s *Server) checkDocumented() {
+	_ = chi.Walk(s.router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		path := strings.TrimSuffix(route, "/")
+		if path == "" {
+			path = "/"
+		}
+		if s.openapi.Has(method, path) {
+			return nil
+		}
+
+		s.logger.Warn("Route is missing from the OpenAPI document",
+			slog.String("method", method),
+			slog.String("route", path),
+		)
+		s.openapi.Add(openapi.Operation{
+			ID:        strings.ToLower(method) + path,
+			Method:    method,
+			Path:      path,
+			Summary:   "Undocumented",
+			Responses: map[int]any{200: nil},
+		})
+		return nil
+	})
+}
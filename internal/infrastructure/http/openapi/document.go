@@ -0,0 +1,285 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.1.0"
+
+// Document is an OpenAPI 3.1 document
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]*Endpoint `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Components holds the schemas shared by operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Endpoint is an operation object of the document
+type Endpoint struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *Body                `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Body is a request body
+type Body struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is a response of an operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the JSON Schema subset generated from Go types
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Operation documents one route. Request and response bodies are given as Go values
+// (usually nil pointers to DTOs) and converted to schemas by reflection, so the document
+// always matches the types the handlers actually encode and decode.
+type Operation struct {
+	ID          string
+	Method      string
+	Path        string
+	Summary     string
+	Tag         string
+	Request     any
+	Responses   map[int]any
+	ContentType string
+	Query       []string
+	Headers     []string
+	// RequestContentTypes and ResponseContentTypes list further media types accepted for the request
+	// body and sent in responses with a body; their bodies are documented as plain strings
+	RequestContentTypes  []string
+	ResponseContentTypes []string
+}
+
+// Builder assembles a document from operations
+type Builder struct {
+	doc *Document
+}
+
+// NewBuilder creates a new document builder
+func NewBuilder(title, version string) *Builder {
+	return &Builder{
+		doc: &Document{
+			OpenAPI:    Version,
+			Info:       Info{Title: title, Version: version},
+			Paths:      make(map[string]map[string]*Endpoint),
+			Components: Components{Schemas: make(map[string]*Schema)},
+		},
+	}
+}
+
+// Add adds an operation to the document
+func (b *Builder) Add(op Operation) {
+	endpoint := &Endpoint{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Responses:   make(map[string]*Response),
+	}
+	if op.Tag != "" {
+		endpoint.Tags = []string{op.Tag}
+	}
+
+	for _, name := range pathParameters(op.Path) {
+		endpoint.Parameters = append(endpoint.Parameters, &Parameter{
+			Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	for _, name := range op.Query {
+		endpoint.Parameters = append(endpoint.Parameters, &Parameter{
+			Name: name, In: "query", Schema: &Schema{Type: "string"},
+		})
+	}
+	for _, name := range op.Headers {
+		endpoint.Parameters = append(endpoint.Parameters, &Parameter{
+			Name: name, In: "header", Schema: &Schema{Type: "string"},
+		})
+	}
+
+	if op.Request != nil || len(op.RequestContentTypes) > 0 {
+		endpoint.RequestBody = &Body{Required: true, Content: make(map[string]*MediaType)}
+		if op.Request != nil {
+			endpoint.RequestBody.Content["application/json"] = &MediaType{Schema: b.schema(reflect.TypeOf(op.Request))}
+		}
+		for _, mediaType := range op.RequestContentTypes {
+			endpoint.RequestBody.Content[mediaType] = &MediaType{Schema: &Schema{Type: "string"}}
+		}
+	}
+
+	contentType := op.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	for status, body := range op.Responses {
+		response := &Response{Description: http.StatusText(status)}
+		if body != nil {
+			response.Content = map[string]*MediaType{contentType: {Schema: b.schema(reflect.TypeOf(body))}}
+			for _, mediaType := range op.ResponseContentTypes {
+				response.Content[mediaType] = &MediaType{Schema: &Schema{Type: "string"}}
+			}
+		}
+		endpoint.Responses[strconv.Itoa(status)] = response
+	}
+
+	if b.doc.Paths[op.Path] == nil {
+		b.doc.Paths[op.Path] = make(map[string]*Endpoint)
+	}
+	b.doc.Paths[op.Path][strings.ToLower(op.Method)] = endpoint
+}
+
+// Has reports whether an operation is documented for the method and path
+func (b *Builder) Has(method, path string) bool {
+	_, ok := b.doc.Paths[path][strings.ToLower(method)]
+	return ok
+}
+
+// Document returns the assembled document
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schema returns the schema of a Go type; named structs become shared components
+func (b *Builder) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		if _, ok := b.doc.Components.Schemas[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate
+			b.doc.Components.Schemas[t.Name()] = &Schema{}
+			*b.doc.Components.Schemas[t.Name()] = *b.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		// Interfaces and anything else accept any JSON value
+		return &Schema{}
+	}
+}
+
+// object returns the schema of a struct from its exported, JSON-encoded fields.
+// Fields without omitempty are required.
+func (b *Builder) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = b.schema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+	return s
+}
+
+// pathParameters returns the names of the {parameters} in a path template.
+// A parameter may be followed by a literal suffix in its segment, as in /products/{id}:restore.
+func pathParameters(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, _, ok := splitParameter(segment); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// splitParameter splits a {parameter} segment of a path template into its name and literal suffix
+func splitParameter(segment string) (name, suffix string, ok bool) {
+	if !strings.HasPrefix(segment, "{") {
+		return "", "", false
+	}
+	end := strings.Index(segment, "}")
+	if end < 0 {
+		return "", "", false
+	}
+	return segment[1:end], segment[end+1:], true
+}
@@ -0,0 +1,25 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/response"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// DocumentHandler serves the document as JSON
+func DocumentHandler(doc *Document) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusOK, doc)
+	}
+}
+
+// DocsHandler serves a Swagger UI page that renders /openapi.json
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(docsPage)
+}
@@ -0,0 +1,326 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxValidatedBody is the largest request or response body the validator inspects
+const maxValidatedBody = 1 << 20
+
+// Violation is a mismatch between a request or response and the document
+type Violation struct {
+	Pointer string
+	Message string
+}
+
+// Validator checks requests and responses against a document.
+// Violations are only reported as span events, logs and metrics; traffic is never rejected.
+type Validator struct {
+	doc        *Document
+	logger     *slog.Logger
+	violations metric.Int64Counter
+}
+
+// NewValidator creates a new validator for the document
+func NewValidator(doc *Document, meter metric.Meter, logger *slog.Logger) *Validator {
+	// Initialize metrics
+	violations, _ := meter.Int64Counter(
+		"openapi.violations",
+		metric.WithDescription("Total number of requests and responses that did not match the OpenAPI document"),
+	)
+
+	return &Validator{
+		doc:        doc,
+		logger:     logger,
+		violations: violations,
+	}
+}
+
+// Middleware validates the request body before the handler runs and the response after it
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint, path := v.find(r.Method, r.URL.Path)
+		if endpoint == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if endpoint.RequestBody != nil {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxValidatedBody))
+			if err == nil {
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+				v.report(r, endpoint, path, "request", v.validateBody(endpoint.RequestBody.Content, r.Header.Get("Content-Type"), body))
+			}
+		}
+
+		rw := &capturingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		response, ok := endpoint.Responses[strconv.Itoa(rw.status)]
+		if !ok {
+			v.report(r, endpoint, path, "response", []Violation{{
+				Message: fmt.Sprintf("status %d is not documented", rw.status),
+			}})
+			return
+		}
+		if rw.capture {
+			v.report(r, endpoint, path, "response", v.validateBody(response.Content, rw.Header().Get("Content-Type"), rw.body.Bytes()))
+		}
+	})
+}
+
+// report records violations on the current span, in the logs and in metrics
+func (v *Validator) report(r *http.Request, endpoint *Endpoint, path, direction string, violations []Violation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	ctx := r.Context()
+	span := trace.SpanFromContext(ctx)
+	for _, violation := range violations {
+		span.AddEvent("openapi.violation", trace.WithAttributes(
+			attribute.String("openapi.operation_id", endpoint.OperationID),
+			attribute.String("openapi.direction", direction),
+			attribute.String("openapi.pointer", violation.Pointer),
+			attribute.String("openapi.message", violation.Message),
+		))
+	}
+
+	v.violations.Add(ctx, int64(len(violations)), metric.WithAttributes(
+		attribute.String("openapi.operation_id", endpoint.OperationID),
+		attribute.String("openapi.direction", direction),
+	))
+
+	v.logger.WarnContext(ctx, "Request does not match OpenAPI document",
+		slog.String("operation_id", endpoint.OperationID),
+		slog.String("path", path),
+		slog.String("direction", direction),
+		slog.Int("violations", len(violations)),
+		slog.String("first_violation", violations[0].Pointer+": "+violations[0].Message),
+	)
+}
+
+// validateBody checks a JSON body against the schema documented for its media type
+func (v *Validator) validateBody(content map[string]*MediaType, contentType string, body []byte) []Violation {
+	if len(content) == 0 {
+		if len(body) > 0 {
+			return []Violation{{Message: "body is not documented"}}
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	documented, ok := content[mediaType]
+	if !ok {
+		return []Violation{{Message: fmt.Sprintf("content type %q is not documented", contentType)}}
+	}
+	if mediaType != "application/json" {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []Violation{{Message: "body is not valid JSON: " + err.Error()}}
+	}
+
+	return v.validate(documented.Schema, value, "")
+}
+
+// validate checks a decoded JSON value against a schema
+func (v *Validator) validate(schema *Schema, value any, pointer string) []Violation {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		return v.validate(v.doc.Components.Schemas[name], value, pointer)
+	}
+
+	mismatch := func() []Violation {
+		return []Violation{{Pointer: pointer, Message: fmt.Sprintf("expected %s, got %s", schema.Type, jsonType(value))}}
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return mismatch()
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return []Violation{{Pointer: pointer, Message: "expected an RFC 3339 date-time"}}
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch()
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return mismatch()
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return mismatch()
+		}
+		if _, err := n.Int64(); err != nil {
+			return mismatch()
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return mismatch()
+		}
+		var violations []Violation
+		for i, item := range items {
+			violations = append(violations, v.validate(schema.Items, item, pointer+"/"+strconv.Itoa(i))...)
+		}
+		return violations
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		return v.validateObject(schema, object, pointer)
+	}
+	return nil
+}
+
+// validateObject checks required, known and additional properties of an object
+func (v *Validator) validateObject(schema *Schema, object map[string]any, pointer string) []Violation {
+	var violations []Violation
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			violations = append(violations, Violation{Pointer: pointer + "/" + name, Message: "required property is missing"})
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, known := schema.Properties[name]
+		switch {
+		case known:
+			violations = append(violations, v.validate(property, object[name], pointer+"/"+name)...)
+		case schema.AdditionalProperties != nil:
+			violations = append(violations, v.validate(schema.AdditionalProperties, object[name], pointer+"/"+name)...)
+		case schema.Properties != nil:
+			violations = append(violations, Violation{Pointer: pointer + "/" + name, Message: "property is not documented"})
+		}
+	}
+	return violations
+}
+
+// find returns the endpoint documented for the method and request path.
+// Literal segments win over parameters, so /products/stream is not matched as /products/{id}.
+func (v *Validator) find(method, requestPath string) (*Endpoint, string) {
+	segments := strings.Split(strings.TrimSuffix(requestPath, "/"), "/")
+	var best *Endpoint
+	bestPath, bestParams := "", -1
+
+	for path, endpoints := range v.doc.Paths {
+		endpoint, ok := endpoints[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+
+		template := strings.Split(strings.TrimSuffix(path, "/"), "/")
+		if len(template) != len(segments) {
+			continue
+		}
+
+		params, matched := 0, true
+		for i, segment := range template {
+			if _, suffix, ok := splitParameter(segment); ok {
+				if len(segments[i]) <= len(suffix) || !strings.HasSuffix(segments[i], suffix) {
+					matched = false
+					break
+				}
+				params++
+				continue
+			}
+			if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if matched && (bestParams < 0 || params < bestParams) {
+			best, bestPath, bestParams = endpoint, path, params
+		}
+	}
+	return best, bestPath
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// capturingWriter records the status code and, for JSON responses, a copy of the body
+type capturingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	capture     bool
+	body        bytes.Buffer
+}
+
+func (w *capturingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+		mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		w.capture = mediaType == "application/json"
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *capturingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.capture && w.body.Len()+len(b) <= maxValidatedBody {
+		w.body.Write(b)
+	} else {
+		w.capture = false
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush streams
+func (w *capturingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
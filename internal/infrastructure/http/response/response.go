@@ -26,8 +26,24 @@ func Error(w http.ResponseWriter, status int, err error) {
 		errorType = "not_found"
 	case http.StatusBadRequest:
 		errorType = "bad_request"
+	case http.StatusUnauthorized:
+		errorType = "unauthorized"
+	case http.StatusForbidden:
+		errorType = "forbidden"
+	case http.StatusConflict:
+		errorType = "conflict"
+	case http.StatusRequestEntityTooLarge:
+		errorType = "payload_too_large"
+	case http.StatusUnsupportedMediaType:
+		errorType = "unsupported_media_type"
+	case http.StatusUnprocessableEntity:
+		errorType = "unprocessable_entity"
+	case http.StatusTooManyRequests:
+		errorType = "too_many_requests"
 	case http.StatusInternalServerError:
 		errorType = "internal_server_error"
+	case http.StatusServiceUnavailable:
+		errorType = "service_unavailable"
 	}
 
 	JSON(w, status, ErrorResponse{
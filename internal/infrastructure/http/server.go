@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -8,8 +9,10 @@ import (
 	"github.com/go-chi/chi/v5"
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/config"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/graphql"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/handler"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/middleware"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/openapi"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/telemetry"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
@@ -17,28 +20,53 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// Handlers groups the HTTP handlers served by the API
+type Handlers struct {
+	Product   *handler.ProductHandler
+	Catalog   *handler.CatalogHandler
+	Category  *handler.CategoryHandler
+	Inventory *handler.InventoryHandler
+	Webhook   *handler.WebhookHandler
+	Stream    *handler.StreamHandler
+	Audit     *handler.AuditHandler
+	Image     *handler.ImageHandler
+	Admin     *handler.AdminHandler
+	GraphQL   *graphql.Handler
+	// Idempotency wraps POST /products so retries with the same Idempotency-Key are replayed
+	Idempotency func(http.Handler) http.Handler
+}
+
 // Server represents the HTTP server
 type Server struct {
+	server    *http.Server
 	router    *chi.Mux
 	config    *config.ServerConfig
-	handler   *handler.ProductHandler
+	handlers  Handlers
+	guards    []func(http.Handler) http.Handler
+	openapi   *openapi.Builder
 	tracer    trace.Tracer
 	logger    *slog.Logger
 	telemetry *telemetry.Telemetry
 }
 
-// NewServer creates a new HTTP server
+// NewServer creates a new HTTP server.
+// guards are the rate limiting, authentication and authorization middlewares, in order;
+// without any, every route is served anonymously and without limits.
 func NewServer(
 	cfg *config.ServerConfig,
-	handler *handler.ProductHandler,
+	handlers Handlers,
+	guards []func(http.Handler) http.Handler,
 	tracer trace.Tracer,
 	logger *slog.Logger,
 	telem *telemetry.Telemetry,
 ) *Server {
 	s := &Server{
+		server:    &http.Server{Addr: fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)},
 		router:    chi.NewRouter(),
 		config:    cfg,
-		handler:   handler,
+		handlers:  handlers,
+		guards:    guards,
+		openapi:   newDocument(),
 		tracer:    tracer,
 		logger:    logger,
 		telemetry: telem,
@@ -46,6 +74,7 @@ func NewServer(
 
 	s.setupMiddleware()
 	s.setupRoutes()
+	s.checkDocumented()
 
 	return s
 }
@@ -56,13 +85,23 @@ func (s *Server) setupMiddleware() {
 	s.router.Use(middleware.StructuredLogger(s.logger))
 	s.router.Use(chimiddleware.Recoverer)
 	s.router.Use(chimiddleware.RequestID)
+	s.router.Use(middleware.RequestIDContext())
 
 	// Add HTTP route to context so all logs include it automatically
 	s.router.Use(middleware.HTTPRouteContext())
 
 	// Add OpenTelemetry active requests tracking
 	meter := s.telemetry.MeterProvider.Meter("products-api")
-	s.router.Use(middleware.ActiveRequestsMiddleware(meter))
+	// Long-lived streams are tracked by products.stream.active instead
+	s.router.Use(middleware.ActiveRequestsMiddleware(meter, "/products/stream"))
+
+	// Rate limit, authenticate and authorize callers; the principal is added to the request context, spans and logs
+	s.router.Use(s.guards...)
+
+	// Check requests and responses against the OpenAPI document, reporting violations as span events
+	if s.config.OpenAPIValidation {
+		s.router.Use(openapi.NewValidator(s.openapi.Document(), meter, s.logger).Middleware)
+	}
 
 	// OPTIONAL: Add custom milliseconds duration metric (in addition to standard seconds metric)
 	// Uncomment the line below if you prefer milliseconds-based duration metrics
@@ -71,12 +110,74 @@ func (s *Server) setupMiddleware() {
 
 // setupRoutes configures the API routes
 func (s *Server) setupRoutes() {
+	// Batch create; registered outside /products since chi has no {id} match for ":batch"
+	s.router.With(s.handlers.Idempotency).Post("/products:batch", s.handlers.Product.CreateProducts)
+
 	s.router.Route("/products", func(r chi.Router) {
-		r.Post("/", s.handler.CreateProduct)
-		r.Get("/", s.handler.ListProducts)
-		r.Get("/{id}", s.handler.GetProduct)
+		r.With(s.handlers.Idempotency).Post("/", s.handlers.Product.CreateProduct)
+		r.Get("/", s.handlers.Product.ListProducts)
+		r.Get("/stream", s.handlers.Stream.StreamProducts)
+		r.Get("/export", s.handlers.Catalog.ExportProducts)
+		r.Post("/import", s.handlers.Catalog.ImportProducts)
+		r.Get("/import/jobs/{jobID}", s.handlers.Catalog.GetImportJob)
+		r.Get("/{id}", s.handlers.Product.GetProduct)
+		r.Put("/{id}", s.handlers.Product.UpdateProduct)
+		r.Delete("/{id}", s.handlers.Product.DeleteProduct)
+		r.Post("/{id}:restore", s.handlers.Product.RestoreProduct)
+		r.Get("/{id}/prices", s.handlers.Product.GetPriceHistory)
+		r.Get("/{id}/history", s.handlers.Audit.GetProductHistory)
+
+		// Images and their thumbnails
+		r.Put("/{id}/images", s.handlers.Image.ReplaceImages)
+		r.Get("/{id}/images", s.handlers.Image.ListImages)
+		r.Get("/{id}/images/{imageID}", s.handlers.Image.GetImage)
+		r.Get("/{id}/images/{imageID}/thumbnail", s.handlers.Image.GetThumbnail)
+
+		// Inventory and stock reservations
+		r.Get("/{id}/stock", s.handlers.Inventory.GetStock)
+		r.Put("/{id}/stock", s.handlers.Inventory.SetStock)
+		r.Post("/{id}/reservations", s.handlers.Inventory.CreateReservation)
+		r.Get("/{id}/reservations/{reservationID}", s.handlers.Inventory.GetReservation)
+		r.Post("/{id}/reservations/{reservationID}/confirm", s.handlers.Inventory.ConfirmReservation)
+		r.Post("/{id}/reservations/{reservationID}/release", s.handlers.Inventory.ReleaseReservation)
+	})
+
+	s.router.Route("/categories", func(r chi.Router) {
+		r.Post("/", s.handlers.Category.CreateCategory)
+		r.Get("/", s.handlers.Category.ListCategories)
+		r.Get("/{id}", s.handlers.Category.GetCategory)
+		r.Put("/{id}", s.handlers.Category.UpdateCategory)
+		r.Delete("/{id}", s.handlers.Category.DeleteCategory)
+		r.Get("/{id}/products", s.handlers.Category.ListCategoryProducts)
 	})
 
+	s.router.Route("/webhooks", func(r chi.Router) {
+		r.Post("/", s.handlers.Webhook.CreateWebhook)
+		r.Get("/", s.handlers.Webhook.ListWebhooks)
+		r.Get("/dead-letters", s.handlers.Webhook.ListDeadLetters)
+		r.Post("/deliveries/{deliveryID}/retry", s.handlers.Webhook.RetryDelivery)
+		r.Get("/{id}", s.handlers.Webhook.GetWebhook)
+		r.Delete("/{id}", s.handlers.Webhook.DeleteWebhook)
+		r.Get("/{id}/deliveries", s.handlers.Webhook.ListDeliveries)
+	})
+
+	// Variant lookup by SKU
+	s.router.Get("/skus/{sku}", s.handlers.Product.GetProductBySKU)
+
+	// Audit trail of product mutations
+	s.router.Get("/audit", s.handlers.Audit.ListEntries)
+
+	// Runtime log levels, globally and per component logger
+	s.router.Get("/admin/loglevel", s.handlers.Admin.GetLogLevels)
+	s.router.Put("/admin/loglevel", s.handlers.Admin.SetLogLevel)
+
+	// GraphQL endpoint over the product service
+	s.router.Post("/graphql", s.handlers.GraphQL.ServeHTTP)
+
+	// OpenAPI document and Swagger UI
+	s.router.Get("/openapi.json", openapi.DocumentHandler(s.openapi.Document()))
+	s.router.Get("/docs", openapi.DocsHandler)
+
 	// Health check endpoint
 	s.router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -87,11 +188,10 @@ func (s *Server) setupRoutes() {
 	s.router.Get("/metrics", promhttp.Handler().ServeHTTP)
 }
 
-// Start starts the HTTP server
+// Start starts the HTTP server; it returns http.ErrServerClosed once Shutdown is called
 func (s *Server) Start() error {
-	addr := fmt.Sprintf("%s:%s", s.config.Host, s.config.Port)
 	s.logger.Info("Starting HTTP server",
-		slog.String("address", addr),
+		slog.String("address", s.server.Addr),
 	)
 
 	// Wrap the entire router with otelhttp for automatic HTTP metrics and tracing
@@ -116,5 +216,11 @@ func (s *Server) Start() error {
 		}),
 	)
 
-	return http.ListenAndServe(addr, handler)
+	s.server.Handler = handler
+	return s.server.ListenAndServe()
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests to finish, until ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
 }
@@ -0,0 +1,115 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInFlight            = errors.New("a request with this idempotency key is still in flight")
+	ErrFingerprintMismatch = errors.New("idempotency key was already used with a different request")
+)
+
+// Response is a stored response, replayed for duplicate requests
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store remembers the first response for each idempotency key
+type Store interface {
+	// Begin reserves the key for a request with the given fingerprint.
+	// It returns the stored response when the key was already completed with the same fingerprint,
+	// ErrInFlight while the first request is running and ErrFingerprintMismatch for a different request.
+	Begin(ctx context.Context, key, fingerprint string) (*Response, error)
+	// Complete stores the response of the request that reserved the key
+	Complete(ctx context.Context, key string, response *Response) error
+	// Release drops the reservation so the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// record is the state of one idempotency key
+type record struct {
+	fingerprint string
+	response    *Response
+	expiresAt   time.Time
+}
+
+// MemoryStore keeps idempotency records in process memory for a fixed TTL
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]*record
+	ttl       time.Duration
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates a new in-memory store keeping responses for ttl
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		records:   make(map[string]*record),
+		ttl:       ttl,
+		lastSweep: time.Now(),
+	}
+}
+
+// Begin reserves the key or returns the outcome of the request that reserved it
+func (s *MemoryStore) Begin(_ context.Context, key, fingerprint string) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if r, ok := s.records[key]; ok && now.Before(r.expiresAt) {
+		switch {
+		case r.fingerprint != fingerprint:
+			return nil, ErrFingerprintMismatch
+		case r.response == nil:
+			return nil, ErrInFlight
+		default:
+			return r.response, nil
+		}
+	}
+
+	s.records[key] = &record{fingerprint: fingerprint, expiresAt: now.Add(s.ttl)}
+	return nil, nil
+}
+
+// Complete stores the response; the TTL starts when the response is stored
+func (s *MemoryStore) Complete(_ context.Context, key string, response *Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[key]; ok {
+		r.response = response
+		r.expiresAt = time.Now().Add(s.ttl)
+	}
+	return nil
+}
+
+// Release drops the reservation of the key
+func (s *MemoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep evicts expired records, at most once per minute
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, r := range s.records {
+		if now.After(r.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Rule configures a token bucket: Rate tokens are added per second, up to Burst
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+// Decision is the outcome of taking a token from a bucket
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until a token is available; zero when allowed
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// Limiter stores token buckets by key
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (Decision, error)
+}
+
+// Rules holds the default rule and the per-route overrides
+type Rules struct {
+	Default Rule
+	Routes  map[string]Rule
+}
+
+// ParseRules parses per-route rules given as comma-separated "METHOD /route=rate:burst" entries.
+// Routes are chi route patterns, e.g. "POST /products" or "GET /products/{id}".
+func ParseRules(rate float64, burst int, routes string) (*Rules, error) {
+	rules := &Rules{
+		Default: Rule{Rate: rate, Burst: burst},
+		Routes:  make(map[string]Rule),
+	}
+
+	for _, entry := range strings.Split(routes, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, params, ok := strings.Cut(entry, "=")
+		rateValue, burstValue, hasBurst := strings.Cut(params, ":")
+		if !ok || !hasBurst {
+			return nil, fmt.Errorf("invalid rate limit rule %q, expected METHOD /route=rate:burst", entry)
+		}
+
+		method, pattern, ok := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !strings.HasPrefix(strings.TrimSpace(pattern), "/") {
+			return nil, fmt.Errorf("invalid rate limit rule %q, expected METHOD /route=rate:burst", entry)
+		}
+
+		r, err := strconv.ParseFloat(strings.TrimSpace(rateValue), 64)
+		if err != nil || r <= 0 {
+			return nil, fmt.Errorf("invalid rate in rate limit rule %q", entry)
+		}
+		b, err := strconv.Atoi(strings.TrimSpace(burstValue))
+		if err != nil || b <= 0 {
+			return nil, fmt.Errorf("invalid burst in rate limit rule %q", entry)
+		}
+
+		rules.Routes[strings.ToUpper(method)+" "+strings.TrimSpace(pattern)] = Rule{Rate: r, Burst: b}
+	}
+
+	return rules, nil
+}
+
+// For returns the rule of the route, or the default rule
+func (r *Rules) For(method, route string) Rule {
+	if rule, ok := r.Routes[method+" "+route]; ok {
+		return rule
+	}
+	return r.Default
+}
+
+// LiveRules holds the rules in force; they can be swapped while requests are being checked
+type LiveRules struct {
+	rules atomic.Pointer[Rules]
+}
+
+// NewLiveRules creates live rules starting with rules
+func NewLiveRules(rules *Rules) *LiveRules {
+	l := &LiveRules{}
+	l.Swap(rules)
+	return l
+}
+
+// Swap replaces the rules in force
+func (l *LiveRules) Swap(rules *Rules) {
+	l.rules.Store(rules)
+}
+
+// For returns the rule of the route under the rules in force
+func (l *LiveRules) For(method, route string) Rule {
+	return l.rules.Load().For(method, route)
+}
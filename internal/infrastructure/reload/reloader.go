@@ -0,0 +1,216 @@
+package reload
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/config"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/middleware"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/ratelimit"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// reloadable are the settings applied to the running API; any other change needs a restart
+var reloadable = map[string]bool{
+	"log.level":              true,
+	"otlp.sampler_ratio":     true,
+	"rate_limit.rate":        true,
+	"rate_limit.burst":       true,
+	"rate_limit.routes":      true,
+	"server.disabled_routes": true,
+}
+
+// Targets are the parts of the running API the reloadable settings are applied to
+type Targets struct {
+	// LogLevels receives log.level as the global level
+	LogLevels *telemetry.LogLevels
+	// Sampler receives otlp.sampler_ratio
+	Sampler *telemetry.RatioSampler
+	// RateLimits receives rate_limit.rate, rate_limit.burst and rate_limit.routes; nil when rate limiting is off
+	RateLimits *ratelimit.LiveRules
+	// Routes receives server.disabled_routes
+	Routes *middleware.RouteToggles
+}
+
+// Reloader loads the configuration again on SIGHUP or when the config file changes and applies its reloadable settings.
+// A configuration that fails to load or validate is rejected as a whole and the one in force is kept.
+type Reloader struct {
+	mu      sync.Mutex
+	args    []string
+	started *config.Config
+	current *config.Config
+	targets Targets
+	tracer  trace.Tracer
+	logger  *slog.Logger
+	reloads metric.Int64Counter
+}
+
+// NewReloader creates a reloader for the configuration cfg, loaded from the command-line args
+func NewReloader(args []string, cfg *config.Config, targets Targets, tracer trace.Tracer, meter metric.Meter, logger *slog.Logger) *Reloader {
+	// Initialize metrics
+	reloads, _ := meter.Int64Counter(
+		"config.reloads",
+		metric.WithDescription("Total number of configuration reloads by trigger and result"),
+	)
+
+	return &Reloader{
+		args:    args,
+		started: cfg,
+		current: cfg,
+		targets: targets,
+		tracer:  tracer,
+		logger:  logger,
+		reloads: reloads,
+	}
+}
+
+// fileState identifies a version of the config file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Run blocks, reloading on SIGHUP and, with a config file, whenever the file changes, until ctx is done
+func (r *Reloader) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	path := r.started.File()
+	var changes <-chan time.Time
+	var last fileState
+	if path != "" {
+		ticker := time.NewTicker(r.started.Reload.WatchInterval)
+		defer ticker.Stop()
+		changes = ticker.C
+		last, _ = stat(path)
+	}
+
+	r.logger.Info("Configuration reloader started",
+		slog.String("file", path),
+		slog.String("watch_interval", r.started.Reload.WatchInterval.String()),
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			_ = r.Reload(ctx, "signal")
+		case <-changes:
+			// A missing file is usually being replaced; wait for the new version
+			state, err := stat(path)
+			if err != nil || state == last {
+				continue
+			}
+			last = state
+			_ = r.Reload(ctx, "file")
+		}
+	}
+}
+
+// stat returns the current version of the file at path
+func stat(path string) (fileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// Reload loads the configuration and, if it is valid, applies every reloadable setting at once
+func (r *Reloader) Reload(ctx context.Context, trigger string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ctx, span := r.tracer.Start(ctx, "ConfigReloader.Reload",
+		trace.WithNewRoot(),
+		trace.WithAttributes(attribute.String("config.reload.trigger", trigger)),
+	)
+	defer span.End()
+
+	// Check everything before applying anything, so a reload is applied whole or not at all
+	next, err := config.Load(r.args)
+	var rules *ratelimit.Rules
+	if err == nil && r.targets.RateLimits != nil {
+		rules, err = ratelimit.ParseRules(next.RateLimit.Rate, next.RateLimit.Burst, next.RateLimit.Routes)
+	}
+	if err != nil {
+		r.record(ctx, trigger, "rejected")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Configuration rejected")
+		r.logger.ErrorContext(ctx, "Configuration reload rejected, keeping the current configuration",
+			slog.String("trigger", trigger),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	// Apply only what changed, leaving values set at runtime by other means alone
+	var applied, pending []string
+	changed := make(map[string]bool)
+	for _, key := range r.current.Diff(next) {
+		if reloadable[key] {
+			applied = append(applied, key)
+			changed[key] = true
+		}
+	}
+	for _, key := range r.started.Diff(next) {
+		if !reloadable[key] {
+			pending = append(pending, key)
+		}
+	}
+
+	if changed["log.level"] {
+		r.targets.LogLevels.SetGlobal(ctx, next.Log.Level, "reload")
+	}
+	if changed["otlp.sampler_ratio"] {
+		r.targets.Sampler.SetRatio(next.OTLP.SamplerRatio)
+	}
+	if rules != nil && (changed["rate_limit.rate"] || changed["rate_limit.burst"] || changed["rate_limit.routes"]) {
+		r.targets.RateLimits.Swap(rules)
+	}
+	if changed["server.disabled_routes"] {
+		r.targets.Routes.Swap(next.Server.DisabledRoutes)
+	}
+	r.current = next
+
+	result := "applied"
+	if len(applied) == 0 {
+		result = "unchanged"
+	}
+	r.record(ctx, trigger, result)
+	span.SetAttributes(attribute.StringSlice("config.reload.changed", applied))
+	span.SetStatus(codes.Ok, "")
+
+	// Log at the level in force at least, so raising the level never hides the reload that raised it
+	r.logger.Log(ctx, max(slog.LevelInfo, r.targets.LogLevels.Global()), "Configuration reloaded",
+		slog.String("trigger", trigger),
+		slog.String("result", result),
+		slog.String("changed", strings.Join(applied, ",")),
+	)
+	if len(pending) > 0 {
+		r.logger.WarnContext(ctx, "Configuration changes need a restart to take effect",
+			slog.String("settings", strings.Join(pending, ",")),
+		)
+	}
+	return nil
+}
+
+// record counts a reload
+func (r *Reloader) record(ctx context.Context, trigger, result string) {
+	r.reloads.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("trigger", trigger),
+		attribute.String("result", result),
+	))
+}
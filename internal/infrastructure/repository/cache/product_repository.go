@@ -0,0 +1,340 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
+)
+
+// entry is a cached query result
+type entry struct {
+	key        string
+	products   []*domain.Product
+	generation uint64
+	expiresAt  time.Time
+}
+
+// ProductRepository is a read-through cache in front of any domain.ProductRepository.
+// FindByID and the list queries are cached in an LRU with a TTL; writes go to the backend and
+// then invalidate the written products and every cached list. Concurrent misses for the same
+// query are collapsed into a single backend call.
+type ProductRepository struct {
+	next       domain.ProductRepository
+	maxEntries int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// generation is bumped by every write; list entries from an older generation are stale,
+	// and results loaded across a write are not stored
+	generation uint64
+
+	group     singleflight.Group
+	tracer    trace.Tracer
+	logger    *slog.Logger
+	hits      metric.Int64Counter
+	misses    metric.Int64Counter
+	evictions metric.Int64Counter
+}
+
+// NewProductRepository creates a cache of up to maxEntries query results, each kept for ttl, in front of next
+func NewProductRepository(
+	next domain.ProductRepository,
+	maxEntries int,
+	ttl time.Duration,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *ProductRepository {
+	// Initialize metrics
+	hits, _ := meter.Int64Counter(
+		"cache.hits",
+		metric.WithDescription("Total number of cache hits"),
+	)
+
+	misses, _ := meter.Int64Counter(
+		"cache.misses",
+		metric.WithDescription("Total number of cache misses"),
+	)
+
+	evictions, _ := meter.Int64Counter(
+		"cache.evictions",
+		metric.WithDescription("Total number of cache entries evicted"),
+	)
+
+	return &ProductRepository{
+		next:       next,
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		tracer:     tracer,
+		logger:     logger,
+		hits:       hits,
+		misses:     misses,
+		evictions:  evictions,
+	}
+}
+
+// Create stores a new product and invalidates the cached lists
+func (r *ProductRepository) Create(ctx context.Context, product *domain.Product) error {
+	err := r.next.Create(ctx, product)
+	r.invalidate(product.ID)
+	return err
+}
+
+// CreateMany stores new products and invalidates the cached lists
+func (r *ProductRepository) CreateMany(ctx context.Context, products []*domain.Product) error {
+	err := r.next.CreateMany(ctx, products)
+	ids := make([]string, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+	r.invalidate(ids...)
+	return err
+}
+
+// Update replaces a product and invalidates it and the cached lists
+func (r *ProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	err := r.next.Update(ctx, product)
+	r.invalidate(product.ID)
+	return err
+}
+
+// Purge removes the products soft-deleted before the given time and invalidates them and the cached lists
+func (r *ProductRepository) Purge(ctx context.Context, before time.Time) ([]*domain.Product, error) {
+	purged, err := r.next.Purge(ctx, before)
+	ids := make([]string, len(purged))
+	for i, product := range purged {
+		ids[i] = product.ID
+	}
+	r.invalidate(ids...)
+	return purged, err
+}
+
+// FindByID retrieves a product by ID, from the cache when possible
+func (r *ProductRepository) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	products, err := r.get(ctx, "FindByID", "id:"+id, func(ctx context.Context) ([]*domain.Product, error) {
+		product, err := r.next.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.Product{product}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products[0], nil
+}
+
+// FindAll retrieves all products, from the cache when possible
+func (r *ProductRepository) FindAll(ctx context.Context) ([]*domain.Product, error) {
+	return r.get(ctx, "FindAll", "all", r.next.FindAll)
+}
+
+// FindPage retrieves a page of products in ID order, from the cache when possible
+func (r *ProductRepository) FindPage(ctx context.Context, after string, limit int) ([]*domain.Product, error) {
+	key := fmt.Sprintf("page:%d:%s", limit, after)
+	return r.get(ctx, "FindPage", key, func(ctx context.Context) ([]*domain.Product, error) {
+		return r.next.FindPage(ctx, after, limit)
+	})
+}
+
+// FindByCategories retrieves the products of any of the given categories, from the cache when possible
+func (r *ProductRepository) FindByCategories(ctx context.Context, categoryIDs []string) ([]*domain.Product, error) {
+	sorted := append([]string(nil), categoryIDs...)
+	sort.Strings(sorted)
+	key := "categories:" + strings.Join(sorted, ",")
+	return r.get(ctx, "FindByCategories", key, func(ctx context.Context) ([]*domain.Product, error) {
+		return r.next.FindByCategories(ctx, categoryIDs)
+	})
+}
+
+// Search retrieves the products matching the query, from the cache when possible
+func (r *ProductRepository) Search(ctx context.Context, query string) ([]*domain.Product, error) {
+	return r.get(ctx, "Search", "search:"+query, func(ctx context.Context) ([]*domain.Product, error) {
+		return r.next.Search(ctx, query)
+	})
+}
+
+// FindBySKU retrieves the product with a variant of the given SKU, from the cache when possible.
+// Like the lists, it is invalidated by every write, since any write can move a SKU.
+func (r *ProductRepository) FindBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	products, err := r.get(ctx, "FindBySKU", "sku:"+sku, func(ctx context.Context) ([]*domain.Product, error) {
+		product, err := r.next.FindBySKU(ctx, sku)
+		if err != nil {
+			return nil, err
+		}
+		return []*domain.Product{product}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products[0], nil
+}
+
+// FindDeletedByID retrieves a soft-deleted product by ID from the backend; the trash is not cached
+func (r *ProductRepository) FindDeletedByID(ctx context.Context, id string) (*domain.Product, error) {
+	return r.next.FindDeletedByID(ctx, id)
+}
+
+// FindDeleted retrieves the products soft-deleted before the given time from the backend
+func (r *ProductRepository) FindDeleted(ctx context.Context, before time.Time) ([]*domain.Product, error) {
+	return r.next.FindDeleted(ctx, before)
+}
+
+// get returns the cached result for key or loads it from the backend, recording the outcome
+// as the cache.hit span attribute and in the hit and miss counters
+func (r *ProductRepository) get(
+	ctx context.Context,
+	operation, key string,
+	load func(ctx context.Context) ([]*domain.Product, error),
+) ([]*domain.Product, error) {
+	ctx, span := r.tracer.Start(ctx, "ProductCache."+operation)
+	defer span.End()
+
+	attrs := metric.WithAttributes(
+		attribute.String("cache.name", "products"),
+		attribute.String("operation", operation),
+	)
+
+	products, generation, ok := r.lookup(ctx, key)
+	span.SetAttributes(attribute.Bool("cache.hit", ok))
+	if ok {
+		r.hits.Add(ctx, 1, attrs)
+		span.SetStatus(codes.Ok, "Cache hit")
+		return clone(products), nil
+	}
+	r.misses.Add(ctx, 1, attrs)
+
+	r.logger.DebugContext(ctx, "Product cache miss",
+		slog.String("operation", operation),
+		slog.String("key", key),
+	)
+
+	// Callers missing the same key in the same generation share one backend call.
+	// The call must outlive the caller that started it, so it keeps the caller's span
+	// but not its cancellation; each caller still stops waiting when its own context ends.
+	loadCtx := context.WithoutCancel(ctx)
+	results := r.group.DoChan(fmt.Sprintf("%d|%s", generation, key), func() (any, error) {
+		products, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		r.store(loadCtx, key, products, generation)
+		return products, nil
+	})
+
+	var result singleflight.Result
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		result = singleflight.Result{Err: ctx.Err()}
+	}
+	value, err := result.Val, result.Err
+	span.SetAttributes(attribute.Bool("cache.shared", result.Shared))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Backend query failed")
+		return nil, err
+	}
+
+	span.SetStatus(codes.Ok, "Cache miss")
+	return clone(value.([]*domain.Product)), nil
+}
+
+// lookup returns the live entry for key, with the current generation
+func (r *ProductRepository) lookup(ctx context.Context, key string) ([]*domain.Product, uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := r.entries[key]
+	if !ok {
+		return nil, r.generation, false
+	}
+
+	e := element.Value.(*entry)
+	switch {
+	case time.Now().After(e.expiresAt):
+		r.remove(ctx, element, "expired")
+		return nil, r.generation, false
+	case !strings.HasPrefix(key, "id:") && e.generation != r.generation:
+		r.remove(ctx, element, "stale")
+		return nil, r.generation, false
+	}
+
+	r.lru.MoveToFront(element)
+	return e.products, r.generation, true
+}
+
+// store caches a result loaded in the given generation, unless a write happened since
+func (r *ProductRepository) store(ctx context.Context, key string, products []*domain.Product, generation uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation != r.generation {
+		return
+	}
+
+	if element, ok := r.entries[key]; ok {
+		r.lru.Remove(element)
+		delete(r.entries, key)
+	}
+	r.entries[key] = r.lru.PushFront(&entry{
+		key:        key,
+		products:   clone(products),
+		generation: generation,
+		expiresAt:  time.Now().Add(r.ttl),
+	})
+
+	for r.lru.Len() > r.maxEntries {
+		r.remove(ctx, r.lru.Back(), "capacity")
+	}
+}
+
+// invalidate drops the cached products with the given IDs and starts a new generation,
+// making every cached list stale
+func (r *ProductRepository) invalidate(ids ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	for _, id := range ids {
+		if element, ok := r.entries["id:"+id]; ok {
+			r.lru.Remove(element)
+			delete(r.entries, "id:"+id)
+		}
+	}
+}
+
+// remove evicts an entry; callers hold the lock
+func (r *ProductRepository) remove(ctx context.Context, element *list.Element, reason string) {
+	r.lru.Remove(element)
+	delete(r.entries, element.Value.(*entry).key)
+	r.evictions.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("cache.name", "products"),
+		attribute.String("reason", reason),
+	))
+}
+
+// clone copies products so callers never share the cached ones
+func clone(products []*domain.Product) []*domain.Product {
+	clones := make([]*domain.Product, len(products))
+	for i, product := range products {
+		clones[i] = product.Clone()
+	}
+	return clones
+}
@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/repository/memory"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// countingRepository counts the queries that reach the backend; afterLoad, when set, runs after each one
+type countingRepository struct {
+	domain.ProductRepository
+	loads     atomic.Int64
+	afterLoad func()
+}
+
+func (r *countingRepository) loaded() {
+	r.loads.Add(1)
+	if r.afterLoad != nil {
+		r.afterLoad()
+	}
+}
+
+func (r *countingRepository) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	defer r.loaded()
+	return r.ProductRepository.FindByID(ctx, id)
+}
+
+func (r *countingRepository) FindAll(ctx context.Context) ([]*domain.Product, error) {
+	defer r.loaded()
+	return r.ProductRepository.FindAll(ctx)
+}
+
+func (r *countingRepository) FindBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	defer r.loaded()
+	return r.ProductRepository.FindBySKU(ctx, sku)
+}
+
+// newTestCache creates a cache in front of a memory repository holding the products named after names,
+// each with a variant whose SKU is its name
+func newTestCache(t *testing.T, names ...string) (*ProductRepository, *countingRepository, []*domain.Product) {
+	t.Helper()
+	backend := &countingRepository{ProductRepository: memory.NewProductRepository(memory.NewOutbox())}
+	products := make([]*domain.Product, len(names))
+	for i, name := range names {
+		product, err := domain.NewProduct(name, "", 1)
+		if err != nil {
+			t.Fatalf("NewProduct: %v", err)
+		}
+		product.Variants = []domain.Variant{{SKU: name}}
+		if err := backend.Create(context.Background(), product); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		products[i] = product
+	}
+	cache := NewProductRepository(backend, 100, time.Minute, tracenoop.NewTracerProvider().Tracer("test"),
+		metricnoop.NewMeterProvider().Meter("test"), slog.New(slog.DiscardHandler))
+	return cache, backend, products
+}
+
+// rename renames a stored product through the cache, reading it from the backend without counting a load
+func rename(ctx context.Context, t *testing.T, r *ProductRepository, id, name string) {
+	t.Helper()
+	product, err := r.next.(*countingRepository).ProductRepository.FindByID(ctx, id)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if err := product.Update(name, product.Description, product.Price, product.CategoryIDs); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := r.Update(ctx, product); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+}
+
+func TestProductRepositoryInvalidation(t *testing.T) {
+	tests := []struct {
+		name string
+		// query reads through the cache and returns the names it saw
+		query func(ctx context.Context, r *ProductRepository, products []*domain.Product) ([]string, error)
+		// write runs between two identical queries
+		write     func(ctx context.Context, t *testing.T, r *ProductRepository, products []*domain.Product)
+		wantLoads int64
+		wantNames []string
+	}{
+		{
+			name:      "list without a write is served from the cache",
+			query:     findAllNames,
+			write:     func(context.Context, *testing.T, *ProductRepository, []*domain.Product) {},
+			wantLoads: 1,
+			wantNames: []string{"alpha", "beta"},
+		},
+		{
+			name:  "list is reloaded after a create",
+			query: findAllNames,
+			write: func(ctx context.Context, t *testing.T, r *ProductRepository, _ []*domain.Product) {
+				product, err := domain.NewProduct("gamma", "", 1)
+				if err != nil {
+					t.Fatalf("NewProduct: %v", err)
+				}
+				if err := r.Create(ctx, product); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			},
+			wantLoads: 2,
+			wantNames: []string{"alpha", "beta", "gamma"},
+		},
+		{
+			name:  "list is reloaded after an update of any product",
+			query: findAllNames,
+			write: func(ctx context.Context, t *testing.T, r *ProductRepository, products []*domain.Product) {
+				rename(ctx, t, r, products[1].ID, "beta-2")
+			},
+			wantLoads: 2,
+			wantNames: []string{"alpha", "beta-2"},
+		},
+		{
+			name:  "updated product is reloaded by ID",
+			query: findByIDName(0),
+			write: func(ctx context.Context, t *testing.T, r *ProductRepository, products []*domain.Product) {
+				rename(ctx, t, r, products[0].ID, "alpha-2")
+			},
+			wantLoads: 2,
+			wantNames: []string{"alpha-2"},
+		},
+		{
+			name:  "other products stay cached by ID across a generation bump",
+			query: findByIDName(0),
+			write: func(ctx context.Context, t *testing.T, r *ProductRepository, products []*domain.Product) {
+				rename(ctx, t, r, products[1].ID, "beta-2")
+			},
+			wantLoads: 1,
+			wantNames: []string{"alpha"},
+		},
+		{
+			name: "SKU lookup is reloaded after a write to another product",
+			query: func(ctx context.Context, r *ProductRepository, _ []*domain.Product) ([]string, error) {
+				product, err := r.FindBySKU(ctx, "alpha")
+				if err != nil {
+					return nil, err
+				}
+				return []string{product.Name}, nil
+			},
+			write: func(ctx context.Context, t *testing.T, r *ProductRepository, products []*domain.Product) {
+				rename(ctx, t, r, products[1].ID, "beta-2")
+			},
+			wantLoads: 2,
+			wantNames: []string{"alpha"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache, backend, products := newTestCache(t, "alpha", "beta")
+
+			if _, err := tt.query(ctx, cache, products); err != nil {
+				t.Fatalf("first query: %v", err)
+			}
+			tt.write(ctx, t, cache, products)
+			names, err := tt.query(ctx, cache, products)
+			if err != nil {
+				t.Fatalf("second query: %v", err)
+			}
+
+			if loads := backend.loads.Load(); loads != tt.wantLoads {
+				t.Errorf("backend loads = %d, want %d", loads, tt.wantLoads)
+			}
+			if !sameNames(names, tt.wantNames) {
+				t.Errorf("names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestProductRepositoryDoesNotStoreResultsLoadedAcrossAWrite(t *testing.T) {
+	ctx := context.Background()
+	cache, backend, products := newTestCache(t, "alpha", "beta")
+
+	// The write lands after the backend query returned but before its result is stored.
+	// Entries by ID outlive generation bumps, so only the check on store keeps the old name out.
+	var written atomic.Bool
+	backend.afterLoad = func() {
+		if written.CompareAndSwap(false, true) {
+			rename(ctx, t, cache, products[0].ID, "alpha-2")
+		}
+	}
+
+	query := findByIDName(0)
+	if _, err := query(ctx, cache, products); err != nil {
+		t.Fatalf("first query: %v", err)
+	}
+	names, err := query(ctx, cache, products)
+	if err != nil {
+		t.Fatalf("second query: %v", err)
+	}
+
+	if loads := backend.loads.Load(); loads != 2 {
+		t.Errorf("backend loads = %d, want 2", loads)
+	}
+	if !sameNames(names, []string{"alpha-2"}) {
+		t.Errorf("names = %v, want [alpha-2]", names)
+	}
+}
+
+// findAllNames returns the names of all products
+func findAllNames(ctx context.Context, r *ProductRepository, _ []*domain.Product) ([]string, error) {
+	products, err := r.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(products))
+	for i, product := range products {
+		names[i] = product.Name
+	}
+	return names, nil
+}
+
+// findByIDName returns a query for the name of the i-th seeded product
+func findByIDName(i int) func(ctx context.Context, r *ProductRepository, products []*domain.Product) ([]string, error) {
+	return func(ctx context.Context, r *ProductRepository, products []*domain.Product) ([]string, error) {
+		product, err := r.FindByID(ctx, products[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		return []string{product.Name}, nil
+	}
+}
+
+// sameNames reports whether got and want hold the same names in any order
+func sameNames(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[string]int, len(got))
+	for _, name := range got {
+		seen[name]++
+	}
+	for _, name := range want {
+		if seen[name] == 0 {
+			return false
+		}
+		seen[name]--
+	}
+	return true
+}
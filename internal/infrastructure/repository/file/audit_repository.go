@@ -0,0 +1,178 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/repository/memory"
+)
+
+// auditRecord is one line of the audit file
+type auditRecord struct {
+	ID         string        `json:"id"`
+	ProductID  string        `json:"product_id"`
+	Operation  string        `json:"operation"`
+	Actor      string        `json:"actor"`
+	Changes    []auditChange `json:"changes"`
+	RequestID  string        `json:"request_id,omitempty"`
+	TraceID    string        `json:"trace_id,omitempty"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+type auditChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+func toAuditRecord(e *domain.AuditEntry) *auditRecord {
+	changes := make([]auditChange, len(e.Changes))
+	for i, c := range e.Changes {
+		changes[i] = auditChange{Field: c.Field, Before: c.Before, After: c.After}
+	}
+	return &auditRecord{
+		ID:         e.ID,
+		ProductID:  e.ProductID,
+		Operation:  string(e.Operation),
+		Actor:      e.Actor,
+		Changes:    changes,
+		RequestID:  e.RequestID,
+		TraceID:    e.TraceID,
+		OccurredAt: e.OccurredAt,
+	}
+}
+
+func (r *auditRecord) toAuditEntry() *domain.AuditEntry {
+	changes := make([]domain.AuditChange, len(r.Changes))
+	for i, c := range r.Changes {
+		changes[i] = domain.AuditChange{Field: c.Field, Before: c.Before, After: c.After}
+	}
+	return &domain.AuditEntry{
+		ID:         r.ID,
+		ProductID:  r.ProductID,
+		Operation:  domain.AuditOperation(r.Operation),
+		Actor:      r.Actor,
+		Changes:    changes,
+		RequestID:  r.RequestID,
+		TraceID:    r.TraceID,
+		OccurredAt: r.OccurredAt,
+	}
+}
+
+// AuditRepository is a file-backed implementation of domain.AuditRepository.
+// Entries are appended to a JSON lines file and synced before Append returns; queries are served
+// from an in-memory index loaded from the file on startup.
+type AuditRepository struct {
+	mu    sync.Mutex
+	file  *os.File
+	index *memory.AuditRepository
+}
+
+// NewAuditRepository opens or creates the audit file at path and loads the entries it holds
+func NewAuditRepository(ctx context.Context, path string, logger *slog.Logger) (*AuditRepository, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+
+	index := memory.NewAuditRepository()
+	if err := load(ctx, file, index, logger); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &AuditRepository{file: file, index: index}, nil
+}
+
+// load reads every entry of the audit file into the index.
+// A final line without its newline was cut short by a crash before Append returned, so it is
+// reported and truncated; any other line that fails to decode is an error.
+func load(ctx context.Context, file *os.File, index *memory.AuditRepository, logger *slog.Logger) error {
+	reader := bufio.NewReader(file)
+	var offset int64
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				logger.WarnContext(ctx, "Truncating incomplete audit file line",
+					slog.String("path", file.Name()),
+					slog.Int("line", lineNo),
+				)
+				if err := file.Truncate(offset); err != nil {
+					return fmt.Errorf("failed to truncate audit file: %w", err)
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read audit file: %w", err)
+		}
+		offset += int64(len(line))
+
+		var record auditRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("audit file line %d: %w", lineNo, err)
+		}
+		if err := index.Append(ctx, record.toAuditEntry()); err != nil {
+			return err
+		}
+	}
+}
+
+// Append writes new entries to the file and then adds them to the index
+func (r *AuditRepository) Append(ctx context.Context, entries ...*domain.AuditEntry) error {
+	var buf []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(toAuditRecord(entry))
+		if err != nil {
+			return fmt.Errorf("failed to encode audit entry: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return errors.New("audit file is closed")
+	}
+	if _, err := r.file.Write(buf); err != nil {
+		return fmt.Errorf("failed to append to audit file: %w", err)
+	}
+	if err := r.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit file: %w", err)
+	}
+	return r.index.Append(ctx, entries...)
+}
+
+// FindByProduct retrieves the entries of a product, oldest first
+func (r *AuditRepository) FindByProduct(ctx context.Context, productID string) ([]*domain.AuditEntry, error) {
+	return r.index.FindByProduct(ctx, productID)
+}
+
+// FindSince retrieves up to limit entries that occurred at or after since, oldest first
+func (r *AuditRepository) FindSince(ctx context.Context, since time.Time, limit int) ([]*domain.AuditEntry, error) {
+	return r.index.FindSince(ctx, since, limit)
+}
+
+// Close closes the audit file; later appends fail
+func (r *AuditRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
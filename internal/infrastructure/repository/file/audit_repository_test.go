@@ -0,0 +1,65 @@
+package file
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const auditLine = `{"id":"e1","product_id":"p1","operation":"create","actor":"alice","changes":[],"occurred_at":"2026-01-01T00:00:00Z"}` + "\n"
+
+func TestNewAuditRepositoryLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		entries int
+		size    int // size of the file after loading
+		wantErr bool
+	}{
+		{name: "complete lines", content: auditLine + auditLine, entries: 2, size: 2 * len(auditLine)},
+		{name: "torn final line", content: auditLine + auditLine[:40], entries: 1, size: len(auditLine)},
+		{name: "corrupt line in the middle", content: auditLine + "not json\n" + auditLine, wantErr: true},
+		{name: "empty file", content: "", entries: 0, size: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			repo, err := NewAuditRepository(ctx, path, slog.New(slog.DiscardHandler))
+			if tt.wantErr {
+				if err == nil {
+					repo.Close()
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewAuditRepository: %v", err)
+			}
+			defer repo.Close()
+
+			entries, err := repo.FindByProduct(ctx, "p1")
+			if err != nil {
+				t.Fatalf("FindByProduct: %v", err)
+			}
+			if len(entries) != tt.entries {
+				t.Errorf("loaded %d entries, want %d", len(entries), tt.entries)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Size() != int64(tt.size) {
+				t.Errorf("file size %d, want %d", info.Size(), tt.size)
+			}
+		})
+	}
+}
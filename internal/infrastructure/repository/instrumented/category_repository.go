@@ -0,0 +1,65 @@
+package instrumented
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CategoryRepository wraps any domain.CategoryRepository with tracing, metrics and logs, the same
+// way ProductRepository does, with CategoryRepository.<Method> client spans
+type CategoryRepository struct {
+	next   domain.CategoryRepository
+	client *client
+}
+
+// NewCategoryRepository instruments next; system names the backend, e.g. memory, in the db.system.name attribute
+func NewCategoryRepository(
+	next domain.CategoryRepository,
+	system string,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *CategoryRepository {
+	return &CategoryRepository{
+		next:   next,
+		client: newClient("CategoryRepository", "categories", system, tracer, meter, logger),
+	}
+}
+
+// Create stores a new category
+func (r *CategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	return execute(ctx, r.client, "Create", func(ctx context.Context) error {
+		return r.next.Create(ctx, category)
+	}, attribute.String("category.id", category.ID))
+}
+
+// FindByID retrieves a category by ID
+func (r *CategoryRepository) FindByID(ctx context.Context, id string) (*domain.Category, error) {
+	return observe(ctx, r.client, "FindByID", func(ctx context.Context) (*domain.Category, error) {
+		return r.next.FindByID(ctx, id)
+	}, attribute.String("category.id", id))
+}
+
+// FindAll retrieves all categories
+func (r *CategoryRepository) FindAll(ctx context.Context) ([]*domain.Category, error) {
+	return observe(ctx, r.client, "FindAll", r.next.FindAll)
+}
+
+// Update replaces an existing category
+func (r *CategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	return execute(ctx, r.client, "Update", func(ctx context.Context) error {
+		return r.next.Update(ctx, category)
+	}, attribute.String("category.id", category.ID))
+}
+
+// Delete removes a category by ID
+func (r *CategoryRepository) Delete(ctx context.Context, id string) error {
+	return execute(ctx, r.client, "Delete", func(ctx context.Context) error {
+		return r.next.Delete(ctx, id)
+	}, attribute.String("category.id", id))
+}
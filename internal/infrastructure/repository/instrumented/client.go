@@ -0,0 +1,159 @@
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// client traces, times and logs the calls to one collection of a storage backend, for its decorator
+type client struct {
+	repository string
+	collection string
+	system     string
+	tracer     trace.Tracer
+	logger     *slog.Logger
+	duration   metric.Float64Histogram
+}
+
+// newClient creates the instrumentation of a repository; spans are named <repository>.<Method>, and
+// collection and system fill the db.collection.name and db.system.name attributes
+func newClient(repository, collection, system string, tracer trace.Tracer, meter metric.Meter, logger *slog.Logger) *client {
+	// Initialize metrics
+	duration, _ := meter.Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of repository operations"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5),
+	)
+
+	return &client{
+		repository: repository,
+		collection: collection,
+		system:     system,
+		tracer:     tracer,
+		logger:     logger,
+		duration:   duration,
+	}
+}
+
+// observe runs one repository operation in a client span, recording its duration and outcome.
+// List results also set a <collection>.count span attribute, e.g. product.count.
+func observe[T any](
+	ctx context.Context,
+	c *client,
+	operation string,
+	fn func(ctx context.Context) (T, error),
+	attrs ...attribute.KeyValue,
+) (T, error) {
+	common := []attribute.KeyValue{
+		attribute.String("db.system.name", c.system),
+		attribute.String("db.collection.name", c.collection),
+		attribute.String("db.operation.name", operation),
+	}
+
+	ctx, span := c.tracer.Start(ctx, c.repository+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(common...),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	start := time.Now()
+	result, err := fn(ctx)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		if key, count, ok := resultCount(result); ok {
+			span.SetAttributes(attribute.Int(key, count))
+		}
+	}
+
+	if err != nil {
+		errorType := errorType(err)
+		c.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(
+			append(common, attribute.String("error.type", errorType))...,
+		))
+		span.SetAttributes(attribute.String("error.type", errorType))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		level := slog.LevelError
+		if errorType == "not_found" || errorType == "conflict" {
+			level = slog.LevelWarn
+		}
+		c.logger.Log(ctx, level, "Repository operation failed",
+			slog.String("repository", c.repository),
+			slog.String("operation", operation),
+			slog.String("error", err.Error()),
+			slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+		)
+		return result, err
+	}
+
+	c.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(common...))
+	span.SetStatus(codes.Ok, "")
+
+	c.logger.DebugContext(ctx, "Repository operation completed",
+		slog.String("repository", c.repository),
+		slog.String("operation", operation),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	)
+	return result, nil
+}
+
+// execute observes an operation that only returns an error
+func execute(ctx context.Context, c *client, operation string, fn func(ctx context.Context) error, attrs ...attribute.KeyValue) error {
+	_, err := observe(ctx, c, operation, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, attrs...)
+	return err
+}
+
+// resultCount returns the span attribute counting a list result
+func resultCount(result any) (string, int, bool) {
+	switch list := result.(type) {
+	case []*domain.Product:
+		return "product.count", len(list), true
+	case []*domain.Category:
+		return "category.count", len(list), true
+	case []*domain.Reservation:
+		return "reservation.count", len(list), true
+	case []*domain.WebhookSubscription:
+		return "webhook.count", len(list), true
+	case []*domain.WebhookDelivery:
+		return "webhook.delivery.count", len(list), true
+	default:
+		return "", 0, false
+	}
+}
+
+// errorType classifies an error for the error.type attribute, keeping its cardinality low
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound),
+		errors.Is(err, domain.ErrVariantNotFound),
+		errors.Is(err, domain.ErrCategoryNotFound),
+		errors.Is(err, domain.ErrReservationNotFound),
+		errors.Is(err, domain.ErrWebhookNotFound),
+		errors.Is(err, domain.ErrWebhookDeliveryNotFound):
+		return "not_found"
+	case errors.Is(err, domain.ErrDuplicateSKU),
+		errors.Is(err, domain.ErrProductConflict),
+		errors.Is(err, domain.ErrInsufficientStock),
+		errors.Is(err, domain.ErrInvalidStockLevel),
+		errors.Is(err, domain.ErrReservationNotPending):
+		return "conflict"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "canceled"
+	default:
+		return "internal"
+	}
+}
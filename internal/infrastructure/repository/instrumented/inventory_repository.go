@@ -0,0 +1,86 @@
+package instrumented
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InventoryRepository wraps any domain.InventoryRepository with tracing, metrics and logs, the same
+// way ProductRepository does, with InventoryRepository.<Method> client spans
+type InventoryRepository struct {
+	next   domain.InventoryRepository
+	client *client
+}
+
+// NewInventoryRepository instruments next; system names the backend, e.g. memory, in the db.system.name attribute
+func NewInventoryRepository(
+	next domain.InventoryRepository,
+	system string,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *InventoryRepository {
+	return &InventoryRepository{
+		next:   next,
+		client: newClient("InventoryRepository", "inventory", system, tracer, meter, logger),
+	}
+}
+
+// GetStock retrieves the stock level of a product
+func (r *InventoryRepository) GetStock(ctx context.Context, productID string) (*domain.Stock, error) {
+	return observe(ctx, r.client, "GetStock", func(ctx context.Context) (*domain.Stock, error) {
+		return r.next.GetStock(ctx, productID)
+	}, attribute.String("product.id", productID))
+}
+
+// SetOnHand replaces the on-hand quantity of a product
+func (r *InventoryRepository) SetOnHand(ctx context.Context, productID string, quantity int) (*domain.Stock, error) {
+	return observe(ctx, r.client, "SetOnHand", func(ctx context.Context) (*domain.Stock, error) {
+		return r.next.SetOnHand(ctx, productID, quantity)
+	}, attribute.String("product.id", productID), attribute.Int("inventory.on_hand", quantity))
+}
+
+// Reserve holds stock for a new pending reservation
+func (r *InventoryRepository) Reserve(ctx context.Context, reservation *domain.Reservation) (*domain.Stock, error) {
+	return observe(ctx, r.client, "Reserve", func(ctx context.Context) (*domain.Stock, error) {
+		return r.next.Reserve(ctx, reservation)
+	},
+		attribute.String("product.id", reservation.ProductID),
+		attribute.String("reservation.id", reservation.ID),
+		attribute.Int("reservation.quantity", reservation.Quantity),
+	)
+}
+
+// FindReservation retrieves a reservation by ID
+func (r *InventoryRepository) FindReservation(ctx context.Context, id string) (*domain.Reservation, error) {
+	return observe(ctx, r.client, "FindReservation", func(ctx context.Context) (*domain.Reservation, error) {
+		return r.next.FindReservation(ctx, id)
+	}, attribute.String("reservation.id", id))
+}
+
+// CompleteReservation confirms or releases a pending reservation
+func (r *InventoryRepository) CompleteReservation(ctx context.Context, id string, status domain.ReservationStatus) (*domain.Reservation, error) {
+	return observe(ctx, r.client, "CompleteReservation", func(ctx context.Context) (*domain.Reservation, error) {
+		return r.next.CompleteReservation(ctx, id, status)
+	}, attribute.String("reservation.id", id), attribute.String("reservation.status", string(status)))
+}
+
+// ExpireReservations expires every pending reservation whose TTL has elapsed
+func (r *InventoryRepository) ExpireReservations(ctx context.Context, now time.Time) ([]*domain.Reservation, error) {
+	return observe(ctx, r.client, "ExpireReservations", func(ctx context.Context) ([]*domain.Reservation, error) {
+		return r.next.ExpireReservations(ctx, now)
+	})
+}
+
+// PurgeReservations removes the reservations that stopped being pending before the given time
+func (r *InventoryRepository) PurgeReservations(ctx context.Context, before time.Time) (int, error) {
+	return observe(ctx, r.client, "PurgeReservations", func(ctx context.Context) (int, error) {
+		return r.next.PurgeReservations(ctx, before)
+	}, attribute.String("reservation.completed_before", before.Format(time.RFC3339)))
+}
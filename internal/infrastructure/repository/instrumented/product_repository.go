@@ -0,0 +1,117 @@
+package instrumented
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ProductRepository wraps any domain.ProductRepository with tracing, metrics and logs, so storage
+// implementations stay plain. Every call gets a ProductRepository.<Method> client span with the
+// database semantic convention attributes, its duration is recorded in db.client.operation.duration,
+// and failures are recorded on the span and logged.
+type ProductRepository struct {
+	next   domain.ProductRepository
+	client *client
+}
+
+// NewProductRepository instruments next; system names the backend, e.g. memory, in the db.system.name attribute
+func NewProductRepository(
+	next domain.ProductRepository,
+	system string,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *ProductRepository {
+	return &ProductRepository{
+		next:   next,
+		client: newClient("ProductRepository", "products", system, tracer, meter, logger),
+	}
+}
+
+// Create stores a new product
+func (r *ProductRepository) Create(ctx context.Context, product *domain.Product) error {
+	return execute(ctx, r.client, "Create", func(ctx context.Context) error {
+		return r.next.Create(ctx, product)
+	}, attribute.String("product.id", product.ID))
+}
+
+// CreateMany stores new products
+func (r *ProductRepository) CreateMany(ctx context.Context, products []*domain.Product) error {
+	return execute(ctx, r.client, "CreateMany", func(ctx context.Context) error {
+		return r.next.CreateMany(ctx, products)
+	}, attribute.Int("product.count", len(products)))
+}
+
+// Update replaces an existing product
+func (r *ProductRepository) Update(ctx context.Context, product *domain.Product) error {
+	return execute(ctx, r.client, "Update", func(ctx context.Context) error {
+		return r.next.Update(ctx, product)
+	}, attribute.String("product.id", product.ID))
+}
+
+// FindByID retrieves a product by ID
+func (r *ProductRepository) FindByID(ctx context.Context, id string) (*domain.Product, error) {
+	return observe(ctx, r.client, "FindByID", func(ctx context.Context) (*domain.Product, error) {
+		return r.next.FindByID(ctx, id)
+	}, attribute.String("product.id", id))
+}
+
+// FindAll retrieves all products
+func (r *ProductRepository) FindAll(ctx context.Context) ([]*domain.Product, error) {
+	return observe(ctx, r.client, "FindAll", r.next.FindAll)
+}
+
+// FindPage retrieves a page of products in ID order
+func (r *ProductRepository) FindPage(ctx context.Context, after string, limit int) ([]*domain.Product, error) {
+	return observe(ctx, r.client, "FindPage", func(ctx context.Context) ([]*domain.Product, error) {
+		return r.next.FindPage(ctx, after, limit)
+	}, attribute.String("page.after", after), attribute.Int("page.limit", limit))
+}
+
+// FindByCategories retrieves the products of any of the given categories
+func (r *ProductRepository) FindByCategories(ctx context.Context, categoryIDs []string) ([]*domain.Product, error) {
+	return observe(ctx, r.client, "FindByCategories", func(ctx context.Context) ([]*domain.Product, error) {
+		return r.next.FindByCategories(ctx, categoryIDs)
+	}, attribute.Int("category.count", len(categoryIDs)))
+}
+
+// Search retrieves the products matching the query
+func (r *ProductRepository) Search(ctx context.Context, query string) ([]*domain.Product, error) {
+	return observe(ctx, r.client, "Search", func(ctx context.Context) ([]*domain.Product, error) {
+		return r.next.Search(ctx, query)
+	}, attribute.String("product.search.query", query))
+}
+
+// FindBySKU retrieves the product with a variant of the given SKU
+func (r *ProductRepository) FindBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	return observe(ctx, r.client, "FindBySKU", func(ctx context.Context) (*domain.Product, error) {
+		return r.next.FindBySKU(ctx, sku)
+	}, attribute.String("product.variant.sku", sku))
+}
+
+// FindDeletedByID retrieves a soft-deleted product by ID
+func (r *ProductRepository) FindDeletedByID(ctx context.Context, id string) (*domain.Product, error) {
+	return observe(ctx, r.client, "FindDeletedByID", func(ctx context.Context) (*domain.Product, error) {
+		return r.next.FindDeletedByID(ctx, id)
+	}, attribute.String("product.id", id))
+}
+
+// FindDeleted retrieves the products soft-deleted before the given time
+func (r *ProductRepository) FindDeleted(ctx context.Context, before time.Time) ([]*domain.Product, error) {
+	return observe(ctx, r.client, "FindDeleted", func(ctx context.Context) ([]*domain.Product, error) {
+		return r.next.FindDeleted(ctx, before)
+	}, attribute.String("product.deleted_before", before.Format(time.RFC3339)))
+}
+
+// Purge permanently removes the products soft-deleted before the given time
+func (r *ProductRepository) Purge(ctx context.Context, before time.Time) ([]*domain.Product, error) {
+	return observe(ctx, r.client, "Purge", func(ctx context.Context) ([]*domain.Product, error) {
+		return r.next.Purge(ctx, before)
+	}, attribute.String("product.deleted_before", before.Format(time.RFC3339)))
+}
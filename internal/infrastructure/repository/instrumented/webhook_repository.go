@@ -0,0 +1,94 @@
+package instrumented
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WebhookRepository wraps any domain.WebhookRepository with tracing, metrics and logs, the same
+// way ProductRepository does, with WebhookRepository.<Method> client spans
+type WebhookRepository struct {
+	next   domain.WebhookRepository
+	client *client
+}
+
+// NewWebhookRepository instruments next; system names the backend, e.g. memory, in the db.system.name attribute
+func NewWebhookRepository(
+	next domain.WebhookRepository,
+	system string,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *WebhookRepository {
+	return &WebhookRepository{
+		next:   next,
+		client: newClient("WebhookRepository", "webhooks", system, tracer, meter, logger),
+	}
+}
+
+// CreateSubscription stores a new subscription
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	return execute(ctx, r.client, "CreateSubscription", func(ctx context.Context) error {
+		return r.next.CreateSubscription(ctx, subscription)
+	}, attribute.String("webhook.id", subscription.ID))
+}
+
+// FindSubscription retrieves a subscription by ID
+func (r *WebhookRepository) FindSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	return observe(ctx, r.client, "FindSubscription", func(ctx context.Context) (*domain.WebhookSubscription, error) {
+		return r.next.FindSubscription(ctx, id)
+	}, attribute.String("webhook.id", id))
+}
+
+// ListSubscriptions retrieves all subscriptions
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return observe(ctx, r.client, "ListSubscriptions", r.next.ListSubscriptions)
+}
+
+// DeleteSubscription removes a subscription
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	return execute(ctx, r.client, "DeleteSubscription", func(ctx context.Context) error {
+		return r.next.DeleteSubscription(ctx, id)
+	}, attribute.String("webhook.id", id))
+}
+
+// SaveDelivery creates or replaces a delivery
+func (r *WebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return execute(ctx, r.client, "SaveDelivery", func(ctx context.Context) error {
+		return r.next.SaveDelivery(ctx, delivery)
+	},
+		attribute.String("webhook.delivery.id", delivery.ID),
+		attribute.String("webhook.delivery.status", string(delivery.Status)),
+	)
+}
+
+// FindDelivery retrieves a delivery by ID
+func (r *WebhookRepository) FindDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	return observe(ctx, r.client, "FindDelivery", func(ctx context.Context) (*domain.WebhookDelivery, error) {
+		return r.next.FindDelivery(ctx, id)
+	}, attribute.String("webhook.delivery.id", id))
+}
+
+// DueDeliveries retrieves the pending deliveries whose next attempt is due.
+// The deliverer polls it every tick, so it is passed through without a span to keep idle polls out of traces.
+func (r *WebhookRepository) DueDeliveries(ctx context.Context, now time.Time, perSubscription int) ([]*domain.WebhookDelivery, error) {
+	return r.next.DueDeliveries(ctx, now, perSubscription)
+}
+
+// ListDeliveries retrieves the delivery log of a subscription
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string) ([]*domain.WebhookDelivery, error) {
+	return observe(ctx, r.client, "ListDeliveries", func(ctx context.Context) ([]*domain.WebhookDelivery, error) {
+		return r.next.ListDeliveries(ctx, subscriptionID)
+	}, attribute.String("webhook.id", subscriptionID))
+}
+
+// ListDeadLetters retrieves every delivery that exhausted its attempts
+func (r *WebhookRepository) ListDeadLetters(ctx context.Context) ([]*domain.WebhookDelivery, error) {
+	return observe(ctx, r.client, "ListDeadLetters", r.next.ListDeadLetters)
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// AuditRepository is an in-memory implementation of domain.AuditRepository.
+// Entries are never changed once appended, so they are shared with callers.
+type AuditRepository struct {
+	mu        sync.RWMutex
+	entries   []*domain.AuditEntry
+	byProduct map[string][]*domain.AuditEntry
+}
+
+// NewAuditRepository creates a new in-memory audit repository
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{
+		byProduct: make(map[string][]*domain.AuditEntry),
+	}
+}
+
+// Append stores new entries
+func (r *AuditRepository) Append(ctx context.Context, entries ...*domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range entries {
+		r.entries = append(r.entries, entry)
+		r.byProduct[entry.ProductID] = append(r.byProduct[entry.ProductID], entry)
+	}
+	return nil
+}
+
+// FindByProduct retrieves the entries of a product, oldest first
+func (r *AuditRepository) FindByProduct(ctx context.Context, productID string) ([]*domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]*domain.AuditEntry{}, r.byProduct[productID]...), nil
+}
+
+// FindSince retrieves up to limit entries that occurred at or after since, oldest first
+func (r *AuditRepository) FindSince(ctx context.Context, since time.Time, limit int) ([]*domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*domain.AuditEntry, 0)
+	for _, entry := range r.entries {
+		if len(entries) == limit {
+			break
+		}
+		if !entry.OccurredAt.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
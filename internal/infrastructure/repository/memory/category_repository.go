@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// CategoryRepository is an in-memory implementation of domain.CategoryRepository
+type CategoryRepository struct {
+	mu         sync.RWMutex
+	categories map[string]*domain.Category
+}
+
+// NewCategoryRepository creates a new in-memory category repository
+func NewCategoryRepository() *CategoryRepository {
+	return &CategoryRepository{
+		categories: make(map[string]*domain.Category),
+	}
+}
+
+// Create stores a new category
+func (r *CategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.categories[category.ID] = category
+	return nil
+}
+
+// FindByID retrieves a category by ID
+func (r *CategoryRepository) FindByID(ctx context.Context, id string) (*domain.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	category, exists := r.categories[id]
+	if !exists {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+// FindAll retrieves all categories
+func (r *CategoryRepository) FindAll(ctx context.Context) ([]*domain.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := make([]*domain.Category, 0, len(r.categories))
+	for _, category := range r.categories {
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
+
+// Update replaces an existing category
+func (r *CategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.categories[category.ID]; !exists {
+		return domain.ErrCategoryNotFound
+	}
+
+	r.categories[category.ID] = category
+	return nil
+}
+
+// Delete removes a category by ID
+func (r *CategoryRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.categories[id]; !exists {
+		return domain.ErrCategoryNotFound
+	}
+
+	delete(r.categories, id)
+	return nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// ImageRepository is an in-memory implementation of domain.ImageRepository.
+// It stores copies of images so callers can never mutate stored state without a write.
+type ImageRepository struct {
+	mu        sync.RWMutex
+	byProduct map[string][]*domain.Image
+}
+
+// NewImageRepository creates a new in-memory image repository
+func NewImageRepository() *ImageRepository {
+	return &ImageRepository{
+		byProduct: make(map[string][]*domain.Image),
+	}
+}
+
+// Replace replaces every image of a product and returns the ones it replaced
+func (r *ImageRepository) Replace(ctx context.Context, productID string, images []*domain.Image) ([]*domain.Image, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	replaced := r.byProduct[productID]
+	stored := make([]*domain.Image, len(images))
+	for i, image := range images {
+		copied := *image
+		stored[i] = &copied
+	}
+	r.byProduct[productID] = stored
+	return replaced, nil
+}
+
+// FindByProduct retrieves the images of a product in upload order
+func (r *ImageRepository) FindByProduct(ctx context.Context, productID string) ([]*domain.Image, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	images := make([]*domain.Image, len(r.byProduct[productID]))
+	for i, image := range r.byProduct[productID] {
+		copied := *image
+		images[i] = &copied
+	}
+	return images, nil
+}
+
+// FindByID retrieves an image of a product
+func (r *ImageRepository) FindByID(ctx context.Context, productID, id string) (*domain.Image, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, image := range r.byProduct[productID] {
+		if image.ID == id {
+			copied := *image
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrImageNotFound
+}
+
+// Update replaces a stored image
+func (r *ImageRepository) Update(ctx context.Context, image *domain.Image) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, stored := range r.byProduct[image.ProductID] {
+		if stored.ID == image.ID {
+			copied := *image
+			r.byProduct[image.ProductID][i] = &copied
+			return nil
+		}
+	}
+	return domain.ErrImageNotFound
+}
+
+// DeleteByProduct removes every image of a product and returns them
+func (r *ImageRepository) DeleteByProduct(ctx context.Context, productID string) ([]*domain.Image, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	images := r.byProduct[productID]
+	delete(r.byProduct, productID)
+	return images, nil
+}
@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// InventoryRepository is an in-memory implementation of domain.InventoryRepository.
+// A single mutex guards both stock levels and reservations, so every check-and-update
+// happens atomically and concurrent reservations can never oversell a product.
+type InventoryRepository struct {
+	mu           sync.Mutex
+	stock        map[string]*domain.Stock
+	reservations map[string]*domain.Reservation
+}
+
+// NewInventoryRepository creates a new in-memory inventory repository
+func NewInventoryRepository() *InventoryRepository {
+	return &InventoryRepository{
+		stock:        make(map[string]*domain.Stock),
+		reservations: make(map[string]*domain.Reservation),
+	}
+}
+
+// GetStock retrieves the stock level of a product, which is zero until first set
+func (r *InventoryRepository) GetStock(ctx context.Context, productID string) (*domain.Stock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stock := *r.stockFor(productID)
+	return &stock, nil
+}
+
+// SetOnHand replaces the on-hand quantity of a product
+func (r *InventoryRepository) SetOnHand(ctx context.Context, productID string, quantity int) (*domain.Stock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stock := r.stockFor(productID)
+	if err := stock.SetOnHand(quantity); err != nil {
+		return nil, err
+	}
+	r.stock[productID] = stock
+
+	result := *stock
+	return &result, nil
+}
+
+// Reserve atomically holds stock for a new pending reservation
+func (r *InventoryRepository) Reserve(ctx context.Context, reservation *domain.Reservation) (*domain.Stock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stock := r.stockFor(reservation.ProductID)
+	if err := stock.Reserve(reservation.Quantity); err != nil {
+		return nil, err
+	}
+	r.stock[reservation.ProductID] = stock
+
+	stored := *reservation
+	r.reservations[reservation.ID] = &stored
+
+	result := *stock
+	return &result, nil
+}
+
+// FindReservation retrieves a reservation by ID
+func (r *InventoryRepository) FindReservation(ctx context.Context, id string) (*domain.Reservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservation, exists := r.reservations[id]
+	if !exists {
+		return nil, domain.ErrReservationNotFound
+	}
+
+	result := *reservation
+	return &result, nil
+}
+
+// CompleteReservation atomically confirms or releases a pending reservation,
+// committing or freeing its reserved stock
+func (r *InventoryRepository) CompleteReservation(ctx context.Context, id string, status domain.ReservationStatus) (*domain.Reservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservation, exists := r.reservations[id]
+	if !exists {
+		return nil, domain.ErrReservationNotFound
+	}
+
+	// A reservation past its TTL is expired even if the worker has not swept it yet
+	if reservation.IsExpired(time.Now()) {
+		r.expire(reservation)
+	}
+
+	if err := reservation.Transition(status); err != nil {
+		return nil, err
+	}
+
+	stock := r.stockFor(reservation.ProductID)
+	if status == domain.ReservationConfirmed {
+		stock.Commit(reservation.Quantity)
+	} else {
+		stock.Free(reservation.Quantity)
+	}
+	r.stock[reservation.ProductID] = stock
+
+	result := *reservation
+	return &result, nil
+}
+
+// ExpireReservations expires every pending reservation whose TTL has elapsed
+func (r *InventoryRepository) ExpireReservations(ctx context.Context, now time.Time) ([]*domain.Reservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := make([]*domain.Reservation, 0)
+	for _, reservation := range r.reservations {
+		if reservation.IsExpired(now) {
+			r.expire(reservation)
+			result := *reservation
+			expired = append(expired, &result)
+		}
+	}
+	return expired, nil
+}
+
+// PurgeReservations removes the reservations that stopped being pending before the given time
+func (r *InventoryRepository) PurgeReservations(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := 0
+	for id, reservation := range r.reservations {
+		if reservation.Status != domain.ReservationPending && reservation.UpdatedAt.Before(before) {
+			delete(r.reservations, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// stockFor returns the stored stock of a product or a fresh zero record; callers must hold the lock
+func (r *InventoryRepository) stockFor(productID string) *domain.Stock {
+	if stock, exists := r.stock[productID]; exists {
+		return stock
+	}
+	return &domain.Stock{ProductID: productID, UpdatedAt: time.Now()}
+}
+
+// expire marks a pending reservation as expired and frees its stock; callers must hold the lock
+func (r *InventoryRepository) expire(reservation *domain.Reservation) {
+	if err := reservation.Transition(domain.ReservationExpired); err != nil {
+		return
+	}
+	stock := r.stockFor(reservation.ProductID)
+	stock.Free(reservation.Quantity)
+	r.stock[reservation.ProductID] = stock
+}
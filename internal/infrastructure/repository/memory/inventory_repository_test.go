@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+func TestInventoryRepositoryConcurrentReserve(t *testing.T) {
+	tests := []struct {
+		name     string
+		onHand   int
+		workers  int
+		quantity int
+	}{
+		{name: "more requests than stock", onHand: 10, workers: 50, quantity: 1},
+		{name: "quantities that do not divide the stock", onHand: 10, workers: 20, quantity: 3},
+		{name: "no stock", onHand: 0, workers: 10, quantity: 1},
+		{name: "enough stock for everyone", onHand: 100, workers: 20, quantity: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := NewInventoryRepository()
+			if _, err := repo.SetOnHand(ctx, "product-1", tt.onHand); err != nil {
+				t.Fatalf("SetOnHand: %v", err)
+			}
+
+			var (
+				wg       sync.WaitGroup
+				mu       sync.Mutex
+				reserved int
+				failed   error
+			)
+			start := make(chan struct{})
+			for range tt.workers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					reservation, err := domain.NewReservation("product-1", tt.quantity, time.Minute)
+					if err != nil {
+						t.Errorf("NewReservation: %v", err)
+						return
+					}
+					<-start
+					_, err = repo.Reserve(ctx, reservation)
+
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						reserved += tt.quantity
+					case !errors.Is(err, domain.ErrInsufficientStock):
+						failed = err
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			if failed != nil {
+				t.Fatalf("Reserve: %v, want nil or ErrInsufficientStock", failed)
+			}
+
+			want := min(tt.workers, tt.onHand/tt.quantity) * tt.quantity
+			if reserved != want {
+				t.Errorf("reserved %d, want %d", reserved, want)
+			}
+
+			stock, err := repo.GetStock(ctx, "product-1")
+			if err != nil {
+				t.Fatalf("GetStock: %v", err)
+			}
+			if stock.Reserved != reserved || stock.Available() < 0 {
+				t.Errorf("stock reserved %d available %d, want reserved %d and available >= 0",
+					stock.Reserved, stock.Available(), reserved)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Outbox is an in-memory implementation of domain.OutboxRepository.
+// The product repository appends to it while holding its own write lock, so an
+// event is stored if and only if the product write that raised it is stored.
+type Outbox struct {
+	mu       sync.Mutex
+	messages []*domain.OutboxMessage
+	notify   chan struct{}
+}
+
+// NewOutbox creates a new in-memory outbox
+func NewOutbox() *Outbox {
+	return &Outbox{
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// Notify returns a channel that receives a signal whenever new messages are appended
+func (o *Outbox) Notify() <-chan struct{} {
+	return o.notify
+}
+
+// append stores events together with the trace context of the producing request
+func (o *Outbox) append(ctx context.Context, events []domain.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	o.mu.Lock()
+	for _, event := range events {
+		o.messages = append(o.messages, &domain.OutboxMessage{
+			Event:        event,
+			TraceContext: carrier,
+			DeliveredTo:  make(map[string]bool),
+			CreatedAt:    time.Now(),
+		})
+	}
+	o.mu.Unlock()
+
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns up to limit undispatched messages due for an attempt, in the order they were written.
+// Messages waiting for a retry are skipped, so they never hold back newer ones.
+func (o *Outbox) Pending(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	pending := make([]*domain.OutboxMessage, 0, min(limit, len(o.messages)))
+	for _, message := range o.messages {
+		if len(pending) == limit {
+			break
+		}
+		if message.NextAttemptAt.After(now) {
+			continue
+		}
+		pending = append(pending, copyMessage(message))
+	}
+	return pending, nil
+}
+
+// MarkDelivered records that a publisher has received the event
+func (o *Outbox) MarkDelivered(ctx context.Context, eventID, publisher string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if message := o.find(eventID); message != nil {
+		message.DeliveredTo[publisher] = true
+	}
+	return nil
+}
+
+// RecordAttempt increments the delivery attempt count of a message and holds it back until next
+func (o *Outbox) RecordAttempt(ctx context.Context, eventID string, next time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if message := o.find(eventID); message != nil {
+		message.Attempts++
+		message.NextAttemptAt = next
+	}
+	return nil
+}
+
+// MarkDispatched removes a message from the outbox once it needs no further delivery
+func (o *Outbox) MarkDispatched(ctx context.Context, eventID string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, message := range o.messages {
+		if message.Event.ID == eventID {
+			o.messages = append(o.messages[:i], o.messages[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// find returns the stored message for an event; callers must hold the lock
+func (o *Outbox) find(eventID string) *domain.OutboxMessage {
+	for _, message := range o.messages {
+		if message.Event.ID == eventID {
+			return message
+		}
+	}
+	return nil
+}
+
+// copyMessage returns a copy of a message that is safe to use outside the lock
+func copyMessage(message *domain.OutboxMessage) *domain.OutboxMessage {
+	result := *message
+	result.DeliveredTo = make(map[string]bool, len(message.DeliveredTo))
+	for publisher, delivered := range message.DeliveredTo {
+		result.DeliveredTo[publisher] = delivered
+	}
+	return &result
+}
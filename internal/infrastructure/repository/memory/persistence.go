@@ -0,0 +1,563 @@
+package memory
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrCorrupted is returned when a snapshot or write-ahead log fails its checksum
+var ErrCorrupted = errors.New("persistence file is corrupted")
+
+const (
+	snapshotFile = "products.snapshot"
+	walFile      = "products.wal"
+	// snapshotMagic starts the header line of a snapshot, followed by the SHA-256 of the gzip payload
+	snapshotMagic = "PRODUCTS-SNAPSHOT-V1"
+)
+
+// Write-ahead log operations
+const (
+	walPut    = "put"
+	walDelete = "delete"
+)
+
+// walRecord is one line of the write-ahead log
+type walRecord struct {
+	Seq     uint64         `json:"seq"`
+	Op      string         `json:"op"`
+	Product *productRecord `json:"product,omitempty"`
+	ID      string         `json:"id,omitempty"`
+}
+
+// snapshot is the content of a snapshot file; Seq is the last write-ahead log record it includes
+type snapshot struct {
+	Seq      uint64           `json:"seq"`
+	TakenAt  time.Time        `json:"taken_at"`
+	Products []*productRecord `json:"products"`
+}
+
+// productRecord is the stored form of a product
+type productRecord struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Price       float64    `json:"price"`
+	CategoryIDs []string   `json:"category_ids,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	// PriceHistory is missing from records written before prices were tracked
+	PriceHistory []priceRecord   `json:"price_history,omitempty"`
+	Variants     []variantRecord `json:"variants,omitempty"`
+	Version      int             `json:"version,omitempty"`
+}
+
+type variantRecord struct {
+	SKU           string            `json:"sku"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	PriceOverride *float64          `json:"price_override,omitempty"`
+	Stock         int               `json:"stock"`
+}
+
+type priceRecord struct {
+	Price     float64   `json:"price"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+func toProductRecord(p *domain.Product) *productRecord {
+	prices := make([]priceRecord, len(p.PriceHistory))
+	for i, change := range p.PriceHistory {
+		prices[i] = priceRecord{Price: change.Price, ChangedAt: change.ChangedAt}
+	}
+	variants := make([]variantRecord, len(p.Variants))
+	for i, v := range p.Variants {
+		variants[i] = variantRecord{SKU: v.SKU, Attributes: v.Attributes, PriceOverride: v.PriceOverride, Stock: v.Stock}
+	}
+	return &productRecord{
+		ID:           p.ID,
+		Name:         p.Name,
+		Description:  p.Description,
+		Price:        p.Price,
+		CategoryIDs:  p.CategoryIDs,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+		DeletedAt:    p.DeletedAt,
+		PriceHistory: prices,
+		Variants:     variants,
+		Version:      p.Version,
+	}
+}
+
+func (r *productRecord) toProduct() *domain.Product {
+	var prices []domain.PriceChange
+	for _, change := range r.PriceHistory {
+		prices = append(prices, domain.PriceChange{Price: change.Price, ChangedAt: change.ChangedAt})
+	}
+	var variants []domain.Variant
+	for _, v := range r.Variants {
+		variants = append(variants, domain.Variant{SKU: v.SKU, Attributes: v.Attributes, PriceOverride: v.PriceOverride, Stock: v.Stock})
+	}
+	return &domain.Product{
+		ID:           r.ID,
+		Name:         r.Name,
+		Description:  r.Description,
+		Price:        r.Price,
+		CategoryIDs:  r.CategoryIDs,
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+		DeletedAt:    r.DeletedAt,
+		PriceHistory: prices,
+		Variants:     variants,
+		Version:      r.Version,
+	}
+}
+
+// Persistence keeps a ProductRepository across restarts with gzip'd JSON snapshots and an
+// append-only write-ahead log. The repository appends every write to the log, under its own
+// lock and before applying it; snapshots capture the whole repository and compact the log.
+// Snapshots carry a SHA-256 and log records a CRC-32, and a mismatch is reported as ErrCorrupted.
+type Persistence struct {
+	dir    string
+	sync   bool
+	repo   *ProductRepository
+	mu     sync.Mutex // guards wal and seq
+	wal    *os.File
+	seq    uint64
+	tracer trace.Tracer
+	logger *slog.Logger
+
+	// snapshotting serializes periodic and shutdown snapshots
+	snapshotting sync.Mutex
+
+	snapshotDuration metric.Float64Histogram
+	snapshotSize     metric.Int64Histogram
+	walAppends       metric.Int64Counter
+	corruptions      metric.Int64Counter
+}
+
+// NewPersistence creates the persistence of products in dir; with sync, every log append is fsynced
+func NewPersistence(dir string, sync bool, tracer trace.Tracer, meter metric.Meter, logger *slog.Logger) (*Persistence, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create persistence directory: %w", err)
+	}
+
+	// Initialize metrics
+	snapshotDuration, _ := meter.Float64Histogram(
+		"persistence.snapshot.duration",
+		metric.WithDescription("Duration of product snapshots"),
+		metric.WithUnit("s"),
+	)
+
+	snapshotSize, _ := meter.Int64Histogram(
+		"persistence.snapshot.size",
+		metric.WithDescription("Size of product snapshot files"),
+		metric.WithUnit("By"),
+	)
+
+	walAppends, _ := meter.Int64Counter(
+		"persistence.wal.appends",
+		metric.WithDescription("Total number of records appended to the write-ahead log"),
+	)
+
+	corruptions, _ := meter.Int64Counter(
+		"persistence.corruptions",
+		metric.WithDescription("Total number of corrupted persistence files detected"),
+	)
+
+	return &Persistence{
+		dir:              dir,
+		sync:             sync,
+		tracer:           tracer,
+		logger:           logger,
+		snapshotDuration: snapshotDuration,
+		snapshotSize:     snapshotSize,
+		walAppends:       walAppends,
+		corruptions:      corruptions,
+	}, nil
+}
+
+// Restore loads the latest snapshot into the repository, replays the write-ahead log on top of it
+// and starts logging the repository's writes. It must be called before the repository is used.
+func (p *Persistence) Restore(ctx context.Context, repo *ProductRepository) error {
+	ctx, span := p.tracer.Start(ctx, "Persistence.Restore")
+	defer span.End()
+
+	products := make(map[string]*domain.Product)
+
+	snap, err := p.readSnapshot(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to read snapshot")
+		return err
+	}
+	if snap != nil {
+		for _, record := range snap.Products {
+			products[record.ID] = record.toProduct()
+		}
+		p.seq = snap.Seq
+	}
+
+	replayed, err := p.replay(ctx, products)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to replay write-ahead log")
+		return err
+	}
+
+	wal, err := os.OpenFile(filepath.Join(p.dir, walFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		err = fmt.Errorf("failed to open write-ahead log: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to open write-ahead log")
+		return err
+	}
+	p.wal = wal
+	p.repo = repo
+	repo.restore(products, p)
+
+	span.SetAttributes(
+		attribute.Int("product.count", len(products)),
+		attribute.Int("persistence.wal.replayed", replayed),
+	)
+
+	p.logger.InfoContext(ctx, "Products restored from disk",
+		slog.String("dir", p.dir),
+		slog.Int("count", len(products)),
+		slog.Bool("snapshot", snap != nil),
+		slog.Int("wal_records_replayed", replayed),
+	)
+
+	span.SetStatus(codes.Ok, "Products restored")
+	return nil
+}
+
+// Snapshot writes the whole repository to a new snapshot file and drops the log records it includes.
+// The snapshot is written to a temporary file and renamed, so a crash never leaves a partial snapshot.
+func (p *Persistence) Snapshot(ctx context.Context) error {
+	ctx, span := p.tracer.Start(ctx, "Persistence.Snapshot")
+	defer span.End()
+
+	p.snapshotting.Lock()
+	defer p.snapshotting.Unlock()
+
+	start := time.Now()
+	snap := p.repo.snapshot()
+
+	var payload bytes.Buffer
+	gz := gzip.NewWriter(&payload)
+	if err := json.NewEncoder(gz).Encode(snap); err != nil {
+		return p.fail(ctx, span, "Failed to encode snapshot", err)
+	}
+	if err := gz.Close(); err != nil {
+		return p.fail(ctx, span, "Failed to encode snapshot", err)
+	}
+	digest := sha256.Sum256(payload.Bytes())
+
+	path := filepath.Join(p.dir, snapshotFile)
+	size, err := writeFileAtomic(path, func(w io.Writer) error {
+		if _, err := fmt.Fprintf(w, "%s %s\n", snapshotMagic, hex.EncodeToString(digest[:])); err != nil {
+			return err
+		}
+		_, err := w.Write(payload.Bytes())
+		return err
+	})
+	if err != nil {
+		return p.fail(ctx, span, "Failed to write snapshot", err)
+	}
+
+	if err := p.compact(snap.Seq); err != nil {
+		return p.fail(ctx, span, "Failed to compact write-ahead log", err)
+	}
+
+	duration := time.Since(start)
+	p.snapshotDuration.Record(ctx, duration.Seconds())
+	p.snapshotSize.Record(ctx, size)
+
+	span.SetAttributes(
+		attribute.Int("product.count", len(snap.Products)),
+		attribute.Int64("persistence.snapshot.size", size),
+		attribute.Int64("persistence.snapshot.seq", int64(snap.Seq)),
+	)
+
+	p.logger.InfoContext(ctx, "Product snapshot written",
+		slog.Int("count", len(snap.Products)),
+		slog.Int64("size_bytes", size),
+		slog.Int64("duration_ms", duration.Milliseconds()),
+	)
+
+	span.SetStatus(codes.Ok, "Snapshot written")
+	return nil
+}
+
+// Close closes the write-ahead log
+func (p *Persistence) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.wal == nil {
+		return nil
+	}
+	return p.wal.Close()
+}
+
+// append writes records for products to the log; the repository calls it under its write lock
+func (p *Persistence) append(ctx context.Context, op string, products ...*domain.Product) error {
+	if p == nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	seq := p.seq
+	for _, product := range products {
+		seq++
+		record := &walRecord{Seq: seq, Op: op}
+		if op == walDelete {
+			record.ID = product.ID
+		} else {
+			record.Product = toProductRecord(product)
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to encode write-ahead log record: %w", err)
+		}
+		fmt.Fprintf(&buf, "%08x %s\n", crc32.ChecksumIEEE(line), line)
+	}
+
+	if _, err := p.wal.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to append to write-ahead log: %w", err)
+	}
+	if p.sync {
+		if err := p.wal.Sync(); err != nil {
+			return fmt.Errorf("failed to sync write-ahead log: %w", err)
+		}
+	}
+	p.seq = seq
+
+	p.walAppends.Add(ctx, int64(len(products)), metric.WithAttributes(attribute.String("operation", op)))
+	return nil
+}
+
+// currentSeq returns the sequence number of the last logged record
+func (p *Persistence) currentSeq() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.seq
+}
+
+// readSnapshot reads and verifies the snapshot file; it returns nil when there is none
+func (p *Persistence) readSnapshot(ctx context.Context) (*snapshot, error) {
+	path := filepath.Join(p.dir, snapshotFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	header, payload, ok := bytes.Cut(data, []byte("\n"))
+	magic, checksum, _ := strings.Cut(string(header), " ")
+	if !ok || magic != snapshotMagic {
+		return nil, p.corrupted(ctx, path, "missing snapshot header")
+	}
+	digest := sha256.Sum256(payload)
+	if hex.EncodeToString(digest[:]) != checksum {
+		return nil, p.corrupted(ctx, path, "snapshot checksum mismatch")
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, p.corrupted(ctx, path, err.Error())
+	}
+	var snap snapshot
+	if err := json.NewDecoder(gz).Decode(&snap); err != nil {
+		return nil, p.corrupted(ctx, path, err.Error())
+	}
+	return &snap, nil
+}
+
+// replay applies the log records newer than the snapshot to products and returns how many it applied.
+// A final record without its newline was cut short by a crash before the write returned, so it is
+// reported and truncated; any other record failing its checksum is an error.
+func (p *Persistence) replay(ctx context.Context, products map[string]*domain.Product) (int, error) {
+	path := filepath.Join(p.dir, walFile)
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	replayed := 0
+	var offset int64
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				p.corruptions.Add(ctx, 1, metric.WithAttributes(attribute.String("file", walFile)))
+				p.logger.WarnContext(ctx, "Truncating incomplete write-ahead log record",
+					slog.String("path", path),
+					slog.Int("line", lineNo),
+				)
+				if err := os.Truncate(path, offset); err != nil {
+					return replayed, fmt.Errorf("failed to truncate write-ahead log: %w", err)
+				}
+			}
+			return replayed, nil
+		}
+		if err != nil {
+			return replayed, fmt.Errorf("failed to read write-ahead log: %w", err)
+		}
+		offset += int64(len(line))
+
+		checksum, body, _ := bytes.Cut(bytes.TrimSuffix(line, []byte("\n")), []byte(" "))
+		if fmt.Sprintf("%08x", crc32.ChecksumIEEE(body)) != string(checksum) {
+			return replayed, p.corrupted(ctx, path, fmt.Sprintf("checksum mismatch on line %d", lineNo))
+		}
+		var record walRecord
+		if err := json.Unmarshal(body, &record); err != nil {
+			return replayed, p.corrupted(ctx, path, fmt.Sprintf("line %d: %v", lineNo, err))
+		}
+		if record.Seq <= p.seq {
+			// Already included in the snapshot
+			continue
+		}
+
+		switch record.Op {
+		case walPut:
+			products[record.Product.ID] = record.Product.toProduct()
+		case walDelete:
+			delete(products, record.ID)
+		}
+		p.seq = record.Seq
+		replayed++
+	}
+}
+
+// compact rewrites the log without the records up to seq, which a snapshot now includes.
+// Appends wait meanwhile, so no record can be lost between the copy and the rename.
+func (p *Persistence) compact(seq uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	path := filepath.Join(p.dir, walFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var kept bytes.Buffer
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		_, body, _ := bytes.Cut(line, []byte(" "))
+		var record walRecord
+		if err := json.Unmarshal(body, &record); err != nil || record.Seq > seq {
+			kept.Write(line)
+		}
+	}
+
+	if _, err := writeFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(kept.Bytes())
+		return err
+	}); err != nil {
+		return err
+	}
+
+	wal, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	_ = p.wal.Close()
+	p.wal = wal
+	return nil
+}
+
+// corrupted reports a corrupted file and returns the error describing it
+func (p *Persistence) corrupted(ctx context.Context, path, reason string) error {
+	p.corruptions.Add(ctx, 1, metric.WithAttributes(attribute.String("file", filepath.Base(path))))
+	p.logger.ErrorContext(ctx, "Corrupted persistence file",
+		slog.String("path", path),
+		slog.String("reason", reason),
+	)
+	return fmt.Errorf("%w: %s: %s", ErrCorrupted, path, reason)
+}
+
+// fail records a failed snapshot on the span and in the logs
+func (p *Persistence) fail(ctx context.Context, span trace.Span, message string, err error) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, message)
+	p.logger.ErrorContext(ctx, message,
+		slog.String("error", err.Error()),
+	)
+	return err
+}
+
+// writeFileAtomic writes a file through a synced temporary file renamed over path, returning its size
+func writeFileAtomic(path string, write func(w io.Writer) error) (int64, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+	return info.Size(), syncDir(filepath.Dir(path))
+}
+
+// syncDir flushes a directory so that a rename in it survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,247 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// restoreFrom restores a fresh repository from dir
+func restoreFrom(t *testing.T, dir string) (*ProductRepository, *Persistence, error) {
+	t.Helper()
+	persistence, err := NewPersistence(dir, false, tracenoop.NewTracerProvider().Tracer("test"),
+		metricnoop.NewMeterProvider().Meter("test"), slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewPersistence: %v", err)
+	}
+	repo := NewProductRepository(NewOutbox())
+	if err := persistence.Restore(context.Background(), repo); err != nil {
+		return nil, nil, err
+	}
+	t.Cleanup(func() { persistence.Close() })
+	return repo, persistence, nil
+}
+
+// persistProducts stores products named after names in a repository persisted in dir
+func persistProducts(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	repo, persistence, err := restoreFrom(t, dir)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	for _, name := range names {
+		product, err := domain.NewProduct(name, "", 1)
+		if err != nil {
+			t.Fatalf("NewProduct: %v", err)
+		}
+		if err := repo.Create(context.Background(), product); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if err := persistence.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+// productNames returns the names of the stored products
+func productNames(t *testing.T, repo *ProductRepository) map[string]bool {
+	t.Helper()
+	products, err := repo.FindAll(context.Background())
+	if err != nil {
+		t.Fatalf("FindAll: %v", err)
+	}
+	names := make(map[string]bool, len(products))
+	for _, product := range products {
+		names[product.Name] = true
+	}
+	return names
+}
+
+func TestPersistenceReplay(t *testing.T) {
+	tests := []struct {
+		name      string
+		damage    func(wal []byte) []byte
+		want      []string
+		corrupted bool
+	}{
+		{
+			name:   "intact log",
+			damage: func(wal []byte) []byte { return wal },
+			want:   []string{"first", "second", "third"},
+		},
+		{
+			name: "torn final record",
+			damage: func(wal []byte) []byte {
+				last := bytes.LastIndexByte(wal[:len(wal)-1], '\n')
+				return wal[:last+1+(len(wal)-last-1)/2]
+			},
+			want: []string{"first", "second"},
+		},
+		{
+			name: "corrupt record before the end",
+			damage: func(wal []byte) []byte {
+				damaged := bytes.Clone(wal)
+				first := bytes.IndexByte(damaged, '\n')
+				damaged[first-2] ^= 0x01
+				return damaged
+			},
+			corrupted: true,
+		},
+		{
+			name: "corrupt final record",
+			damage: func(wal []byte) []byte {
+				damaged := bytes.Clone(wal)
+				damaged[len(damaged)-3] ^= 0x01
+				return damaged
+			},
+			corrupted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			persistProducts(t, dir, "first", "second", "third")
+
+			path := filepath.Join(dir, walFile)
+			wal, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			damaged := tt.damage(wal)
+			if err := os.WriteFile(path, damaged, 0o644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+
+			repo, _, err := restoreFrom(t, dir)
+			if tt.corrupted {
+				if !errors.Is(err, ErrCorrupted) {
+					t.Fatalf("Restore error = %v, want ErrCorrupted", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Restore: %v", err)
+			}
+
+			names := productNames(t, repo)
+			if len(names) != len(tt.want) {
+				t.Errorf("restored %v, want %v", names, tt.want)
+			}
+			for _, name := range tt.want {
+				if !names[name] {
+					t.Errorf("product %q was not restored", name)
+				}
+			}
+
+			// The torn record is gone, so the log ends on a complete line again
+			after, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if len(after) > 0 && after[len(after)-1] != '\n' {
+				t.Errorf("write-ahead log still ends with an incomplete record")
+			}
+		})
+	}
+}
+
+func TestPersistenceSnapshot(t *testing.T) {
+	tests := []struct {
+		name      string
+		damage    func(snapshot []byte) []byte
+		corrupted bool
+	}{
+		{
+			name:   "intact snapshot",
+			damage: func(snapshot []byte) []byte { return snapshot },
+		},
+		{
+			name: "payload changed",
+			damage: func(snapshot []byte) []byte {
+				damaged := bytes.Clone(snapshot)
+				damaged[len(damaged)-1] ^= 0x01
+				return damaged
+			},
+			corrupted: true,
+		},
+		{
+			name: "checksum changed",
+			damage: func(snapshot []byte) []byte {
+				damaged := bytes.Clone(snapshot)
+				damaged[len(snapshotMagic)+1] ^= 0x01
+				return damaged
+			},
+			corrupted: true,
+		},
+		{
+			name: "header missing",
+			damage: func(snapshot []byte) []byte {
+				_, payload, _ := bytes.Cut(snapshot, []byte("\n"))
+				return payload
+			},
+			corrupted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			persistProducts(t, dir, "first", "second")
+
+			repo, persistence, err := restoreFrom(t, dir)
+			if err != nil {
+				t.Fatalf("Restore: %v", err)
+			}
+			if err := persistence.Snapshot(context.Background()); err != nil {
+				t.Fatalf("Snapshot: %v", err)
+			}
+			product, err := domain.NewProduct("third", "", 1)
+			if err != nil {
+				t.Fatalf("NewProduct: %v", err)
+			}
+			if err := repo.Create(context.Background(), product); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if err := persistence.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			path := filepath.Join(dir, snapshotFile)
+			snapshot, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if err := os.WriteFile(path, tt.damage(snapshot), 0o644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+
+			restored, _, err := restoreFrom(t, dir)
+			if tt.corrupted {
+				if !errors.Is(err, ErrCorrupted) {
+					t.Fatalf("Restore error = %v, want ErrCorrupted", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Restore: %v", err)
+			}
+
+			// The snapshot holds the first two products and the compacted log only the third
+			names := productNames(t, restored)
+			for _, name := range []string{"first", "second", "third"} {
+				if !names[name] {
+					t.Errorf("product %q was not restored", name)
+				}
+			}
+		})
+	}
+}
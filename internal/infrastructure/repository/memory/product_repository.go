@@ -2,104 +2,324 @@ package memory
 
 import (
 	"context"
-	"log/slog"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/mrops-br/testing-otlp-api/internal/domain"
-	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
-	"go.opentelemetry.io/otel/trace"
 )
 
-// ProductRepository is an in-memory implementation of domain.ProductRepository
+// ProductRepository is an in-memory implementation of domain.ProductRepository.
+// It stores copies of products so callers can never mutate stored state without a write,
+// and appends pending domain events to the outbox under the same lock as the write.
+// With Persistence, every write is also appended to its write-ahead log before it is applied.
+// Variant SKUs are indexed, and checked for uniqueness under the same lock as the write.
+// Tracing, metrics and logs are added by wrapping it in instrumented.ProductRepository.
 type ProductRepository struct {
 	mu       sync.RWMutex
 	products map[string]*domain.Product
-	tracer   trace.Tracer
-	logger   *slog.Logger
+	skus     map[string]string // SKU to product ID
+	outbox   *Outbox
+	journal  *Persistence
 }
 
 // NewProductRepository creates a new in-memory product repository
-func NewProductRepository(tracer trace.Tracer, logger *slog.Logger) *ProductRepository {
+func NewProductRepository(outbox *Outbox) *ProductRepository {
 	return &ProductRepository{
 		products: make(map[string]*domain.Product),
-		tracer:   tracer,
-		logger:   logger,
+		skus:     make(map[string]string),
+		outbox:   outbox,
 	}
 }
 
 // Create stores a new product
 func (r *ProductRepository) Create(ctx context.Context, product *domain.Product) error {
-	ctx, span := r.tracer.Start(ctx, "ProductRepository.Create")
-	defer span.End()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkSKUs(product); err != nil {
+		return err
+	}
+
+	if err := r.journal.append(ctx, walPut, product); err != nil {
+		return err
+	}
+
+	r.put(product)
+	r.outbox.append(ctx, product.PullEvents())
+	return nil
+}
+
+// CreateMany stores new products under a single lock
+func (r *ProductRepository) CreateMany(ctx context.Context, products []*domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkSKUs(products...); err != nil {
+		return err
+	}
 
-	span.SetAttributes(
-		attribute.String("product.id", product.ID),
-		attribute.String("product.name", product.Name),
-	)
+	if err := r.journal.append(ctx, walPut, products...); err != nil {
+		return err
+	}
 
+	for _, product := range products {
+		r.put(product)
+		r.outbox.append(ctx, product.PullEvents())
+	}
+	return nil
+}
+
+// Update replaces an existing product, unless it was changed since the given copy was read
+func (r *ProductRepository) Update(ctx context.Context, product *domain.Product) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.products[product.ID] = product
+	stored, exists := r.products[product.ID]
+	if !exists {
+		return domain.ErrProductNotFound
+	}
+	if stored.Version != product.Version {
+		return domain.ErrProductConflict
+	}
+
+	if err := r.checkSKUs(product); err != nil {
+		return err
+	}
 
-	r.logger.InfoContext(ctx, "Product created in repository",
-		slog.String("product_id", product.ID),
-		slog.String("product_name", product.Name),
-	)
+	product.Version++
+	if err := r.journal.append(ctx, walPut, product); err != nil {
+		product.Version--
+		return err
+	}
 
-	span.SetStatus(codes.Ok, "Product created successfully")
+	r.put(product)
+	r.outbox.append(ctx, product.PullEvents())
 	return nil
 }
 
 // FindByID retrieves a product by ID
 func (r *ProductRepository) FindByID(ctx context.Context, id string) (*domain.Product, error) {
-	ctx, span := r.tracer.Start(ctx, "ProductRepository.FindByID")
-	defer span.End()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	span.SetAttributes(attribute.String("product.id", id))
+	product, exists := r.products[id]
+	if !exists || product.IsDeleted() {
+		return nil, domain.ErrProductNotFound
+	}
+	return product.Clone(), nil
+}
+
+// FindAll retrieves all products
+func (r *ProductRepository) FindAll(ctx context.Context) ([]*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := make([]*domain.Product, 0, len(r.products))
+	for _, product := range r.products {
+		if !product.IsDeleted() {
+			products = append(products, product.Clone())
+		}
+	}
+	return products, nil
+}
 
+// FindPage retrieves the next page of products in ID order, for keyset pagination
+func (r *ProductRepository) FindPage(ctx context.Context, after string, limit int) ([]*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.products))
+	for id, product := range r.products {
+		if id > after && !product.IsDeleted() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	products := make([]*domain.Product, len(ids))
+	for i, id := range ids {
+		products[i] = r.products[id].Clone()
+	}
+	return products, nil
+}
+
+// FindByCategories retrieves all products linked to any of the given categories
+func (r *ProductRepository) FindByCategories(ctx context.Context, categoryIDs []string) ([]*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := make([]*domain.Product, 0)
+	for _, product := range r.products {
+		if product.IsDeleted() {
+			continue
+		}
+		for _, categoryID := range categoryIDs {
+			if product.InCategory(categoryID) {
+				products = append(products, product.Clone())
+				break
+			}
+		}
+	}
+	return products, nil
+}
+
+// Search retrieves all products whose name or description contains the query, ignoring case
+func (r *ProductRepository) Search(ctx context.Context, query string) ([]*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	needle := strings.ToLower(query)
+	products := make([]*domain.Product, 0)
+	for _, product := range r.products {
+		if product.IsDeleted() {
+			continue
+		}
+		if strings.Contains(strings.ToLower(product.Name), needle) ||
+			strings.Contains(strings.ToLower(product.Description), needle) {
+			products = append(products, product.Clone())
+		}
+	}
+	return products, nil
+}
+
+// FindDeletedByID retrieves a soft-deleted product by ID
+func (r *ProductRepository) FindDeletedByID(ctx context.Context, id string) (*domain.Product, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	product, exists := r.products[id]
-	if !exists {
-		span.RecordError(domain.ErrProductNotFound)
-		span.SetStatus(codes.Error, "Product not found")
-		r.logger.WarnContext(ctx, "Product not found",
-			slog.String("product_id", id),
-		)
+	if !exists || !product.IsDeleted() {
 		return nil, domain.ErrProductNotFound
 	}
+	return product.Clone(), nil
+}
 
-	r.logger.DebugContext(ctx, "Product found in repository",
-		slog.String("product_id", id),
-		slog.String("product_name", product.Name),
-	)
+// FindDeleted retrieves the products soft-deleted before the given time, oldest deletion first
+func (r *ProductRepository) FindDeleted(ctx context.Context, before time.Time) ([]*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	span.SetStatus(codes.Ok, "Product found")
-	return product, nil
+	return r.deletedBefore(before), nil
 }
 
-// FindAll retrieves all products
-func (r *ProductRepository) FindAll(ctx context.Context) ([]*domain.Product, error) {
-	ctx, span := r.tracer.Start(ctx, "ProductRepository.FindAll")
-	defer span.End()
+// Purge removes the products soft-deleted before the given time under a single lock
+func (r *ProductRepository) Purge(ctx context.Context, before time.Time) ([]*domain.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := r.deletedBefore(before)
+	if len(purged) == 0 {
+		return purged, nil
+	}
+
+	if err := r.journal.append(ctx, walDelete, purged...); err != nil {
+		return nil, err
+	}
+
+	for _, product := range purged {
+		r.remove(product.ID)
+		product.MarkPurged()
+		r.outbox.append(ctx, product.PullEvents())
+	}
+	return purged, nil
+}
 
+// FindBySKU retrieves the product with a variant of the given SKU
+func (r *ProductRepository) FindBySKU(ctx context.Context, sku string) (*domain.Product, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	products := make([]*domain.Product, 0, len(r.products))
+	product, exists := r.products[r.skus[sku]]
+	if !exists || product.IsDeleted() {
+		return nil, domain.ErrVariantNotFound
+	}
+	return product.Clone(), nil
+}
+
+// checkSKUs returns domain.ErrDuplicateSKU if a variant SKU of the given products is used by a
+// stored product other than its own, or by another of the given products; callers hold the lock
+func (r *ProductRepository) checkSKUs(products ...*domain.Product) error {
+	claimed := make(map[string]string)
+	for _, product := range products {
+		for _, variant := range product.Variants {
+			if owner, exists := r.skus[variant.SKU]; exists && owner != product.ID {
+				return domain.ErrDuplicateSKU
+			}
+			if owner, exists := claimed[variant.SKU]; exists && owner != product.ID {
+				return domain.ErrDuplicateSKU
+			}
+			claimed[variant.SKU] = product.ID
+		}
+	}
+	return nil
+}
+
+// put stores a copy of the product and reindexes its SKUs; callers hold the lock
+func (r *ProductRepository) put(product *domain.Product) {
+	r.remove(product.ID)
+	r.products[product.ID] = product.Clone()
+	for _, variant := range product.Variants {
+		r.skus[variant.SKU] = product.ID
+	}
+}
+
+// remove deletes a product and releases its SKUs; callers hold the lock
+func (r *ProductRepository) remove(id string) {
+	if product, exists := r.products[id]; exists {
+		for _, variant := range product.Variants {
+			delete(r.skus, variant.SKU)
+		}
+	}
+	delete(r.products, id)
+}
+
+// deletedBefore copies the products soft-deleted before the given time, oldest deletion first;
+// callers hold the lock
+func (r *ProductRepository) deletedBefore(before time.Time) []*domain.Product {
+	products := make([]*domain.Product, 0)
 	for _, product := range r.products {
-		products = append(products, product)
+		if product.IsDeleted() && product.DeletedAt.Before(before) {
+			products = append(products, product.Clone())
+		}
 	}
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].DeletedAt.Before(*products[j].DeletedAt)
+	})
+	return products
+}
 
-	span.SetAttributes(attribute.Int("product.count", len(products)))
+// restore replaces the stored products with the ones loaded from disk and starts logging writes to journal
+func (r *ProductRepository) restore(products map[string]*domain.Product, journal *Persistence) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	r.logger.InfoContext(ctx, "Products retrieved from repository",
-		slog.Int("count", len(products)),
-	)
+	r.products = products
+	r.skus = make(map[string]string)
+	for _, product := range products {
+		for _, variant := range product.Variants {
+			r.skus[variant.SKU] = product.ID
+		}
+	}
+	r.journal = journal
+}
 
-	span.SetStatus(codes.Ok, "Products retrieved successfully")
-	return products, nil
+// snapshot copies every product together with the last write-ahead log record they include
+func (r *ProductRepository) snapshot() *snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snap := &snapshot{
+		Seq:      r.journal.currentSeq(),
+		TakenAt:  time.Now(),
+		Products: make([]*productRecord, 0, len(r.products)),
+	}
+	for _, product := range r.products {
+		snap.Products = append(snap.Products, toProductRecord(product))
+	}
+	return snap
 }
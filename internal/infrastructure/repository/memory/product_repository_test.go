@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+func TestProductRepositoryUpdateVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		// edit changes the copy read from the repository before it is written back
+		edit        func(ctx context.Context, t *testing.T, repo *ProductRepository, product *domain.Product)
+		wantErr     error
+		wantName    string
+		wantVersion int
+	}{
+		{
+			name:        "current version",
+			edit:        func(context.Context, *testing.T, *ProductRepository, *domain.Product) {},
+			wantName:    "updated",
+			wantVersion: 1,
+		},
+		{
+			name: "changed by another update since it was read",
+			edit: func(ctx context.Context, t *testing.T, repo *ProductRepository, product *domain.Product) {
+				other, err := repo.FindByID(ctx, product.ID)
+				if err != nil {
+					t.Fatalf("FindByID: %v", err)
+				}
+				other.Name = "other"
+				if err := repo.Update(ctx, other); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+			},
+			wantErr:     domain.ErrProductConflict,
+			wantName:    "other",
+			wantVersion: 1,
+		},
+		{
+			name: "version ahead of the stored one",
+			edit: func(_ context.Context, _ *testing.T, _ *ProductRepository, product *domain.Product) {
+				product.Version++
+			},
+			wantErr:     domain.ErrProductConflict,
+			wantName:    "original",
+			wantVersion: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := NewProductRepository(NewOutbox())
+			original, err := domain.NewProduct("original", "", 1)
+			if err != nil {
+				t.Fatalf("NewProduct: %v", err)
+			}
+			if err := repo.Create(ctx, original); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			product, err := repo.FindByID(ctx, original.ID)
+			if err != nil {
+				t.Fatalf("FindByID: %v", err)
+			}
+			tt.edit(ctx, t, repo, product)
+			product.Name = "updated"
+			readVersion := product.Version
+
+			err = repo.Update(ctx, product)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && product.Version != readVersion {
+				t.Errorf("rejected copy version = %d, want it left at %d", product.Version, readVersion)
+			}
+
+			stored, err := repo.FindByID(ctx, original.ID)
+			if err != nil {
+				t.Fatalf("FindByID: %v", err)
+			}
+			if stored.Name != tt.wantName || stored.Version != tt.wantVersion {
+				t.Errorf("stored name %q version %d, want %q version %d",
+					stored.Name, stored.Version, tt.wantName, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestProductRepositoryConcurrentUpdatesFromTheSameVersion(t *testing.T) {
+	ctx := context.Background()
+	repo := NewProductRepository(NewOutbox())
+	product, err := domain.NewProduct("original", "", 1)
+	if err != nil {
+		t.Fatalf("NewProduct: %v", err)
+	}
+	if err := repo.Create(ctx, product); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	const writers = 20
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		conflicts int
+	)
+	for range writers {
+		read, err := repo.FindByID(ctx, product.ID)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := repo.Update(ctx, read)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, domain.ErrProductConflict):
+				conflicts++
+			default:
+				t.Errorf("Update: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 || conflicts != writers-1 {
+		t.Errorf("%d updates succeeded and %d conflicted, want 1 and %d", succeeded, conflicts, writers-1)
+	}
+}
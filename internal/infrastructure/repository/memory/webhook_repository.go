@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+)
+
+// WebhookRepository is an in-memory implementation of domain.WebhookRepository
+type WebhookRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*domain.WebhookSubscription
+	deliveries    map[string]*domain.WebhookDelivery
+}
+
+// NewWebhookRepository creates a new in-memory webhook repository
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		subscriptions: make(map[string]*domain.WebhookSubscription),
+		deliveries:    make(map[string]*domain.WebhookDelivery),
+	}
+}
+
+// CreateSubscription stores a new subscription
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *subscription
+	r.subscriptions[subscription.ID] = &stored
+	return nil
+}
+
+// FindSubscription retrieves a subscription by ID
+func (r *WebhookRepository) FindSubscription(ctx context.Context, id string) (*domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, exists := r.subscriptions[id]
+	if !exists {
+		return nil, domain.ErrWebhookNotFound
+	}
+
+	result := *subscription
+	return &result, nil
+}
+
+// ListSubscriptions retrieves all subscriptions ordered by creation time
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscriptions := make([]*domain.WebhookSubscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		result := *subscription
+		subscriptions = append(subscriptions, &result)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions, nil
+}
+
+// DeleteSubscription removes a subscription; its delivery log is kept
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.subscriptions[id]; !exists {
+		return domain.ErrWebhookNotFound
+	}
+
+	delete(r.subscriptions, id)
+	return nil
+}
+
+// SaveDelivery creates or replaces a delivery
+func (r *WebhookRepository) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries[delivery.ID] = copyDelivery(delivery)
+	return nil
+}
+
+// FindDelivery retrieves a delivery by ID
+func (r *WebhookRepository) FindDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delivery, exists := r.deliveries[id]
+	if !exists {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+	return copyDelivery(delivery), nil
+}
+
+// DueDeliveries retrieves, for every subscription, up to perSubscription pending deliveries whose next
+// attempt is due, oldest first
+func (r *WebhookRepository) DueDeliveries(ctx context.Context, now time.Time, perSubscription int) ([]*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := make([]*domain.WebhookDelivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.Status == domain.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, copyDelivery(delivery))
+		}
+	}
+	sortDeliveries(due)
+
+	taken := make(map[string]int)
+	limited := due[:0]
+	for _, delivery := range due {
+		if taken[delivery.SubscriptionID] < perSubscription {
+			taken[delivery.SubscriptionID]++
+			limited = append(limited, delivery)
+		}
+	}
+	return limited, nil
+}
+
+// ListDeliveries retrieves the delivery log of a subscription, oldest first
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID string) ([]*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := make([]*domain.WebhookDelivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, copyDelivery(delivery))
+		}
+	}
+	sortDeliveries(deliveries)
+	return deliveries, nil
+}
+
+// ListDeadLetters retrieves every delivery that exhausted its attempts, oldest first
+func (r *WebhookRepository) ListDeadLetters(ctx context.Context) ([]*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deliveries := make([]*domain.WebhookDelivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.Status == domain.DeliveryDead {
+			deliveries = append(deliveries, copyDelivery(delivery))
+		}
+	}
+	sortDeliveries(deliveries)
+	return deliveries, nil
+}
+
+// copyDelivery returns a copy of a delivery that does not share its attempt log
+func copyDelivery(delivery *domain.WebhookDelivery) *domain.WebhookDelivery {
+	result := *delivery
+	result.Attempts = append([]domain.DeliveryAttempt(nil), delivery.Attempts...)
+	return &result
+}
+
+// sortDeliveries orders deliveries by creation time
+func sortDeliveries(deliveries []*domain.WebhookDelivery) {
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+}
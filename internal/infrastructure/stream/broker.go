@@ -0,0 +1,184 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/mrops-br/testing-otlp-api/internal/app/dto"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Message is a single server-sent event with a monotonically increasing ID
+type Message struct {
+	ID    uint64
+	Event string
+	Data  []byte
+}
+
+// Subscription receives live messages for one stream connection.
+// C is closed when the subscriber falls too far behind and must reconnect.
+type Subscription struct {
+	C      <-chan Message
+	ch     chan Message
+	closed bool
+}
+
+// Broker fans product events out to stream subscribers and keeps the most recent
+// messages in a ring buffer so reconnecting clients can resume from Last-Event-ID
+type Broker struct {
+	mu           sync.Mutex
+	history      []Message
+	historySize  int
+	nextID       uint64
+	clientBuffer int
+	subscribers  map[*Subscription]struct{}
+	closed       bool
+	logger       *slog.Logger
+	active       metric.Int64UpDownCounter
+	dropped      metric.Int64Counter
+}
+
+// NewBroker creates a new stream broker
+func NewBroker(historySize, clientBuffer int, meter metric.Meter, logger *slog.Logger) *Broker {
+	// Initialize metrics
+	active, _ := meter.Int64UpDownCounter(
+		"products.stream.active",
+		metric.WithDescription("Number of active product change streams"),
+		metric.WithUnit("{stream}"),
+	)
+
+	dropped, _ := meter.Int64Counter(
+		"products.stream.events.dropped",
+		metric.WithDescription("Total number of stream events dropped for slow subscribers"),
+	)
+
+	return &Broker{
+		history:      make([]Message, 0, historySize),
+		historySize:  historySize,
+		nextID:       1,
+		clientBuffer: clientBuffer,
+		subscribers:  make(map[*Subscription]struct{}),
+		logger:       logger,
+		active:       active,
+		dropped:      dropped,
+	}
+}
+
+// HandleEvent publishes a product domain event to every subscriber.
+// It is registered as a handler on the in-process event bus.
+func (b *Broker) HandleEvent(ctx context.Context, event domain.Event) error {
+	data, err := json.Marshal(dto.ToEventResponse(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	b.publish(ctx, string(event.Type), data)
+	return nil
+}
+
+// publish appends a message to the history and offers it to every subscriber.
+// Subscribers whose buffer is full are disconnected rather than blocking the broker;
+// they can resume from the history with Last-Event-ID.
+func (b *Broker) publish(ctx context.Context, eventType string, data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	message := Message{ID: b.nextID, Event: eventType, Data: data}
+	b.nextID++
+
+	if len(b.history) == b.historySize {
+		copy(b.history, b.history[1:])
+		b.history = b.history[:len(b.history)-1]
+	}
+	b.history = append(b.history, message)
+
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- message:
+		default:
+			b.dropped.Add(ctx, 1, metric.WithAttributes(attribute.String("event.type", eventType)))
+			b.logger.WarnContext(ctx, "Stream subscriber too slow, disconnecting",
+				slog.Uint64("event_id", message.ID),
+			)
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers a new subscriber and returns the buffered messages after lastID.
+// resync is true when messages after lastID have already left the history, or when lastID
+// was never assigned by this broker, such as an ID from before a restart.
+func (b *Broker) Subscribe(ctx context.Context, lastID uint64) (sub *Subscription, backlog []Message, resync bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Message, b.clientBuffer)
+	sub = &Subscription{C: ch, ch: ch}
+	b.subscribers[sub] = struct{}{}
+	b.active.Add(ctx, 1)
+
+	// Streams opened while the server shuts down end right away
+	if b.closed {
+		b.remove(sub)
+		return sub, nil, false
+	}
+
+	if lastID == 0 {
+		return sub, nil, false
+	}
+
+	if lastID >= b.nextID {
+		return sub, nil, true
+	}
+	if len(b.history) > 0 && b.history[0].ID > lastID+1 {
+		resync = true
+	}
+	for _, message := range b.history {
+		if message.ID > lastID {
+			backlog = append(backlog, message)
+		}
+	}
+	return sub, backlog, resync
+}
+
+// Unsubscribe removes a subscriber; it is safe to call after the broker disconnected it
+func (b *Broker) Unsubscribe(ctx context.Context, sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(sub)
+	b.active.Add(ctx, -1)
+}
+
+// Close disconnects every subscriber, so that open streams end and let the HTTP server shut down
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		b.remove(sub)
+	}
+}
+
+// Closed reports whether the broker was closed
+func (b *Broker) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// remove closes and forgets a subscriber; callers must hold the lock
+func (b *Broker) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subscribers, sub)
+	close(sub.ch)
+}
@@ -0,0 +1,175 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Components with a named logger whose level can be set apart from the global level
+const (
+	ComponentRepository = "repository"
+	ComponentService    = "service"
+	ComponentHTTP       = "http"
+)
+
+// ErrUnknownComponent is returned for a component without a named logger
+var ErrUnknownComponent = errors.New("unknown logger component, expected repository, service or http")
+
+// minLevel lets every record through the JSON handler, leaving the filtering to the levels
+const minLevel = slog.Level(math.MinInt)
+
+// LogLevels holds the global log level and the level overrides of the named component loggers.
+// Every change is logged, whatever the levels in force.
+type LogLevels struct {
+	global *slog.LevelVar
+
+	mu        sync.RWMutex
+	overrides map[string]slog.Level
+	logger    *slog.Logger
+}
+
+// newLogLevels creates log levels with the global level set to level and no overrides
+func newLogLevels(level slog.Level) *LogLevels {
+	global := new(slog.LevelVar)
+	global.Set(level)
+	return &LogLevels{
+		global:    global,
+		overrides: make(map[string]slog.Level),
+	}
+}
+
+// Components returns the names of the components that have their own logger
+func (l *LogLevels) Components() []string {
+	return []string{ComponentRepository, ComponentService, ComponentHTTP}
+}
+
+// Global returns the global log level
+func (l *LogLevels) Global() slog.Level {
+	return l.global.Level()
+}
+
+// Level returns the level in force for a component: its override, or the global level
+func (l *LogLevels) Level(component string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if level, ok := l.overrides[component]; ok {
+		return level
+	}
+	return l.global.Level()
+}
+
+// Overrides returns the components that have their own level, with that level
+func (l *LogLevels) Overrides() map[string]slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	overrides := make(map[string]slog.Level, len(l.overrides))
+	for component, level := range l.overrides {
+		overrides[component] = level
+	}
+	return overrides
+}
+
+// SetGlobal changes the global level; source says what changed it, such as reload or api
+func (l *LogLevels) SetGlobal(ctx context.Context, level slog.Level, source string) {
+	previous := l.global.Level()
+	l.global.Set(level)
+	l.audit(ctx, "", levelName(previous), levelName(level), source)
+}
+
+// SetComponent overrides the level of a component; a nil level removes the override,
+// so the component follows the global level again
+func (l *LogLevels) SetComponent(ctx context.Context, component string, level *slog.Level, source string) error {
+	if !slices.Contains(l.Components(), component) {
+		return ErrUnknownComponent
+	}
+
+	l.mu.Lock()
+	previous, had := l.overrides[component]
+	if level != nil {
+		l.overrides[component] = *level
+	} else {
+		delete(l.overrides, component)
+	}
+	l.mu.Unlock()
+
+	from, to := "global", "global"
+	if had {
+		from = levelName(previous)
+	}
+	if level != nil {
+		to = levelName(*level)
+	}
+	l.audit(ctx, component, from, to, source)
+	return nil
+}
+
+// audit logs a level change at no less than the levels in force, so it is never filtered out
+func (l *LogLevels) audit(ctx context.Context, component, from, to, source string) {
+	if l.logger == nil {
+		return
+	}
+
+	level := max(slog.LevelInfo, l.global.Level())
+	attrs := []any{
+		slog.String("from", from),
+		slog.String("to", to),
+		slog.String("source", source),
+	}
+	if component != "" {
+		level = max(level, l.Level(component))
+		attrs = append(attrs, slog.String("component", component))
+	}
+	l.logger.Log(ctx, level, "Log level changed", attrs...)
+}
+
+// levelName formats a level the way it is configured, e.g. "warn"
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// levelHandler filters records by the level in force for a component, "" for the global level
+type levelHandler struct {
+	handler   slog.Handler
+	levels    *LogLevels
+	component string
+}
+
+// Enabled reports whether records at level are logged for the component
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	if h.component == "" {
+		return level >= h.levels.Global()
+	}
+	return level >= h.levels.Level(h.component)
+}
+
+// Handle passes the record on
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a new handler with additional attributes
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{handler: h.handler.WithAttrs(attrs), levels: h.levels, component: h.component}
+}
+
+// WithGroup returns a new handler with the given group name
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{handler: h.handler.WithGroup(name), levels: h.levels, component: h.component}
+}
+
+// ComponentLogger returns the named child logger of a component, which logs at the level in force for it
+func (t *Telemetry) ComponentLogger(component string) *slog.Logger {
+	handler := t.Logger.Handler()
+	if h, ok := handler.(*levelHandler); ok {
+		handler = h.handler
+	}
+	return slog.New(&levelHandler{handler: handler, levels: t.LogLevels, component: component}).
+		With(slog.String("logger", component))
+}
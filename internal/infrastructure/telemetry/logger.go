@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/mrops-br/testing-otlp-api/internal/app/auth"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/config"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -37,7 +38,7 @@ func (h *traceContextHandler) Enabled(ctx context.Context, level slog.Level) boo
 	return h.handler.Enabled(ctx, level)
 }
 
-// Handle adds trace_id, span_id, and http.route to log records from the context
+// Handle adds trace_id, span_id, http.route, and enduser.id to log records from the context
 func (h *traceContextHandler) Handle(ctx context.Context, r slog.Record) error {
 	// Add trace context if available
 	span := trace.SpanFromContext(ctx)
@@ -53,6 +54,11 @@ func (h *traceContextHandler) Handle(ctx context.Context, r slog.Record) error {
 		r.AddAttrs(slog.String("http.route", route))
 	}
 
+	// Add the authenticated principal if available in context
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		r.AddAttrs(slog.String("enduser.id", principal.ID))
+	}
+
 	return h.handler.Handle(ctx, r)
 }
 
@@ -70,11 +76,11 @@ func (h *traceContextHandler) WithGroup(name string) slog.Handler {
 	}
 }
 
-// initLogger initializes a structured logger with trace context injection
-func initLogger(cfg *config.OTLPConfig) *slog.Logger {
-	// Create JSON handler for structured logging
+// initLogger initializes a structured logger with trace context injection, filtered by the global level of levels
+func initLogger(cfg *config.OTLPConfig, levels *LogLevels) *slog.Logger {
+	// Create JSON handler for structured logging; records are filtered by level before they reach it
 	opts := &slog.HandlerOptions{
-		Level: slog.LevelDebug,
+		Level: minLevel,
 	}
 
 	jsonHandler := slog.NewJSONHandler(os.Stdout, opts)
@@ -82,10 +88,11 @@ func initLogger(cfg *config.OTLPConfig) *slog.Logger {
 	// Wrap with trace context handler
 	handler := &traceContextHandler{handler: jsonHandler}
 
-	logger := slog.New(handler).With(
+	logger := slog.New(&levelHandler{handler: handler, levels: levels}).With(
 		slog.String("service.name", cfg.ServiceName),
 		slog.String("environment", cfg.Environment),
 	)
+	levels.logger = logger
 
 	return logger
 }
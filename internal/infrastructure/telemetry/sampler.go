@@ -0,0 +1,36 @@
+package telemetry
+
+import (
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// RatioSampler samples a ratio of new traces and follows the decision of the parent for child spans.
+// The ratio can be changed while spans are being started.
+type RatioSampler struct {
+	sampler atomic.Pointer[sdktrace.Sampler]
+}
+
+// NewRatioSampler creates a sampler for the given ratio, between 0 and 1
+func NewRatioSampler(ratio float64) *RatioSampler {
+	s := &RatioSampler{}
+	s.SetRatio(ratio)
+	return s
+}
+
+// SetRatio replaces the ratio of new traces that are sampled
+func (s *RatioSampler) SetRatio(ratio float64) {
+	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+	s.sampler.Store(&sampler)
+}
+
+// ShouldSample delegates to the sampler for the current ratio
+func (s *RatioSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return (*s.sampler.Load()).ShouldSample(p)
+}
+
+// Description describes the sampler for the current ratio
+func (s *RatioSampler) Description() string {
+	return (*s.sampler.Load()).Description()
+}
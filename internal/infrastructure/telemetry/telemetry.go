@@ -8,6 +8,7 @@ import (
 
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/config"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/metric"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
@@ -17,12 +18,17 @@ type Telemetry struct {
 	TracerProvider    *sdktrace.TracerProvider
 	MeterProvider     *metric.MeterProvider
 	Logger            *slog.Logger
+	// LogLevels are the global and per-component log levels; changes take effect immediately
+	LogLevels         *LogLevels
+	// Sampler decides which new traces are sampled; its ratio can be changed at runtime
+	Sampler           *RatioSampler
 }
 
 // NewTelemetry initializes all OpenTelemetry components
-func NewTelemetry(cfg *config.OTLPConfig) (*Telemetry, error) {
+func NewTelemetry(cfg *config.OTLPConfig, logCfg *config.LogConfig) (*Telemetry, error) {
 	// Initialize logger first for debugging
-	logger := initLogger(cfg)
+	levels := newLogLevels(logCfg.Level)
+	logger := initLogger(cfg, levels)
 
 	logger.Info("Initializing OpenTelemetry",
 		slog.String("endpoint", cfg.Endpoint),
@@ -30,13 +36,15 @@ func NewTelemetry(cfg *config.OTLPConfig) (*Telemetry, error) {
 	)
 
 	// Initialize tracer provider
-	tp, err := initTracerProvider(cfg)
+	sampler := NewRatioSampler(cfg.SamplerRatio)
+	tp, err := initTracerProvider(cfg, sampler)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize tracer provider: %w", err)
 	}
 
-	// Set global tracer provider
+	// Set global tracer provider and W3C trace context propagation
 	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(newPropagator())
 	logger.Info("Tracer provider initialized successfully")
 
 	// Initialize meter provider with DUAL exporters (OTLP + Prometheus)
@@ -53,21 +61,27 @@ func NewTelemetry(cfg *config.OTLPConfig) (*Telemetry, error) {
 		TracerProvider:    tp,
 		MeterProvider:     mp,
 		Logger:            logger,
+		LogLevels:         levels,
+		Sampler:           sampler,
 	}, nil
 }
 
 // NewNoOpTelemetry creates a telemetry instance with no-op providers (no export)
-func NewNoOpTelemetry(cfg *config.OTLPConfig) *Telemetry {
+func NewNoOpTelemetry(cfg *config.OTLPConfig, logCfg *config.LogConfig) *Telemetry {
 	// Create logger without trace context handler
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	})).With(
+	levels := newLogLevels(logCfg.Level)
+	logger := slog.New(&levelHandler{
+		handler: slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: minLevel}),
+		levels:  levels,
+	}).With(
 		slog.String("service.name", cfg.ServiceName),
 		slog.String("environment", cfg.Environment),
 	)
+	levels.logger = logger
 
 	// Create no-op tracer provider (doesn't export)
-	tp := sdktrace.NewTracerProvider()
+	sampler := NewRatioSampler(cfg.SamplerRatio)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSampler(sampler))
 
 	// Create no-op meter provider (doesn't export, but Prometheus metrics still work)
 	mp := metric.NewMeterProvider()
@@ -75,6 +89,7 @@ func NewNoOpTelemetry(cfg *config.OTLPConfig) *Telemetry {
 	// Set as global providers
 	otel.SetTracerProvider(tp)
 	otel.SetMeterProvider(mp)
+	otel.SetTextMapPropagator(newPropagator())
 
 	logger.Info("Telemetry initialized in no-op mode (export disabled)")
 
@@ -82,9 +97,20 @@ func NewNoOpTelemetry(cfg *config.OTLPConfig) *Telemetry {
 		TracerProvider: tp,
 		MeterProvider:  mp,
 		Logger:         logger,
+		LogLevels:      levels,
+		Sampler:        sampler,
 	}
 }
 
+// newPropagator returns the W3C trace context and baggage propagator used for
+// incoming requests, outgoing calls and trace context stored with events
+func newPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	)
+}
+
 // Shutdown gracefully shuts down all telemetry components
 func (t *Telemetry) Shutdown(ctx context.Context) error {
 	t.Logger.Info("Shutting down OpenTelemetry")
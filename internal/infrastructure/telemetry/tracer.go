@@ -14,7 +14,7 @@ import (
 )
 
 // initTracerProvider initializes the OpenTelemetry tracer provider
-func initTracerProvider(cfg *config.OTLPConfig) (*sdktrace.TracerProvider, error) {
+func initTracerProvider(cfg *config.OTLPConfig, sampler sdktrace.Sampler) (*sdktrace.TracerProvider, error) {
 	ctx := context.Background()
 
 	// Create OTLP trace exporter
@@ -46,7 +46,7 @@ func initTracerProvider(cfg *config.OTLPConfig) (*sdktrace.TracerProvider, error
 	tp := sdktrace.NewTracerProvider(
 		sdktrace.WithBatcher(exporter),
 		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSampler(sampler),
 	)
 
 	return tp, nil
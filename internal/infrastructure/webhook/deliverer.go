@@ -0,0 +1,315 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// SignatureHeader carries "t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">"
+	SignatureHeader = "X-Webhook-Signature"
+	// DeliveryHeader carries the delivery ID, which is stable across retries
+	DeliveryHeader = "X-Webhook-Delivery"
+	// EventHeader carries the event type
+	EventHeader = "X-Webhook-Event"
+)
+
+// Sign returns the signature header value for a payload sent at the given time
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// errPrivateAddress is returned for receivers that resolve to an address webhooks are not delivered to
+var errPrivateAddress = errors.New("webhook receiver resolved to a loopback, link-local or private address")
+
+// Deliverer sends queued webhook deliveries, retrying failures with exponential backoff
+// and moving deliveries that exhaust their attempts to the dead-letter list.
+// Each subscription has at most receiverConcurrency deliveries in flight, and deliveries
+// to different subscriptions are sent in parallel, so a slow receiver only delays itself.
+type Deliverer struct {
+	repo                domain.WebhookRepository
+	client              *http.Client
+	pollInterval        time.Duration
+	receiverConcurrency int
+	maxAttempts         int
+	backoffBase         time.Duration
+	backoffMax          time.Duration
+	tracer              trace.Tracer
+	logger              *slog.Logger
+	deliveries          metric.Int64Counter
+	duration            metric.Float64Histogram
+
+	mu       sync.Mutex
+	inFlight map[string]bool // IDs of the deliveries being sent
+	wg       sync.WaitGroup
+}
+
+// NewDeliverer creates a new webhook deliverer with an otelhttp-instrumented client.
+// Unless allowPrivateNetworks is set, the client refuses to connect to loopback, link-local and
+// private addresses, whatever name resolved to them.
+func NewDeliverer(
+	repo domain.WebhookRepository,
+	timeout time.Duration,
+	pollInterval time.Duration,
+	maxAttempts int,
+	backoffBase time.Duration,
+	backoffMax time.Duration,
+	receiverConcurrency int,
+	allowPrivateNetworks bool,
+	tracer trace.Tracer,
+	meter metric.Meter,
+	logger *slog.Logger,
+) *Deliverer {
+	// Initialize metrics
+	deliveries, _ := meter.Int64Counter(
+		"webhooks.delivery.attempts",
+		metric.WithDescription("Total number of webhook delivery attempts"),
+	)
+
+	duration, _ := meter.Float64Histogram(
+		"webhooks.delivery.duration",
+		metric.WithDescription("Duration of webhook delivery attempts"),
+		metric.WithUnit("s"),
+	)
+
+	return &Deliverer{
+		repo: repo,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: otelhttp.NewTransport(newTransport(allowPrivateNetworks)),
+		},
+		pollInterval:        pollInterval,
+		receiverConcurrency: receiverConcurrency,
+		maxAttempts:         maxAttempts,
+		backoffBase:         backoffBase,
+		backoffMax:          backoffMax,
+		tracer:              tracer,
+		logger:              logger,
+		deliveries:          deliveries,
+		duration:            duration,
+		inFlight:            make(map[string]bool),
+	}
+}
+
+// newTransport returns the default transport, guarded against private addresses unless they are allowed.
+// The check runs on the address actually dialled, so a name that resolves to a private address is caught too.
+func newTransport(allowPrivateNetworks bool) http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if allowPrivateNetworks {
+		return transport
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !domain.PublicAddr(addrPort.Addr()) {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport.DialContext = dialer.DialContext
+	// Connect to receivers directly, so the check applies to them rather than to a proxy
+	transport.Proxy = nil
+	return transport
+}
+
+// Run blocks, sending due deliveries on every poll until ctx is done
+func (d *Deliverer) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	d.logger.Info("Webhook deliverer started",
+		slog.String("interval", d.pollInterval.String()),
+		slog.Int("max_attempts", d.maxAttempts),
+		slog.Int("receiver_concurrency", d.receiverConcurrency),
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.wg.Wait()
+			d.logger.Info("Webhook deliverer stopped")
+			return
+		case <-ticker.C:
+			d.deliverDue(ctx)
+		}
+	}
+}
+
+// deliverDue starts sending the due deliveries that are not in flight yet, without waiting for them.
+// The repository returns the oldest receiverConcurrency due deliveries of each subscription, so a
+// subscription whose deliveries are all still in flight gets no new ones until one of them is done.
+func (d *Deliverer) deliverDue(ctx context.Context) {
+	due, err := d.repo.DueDeliveries(ctx, time.Now(), d.receiverConcurrency)
+	if err != nil {
+		d.logger.ErrorContext(ctx, "Failed to read due webhook deliveries",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, delivery := range due {
+		if d.inFlight[delivery.ID] {
+			continue
+		}
+		d.inFlight[delivery.ID] = true
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.deliver(ctx, delivery)
+
+			d.mu.Lock()
+			delete(d.inFlight, delivery.ID)
+			d.mu.Unlock()
+		}()
+	}
+}
+
+// deliver makes one attempt at a delivery, parented to the trace that queued it
+func (d *Deliverer) deliver(ctx context.Context, delivery *domain.WebhookDelivery) {
+	parent := otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(delivery.TraceContext))
+	attempt := len(delivery.Attempts) + 1
+
+	ctx, span := d.tracer.Start(parent, "WebhookDeliverer.Deliver",
+		trace.WithAttributes(
+			attribute.String("webhook.id", delivery.SubscriptionID),
+			attribute.String("webhook.delivery.id", delivery.ID),
+			attribute.String("event.id", delivery.EventID),
+			attribute.String("event.type", string(delivery.EventType)),
+			attribute.Int("webhook.delivery.attempt", attempt),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	record := domain.DeliveryAttempt{Attempt: attempt, AttemptedAt: start}
+
+	statusCode, err := d.send(ctx, delivery)
+	record.StatusCode = statusCode
+	record.Duration = time.Since(start)
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	delivery.RecordAttempt(record, err == nil, d.maxAttempts, d.backoff(attempt))
+	if saveErr := d.repo.SaveDelivery(ctx, delivery); saveErr != nil {
+		d.logger.ErrorContext(ctx, "Failed to store webhook delivery attempt",
+			slog.String("delivery_id", delivery.ID),
+			slog.String("error", saveErr.Error()),
+		)
+	}
+
+	span.SetAttributes(attribute.String("webhook.delivery.status", string(delivery.Status)))
+	d.deliveries.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("event.type", string(delivery.EventType)),
+		attribute.String("result", string(delivery.Status)),
+	))
+	d.duration.Record(ctx, record.Duration.Seconds(), metric.WithAttributes(
+		attribute.String("event.type", string(delivery.EventType)),
+	))
+
+	switch delivery.Status {
+	case domain.DeliverySucceeded:
+		span.SetStatus(codes.Ok, "Webhook delivered")
+		d.logger.InfoContext(ctx, "Webhook delivered",
+			slog.String("delivery_id", delivery.ID),
+			slog.Int("attempt", attempt),
+			slog.Int("status_code", statusCode),
+		)
+	case domain.DeliveryDead:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Webhook moved to dead-letter list")
+		d.logger.ErrorContext(ctx, "Webhook delivery dead-lettered after max attempts",
+			slog.String("delivery_id", delivery.ID),
+			slog.Int("attempt", attempt),
+			slog.String("error", err.Error()),
+		)
+	default:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Webhook delivery failed, will retry")
+		d.logger.WarnContext(ctx, "Webhook delivery failed, will retry",
+			slog.String("delivery_id", delivery.ID),
+			slog.Int("attempt", attempt),
+			slog.Time("next_attempt_at", delivery.NextAttemptAt),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// send posts the signed payload to the subscription URL and returns the response status code
+func (d *Deliverer) send(ctx context.Context, delivery *domain.WebhookDelivery) (int, error) {
+	subscription, err := d.repo.FindSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, time.Now(), delivery.Payload))
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(EventHeader, string(delivery.EventType))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the attempt following the given one
+func (d *Deliverer) backoff(attempt int) time.Duration {
+	delay := d.backoffBase
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= d.backoffMax {
+			return d.backoffMax
+		}
+	}
+	return delay
+}
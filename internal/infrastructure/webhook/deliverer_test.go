@@ -0,0 +1,337 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/repository/memory"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+const testSecret = "test-secret"
+
+// newTestDeliverer creates a deliverer allowed to reach httptest receivers on loopback
+func newTestDeliverer(maxAttempts int, backoffBase, backoffMax time.Duration, concurrency int) (*Deliverer, *memory.WebhookRepository) {
+	tracer := tracenoop.NewTracerProvider().Tracer("test")
+	logger := slog.New(slog.DiscardHandler)
+	repo := memory.NewWebhookRepository()
+	d := NewDeliverer(repo, 2*time.Second, time.Second, maxAttempts, backoffBase, backoffMax,
+		concurrency, true, tracer, metricnoop.NewMeterProvider().Meter("test"), logger)
+	return d, repo
+}
+
+// queue subscribes url and queues one delivery to it
+func queue(t *testing.T, repo *memory.WebhookRepository, url string, payload string) (*domain.WebhookSubscription, *domain.WebhookDelivery) {
+	t.Helper()
+	ctx := context.Background()
+
+	subscription, err := domain.NewWebhookSubscription(url, testSecret, nil)
+	if err != nil {
+		t.Fatalf("NewWebhookSubscription: %v", err)
+	}
+	if err := repo.CreateSubscription(ctx, subscription); err != nil {
+		t.Fatalf("CreateSubscription: %v", err)
+	}
+	delivery := queueTo(t, repo, subscription, payload)
+	return subscription, delivery
+}
+
+// queueTo queues one delivery to an existing subscription
+func queueTo(t *testing.T, repo *memory.WebhookRepository, subscription *domain.WebhookSubscription, payload string) *domain.WebhookDelivery {
+	t.Helper()
+	event := domain.Event{ID: "event-" + strconv.FormatInt(time.Now().UnixNano(), 10), Type: domain.EventProductCreated, OccurredAt: time.Now()}
+	delivery := domain.NewWebhookDelivery(subscription.ID, event, []byte(payload), nil)
+	if err := repo.SaveDelivery(context.Background(), delivery); err != nil {
+		t.Fatalf("SaveDelivery: %v", err)
+	}
+	return delivery
+}
+
+// poll runs one delivery round and waits for the deliveries it started
+func poll(d *Deliverer) {
+	d.deliverDue(context.Background())
+	d.wg.Wait()
+}
+
+func find(t *testing.T, repo *memory.WebhookRepository, id string) *domain.WebhookDelivery {
+	t.Helper()
+	delivery, err := repo.FindDelivery(context.Background(), id)
+	if err != nil {
+		t.Fatalf("FindDelivery: %v", err)
+	}
+	return delivery
+}
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1"}`)
+
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "t=1700000000,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := Sign(testSecret, timestamp, body); got != want {
+		t.Fatalf("Sign() = %q, want %q", got, want)
+	}
+	if Sign("other-secret", timestamp, body) == want {
+		t.Fatal("Sign() does not depend on the secret")
+	}
+}
+
+func TestDelivererSendsSignedRequest(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   string
+	}
+	requests := make(chan received, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header.Clone(), body: string(body)}
+	}))
+	defer receiver.Close()
+
+	d, repo := newTestDeliverer(3, time.Second, time.Minute, 2)
+	_, delivery := queue(t, repo, receiver.URL, `{"type":"product.created"}`)
+
+	poll(d)
+
+	req := <-requests
+	if req.body != `{"type":"product.created"}` {
+		t.Errorf("body = %q", req.body)
+	}
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := req.header.Get(DeliveryHeader); got != delivery.ID {
+		t.Errorf("%s = %q, want %q", DeliveryHeader, got, delivery.ID)
+	}
+	if got := req.header.Get(EventHeader); got != string(domain.EventProductCreated) {
+		t.Errorf("%s = %q", EventHeader, got)
+	}
+
+	// The signature must verify against the timestamp it carries
+	signature := req.header.Get(SignatureHeader)
+	ts, _, ok := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+	if !ok {
+		t.Fatalf("%s = %q, want t=<timestamp>,v1=<hmac>", SignatureHeader, signature)
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		t.Fatalf("signature timestamp %q: %v", ts, err)
+	}
+	if want := Sign(testSecret, time.Unix(unix, 0), []byte(req.body)); signature != want {
+		t.Errorf("%s = %q, want %q", SignatureHeader, signature, want)
+	}
+
+	stored := find(t, repo, delivery.ID)
+	if stored.Status != domain.DeliverySucceeded {
+		t.Errorf("status = %s, want %s", stored.Status, domain.DeliverySucceeded)
+	}
+	if len(stored.Attempts) != 1 || stored.Attempts[0].StatusCode != http.StatusOK || stored.Attempts[0].Error != "" {
+		t.Errorf("attempts = %+v, want one successful attempt", stored.Attempts)
+	}
+}
+
+func TestDelivererRetriesWithBackoff(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	d, repo := newTestDeliverer(5, time.Second, time.Minute, 2)
+	_, delivery := queue(t, repo, receiver.URL, `{}`)
+
+	poll(d)
+
+	stored := find(t, repo, delivery.ID)
+	if stored.Status != domain.DeliveryPending {
+		t.Fatalf("status = %s, want %s", stored.Status, domain.DeliveryPending)
+	}
+	attempt := stored.Attempts[0]
+	if attempt.StatusCode != http.StatusServiceUnavailable || attempt.Error == "" {
+		t.Errorf("attempt = %+v, want a failed 503 attempt", attempt)
+	}
+	if got := stored.NextAttemptAt.Sub(attempt.AttemptedAt); got != time.Second {
+		t.Errorf("next attempt after %s, want 1s", got)
+	}
+
+	// Not due yet, so the next round leaves it alone
+	poll(d)
+	if got := len(find(t, repo, delivery.ID).Attempts); got != 1 {
+		t.Errorf("attempts = %d before the backoff elapsed, want 1", got)
+	}
+}
+
+func TestDelivererBackoff(t *testing.T) {
+	d, _ := newTestDeliverer(10, time.Second, 5*time.Second, 1)
+
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 9: 5 * time.Second} {
+		if got := d.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestDelivererDeadLettersAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	d, repo := newTestDeliverer(3, time.Millisecond, time.Millisecond, 2)
+	subscription, delivery := queue(t, repo, receiver.URL, `{}`)
+
+	for range 5 {
+		poll(d)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if got := calls.Load(); got != 3 {
+		t.Errorf("receiver called %d times, want 3", got)
+	}
+
+	dead, err := repo.ListDeadLetters(context.Background())
+	if err != nil {
+		t.Fatalf("ListDeadLetters: %v", err)
+	}
+	if len(dead) != 1 || dead[0].ID != delivery.ID || dead[0].Status != domain.DeliveryDead {
+		t.Fatalf("dead letters = %+v, want the delivery", dead)
+	}
+
+	// The delivery log keeps every attempt, in order
+	log, err := repo.ListDeliveries(context.Background(), subscription.ID)
+	if err != nil {
+		t.Fatalf("ListDeliveries: %v", err)
+	}
+	if len(log) != 1 || len(log[0].Attempts) != 3 {
+		t.Fatalf("delivery log = %+v, want one delivery with 3 attempts", log)
+	}
+	for i, attempt := range log[0].Attempts {
+		if attempt.Attempt != i+1 || attempt.StatusCode != http.StatusInternalServerError || attempt.Error == "" {
+			t.Errorf("attempt %d = %+v", i+1, attempt)
+		}
+	}
+
+	// A requeued dead letter gets a fresh round of attempts
+	requeued := find(t, repo, delivery.ID)
+	if err := requeued.Requeue(); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	if err := repo.SaveDelivery(context.Background(), requeued); err != nil {
+		t.Fatalf("SaveDelivery: %v", err)
+	}
+	poll(d)
+	if got := calls.Load(); got != 4 {
+		t.Errorf("receiver called %d times after requeue, want 4", got)
+	}
+}
+
+func TestDelivererRefusesPrivateAddresses(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	tracer := tracenoop.NewTracerProvider().Tracer("test")
+	logger := slog.New(slog.DiscardHandler)
+	repo := memory.NewWebhookRepository()
+	d := NewDeliverer(repo, time.Second, time.Second, 3, time.Second, time.Minute,
+		2, false, tracer, metricnoop.NewMeterProvider().Meter("test"), logger)
+	_, delivery := queue(t, repo, receiver.URL, `{}`)
+
+	poll(d)
+
+	if calls.Load() != 0 {
+		t.Error("receiver on loopback was called")
+	}
+	stored := find(t, repo, delivery.ID)
+	if len(stored.Attempts) != 1 || !strings.Contains(stored.Attempts[0].Error, errPrivateAddress.Error()) {
+		t.Errorf("attempts = %+v, want one refused attempt", stored.Attempts)
+	}
+}
+
+func TestDelivererBoundsConcurrencyPerReceiver(t *testing.T) {
+	release := make(chan struct{})
+	var inFlight, peak atomic.Int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	d, repo := newTestDeliverer(3, time.Second, time.Minute, 2)
+	slowSubscription, _ := queue(t, repo, slow.URL, `{}`)
+	for range 4 {
+		queueTo(t, repo, slowSubscription, `{}`)
+	}
+	_, fastDelivery := queue(t, repo, fast.URL, `{}`)
+
+	ctx := context.Background()
+	d.deliverDue(ctx)
+
+	// The fast receiver is served while the slow one hangs
+	deadline := time.Now().Add(time.Second)
+	for find(t, repo, fastDelivery.ID).Status != domain.DeliverySucceeded {
+		if time.Now().After(deadline) {
+			t.Fatal("delivery to the fast receiver was held up by the slow one")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Further rounds start nothing new for the saturated receiver
+	d.deliverDue(ctx)
+	d.deliverDue(ctx)
+	time.Sleep(50 * time.Millisecond)
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak deliveries in flight to one receiver = %d, want 2", got)
+	}
+
+	close(release)
+	d.wg.Wait()
+}
+
+func TestTransportGuard(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	for _, allow := range []bool{true, false} {
+		client := &http.Client{Transport: newTransport(allow)}
+		resp, err := client.Get(receiver.URL)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if allow && err != nil {
+			t.Errorf("allowed: %v", err)
+		}
+		if !allow && !errors.Is(err, errPrivateAddress) {
+			t.Errorf("guarded: err = %v, want %v", err, errPrivateAddress)
+		}
+	}
+}
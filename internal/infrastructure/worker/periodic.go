@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Task is a unit of background work executed on every tick
+type Task func(ctx context.Context) error
+
+// Periodic runs a task at a fixed interval until its context is cancelled.
+// Each run gets its own root span so background work shows up as separate traces.
+type Periodic struct {
+	name     string
+	interval time.Duration
+	task     Task
+	tracer   trace.Tracer
+	logger   *slog.Logger
+}
+
+// NewPeriodic creates a new periodic background worker
+func NewPeriodic(name string, interval time.Duration, task Task, tracer trace.Tracer, logger *slog.Logger) *Periodic {
+	return &Periodic{
+		name:     name,
+		interval: interval,
+		task:     task,
+		tracer:   tracer,
+		logger:   logger,
+	}
+}
+
+// Run blocks, executing the task on every tick until ctx is done
+func (p *Periodic) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.logger.Info("Background worker started",
+		slog.String("worker", p.name),
+		slog.String("interval", p.interval.String()),
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Background worker stopped",
+				slog.String("worker", p.name),
+			)
+			return
+		case <-ticker.C:
+			p.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce executes a single run of the task inside a new root span
+func (p *Periodic) runOnce(ctx context.Context) {
+	ctx, span := p.tracer.Start(ctx, p.name,
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attribute.String("worker.name", p.name)),
+	)
+	defer span.End()
+
+	if err := p.task(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Background task failed")
+		p.logger.ErrorContext(ctx, "Background task failed",
+			slog.String("worker", p.name),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	span.SetStatus(codes.Ok, "Background task completed")
+}
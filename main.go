@@ -2,30 +2,73 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"log"
+	"log/slog"
+	nethttp "net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	appauth "github.com/mrops-br/testing-otlp-api/internal/app/auth"
 	"github.com/mrops-br/testing-otlp-api/internal/app/service"
+	"github.com/mrops-br/testing-otlp-api/internal/domain"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/auth"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/blob"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/config"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/events"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/graphql"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/grpc"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/handler"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/http/middleware"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/idempotency"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/ratelimit"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/reload"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/repository/cache"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/repository/file"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/repository/instrumented"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/repository/memory"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/stream"
 	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/telemetry"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/webhook"
+	"github.com/mrops-br/testing-otlp-api/internal/infrastructure/worker"
+	"go.opentelemetry.io/otel/metric"
 )
 
 func main() {
-	// Load configuration
-	cfg := config.LoadConfig()
+	// "config print" shows the effective configuration instead of starting the API
+	args := os.Args[1:]
+	printConfig := len(args) > 0 && args[0] == "config"
+	if printConfig {
+		if len(args) < 2 || args[1] != "print" {
+			log.Fatalf("Usage: %s config print [flags]", os.Args[0])
+		}
+		args = args[2:]
+	}
+
+	// Load configuration from the defaults, the config file, the environment and the flags
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	if printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+		return
+	}
 
 	// Initialize OpenTelemetry (if enabled)
 	var telem *telemetry.Telemetry
-	var err error
 
 	if cfg.OTLP.Enabled {
-		telem, err = telemetry.NewTelemetry(&cfg.OTLP)
+		telem, err = telemetry.NewTelemetry(&cfg.OTLP, &cfg.Log)
 		if err != nil {
 			log.Fatalf("Failed to initialize telemetry: %v", err)
 		}
@@ -40,7 +83,7 @@ func main() {
 		}()
 	} else {
 		// Create minimal telemetry without OTLP exporters
-		telem = telemetry.NewNoOpTelemetry(&cfg.OTLP)
+		telem = telemetry.NewNoOpTelemetry(&cfg.OTLP, &cfg.Log)
 		log.Println("OpenTelemetry export disabled (OTEL_ENABLED=false)")
 	}
 
@@ -53,29 +96,245 @@ func main() {
 	meter := telem.MeterProvider.Meter("products-api")
 	logger := telem.Logger
 
+	// Named child loggers whose levels can be changed apart from the global level
+	repositoryLogger := telem.ComponentLogger(telemetry.ComponentRepository)
+	serviceLogger := telem.ComponentLogger(telemetry.ComponentService)
+	httpLogger := telem.ComponentLogger(telemetry.ComponentHTTP)
+
 	logger.Info("Starting Products API")
 
-	// Initialize repository (dependency injection)
-	repo := memory.NewProductRepository(tracer, logger)
+	// Initialize repositories (dependency injection)
+	// Product writes append their domain events to the outbox in the same write
+	outbox := memory.NewOutbox()
+	repo := memory.NewProductRepository(outbox)
+
+	// Keep products across restarts with snapshots and a write-ahead log
+	var persistence *memory.Persistence
+	if cfg.Persistence.Dir != "" {
+		persistence, err = memory.NewPersistence(cfg.Persistence.Dir, cfg.Persistence.SyncWrites, tracer, meter, repositoryLogger)
+		if err != nil {
+			log.Fatalf("Failed to initialize persistence: %v", err)
+		}
+		if err := persistence.Restore(ctx, repo); err != nil {
+			log.Fatalf("Failed to restore products: %v", err)
+		}
+		defer persistence.Close()
+	}
+
+	// Keep the audit trail of product mutations in a file when configured, otherwise in memory
+	var auditRepo domain.AuditRepository = memory.NewAuditRepository()
+	if cfg.Audit.FilePath != "" {
+		auditFile, err := file.NewAuditRepository(ctx, cfg.Audit.FilePath, repositoryLogger)
+		if err != nil {
+			log.Fatalf("Failed to initialize audit trail: %v", err)
+		}
+		defer auditFile.Close()
+		auditRepo = auditFile
+	}
+
+	// Trace, time and log every call to the storage backend
+	var products domain.ProductRepository = instrumented.NewProductRepository(repo, "memory", tracer, meter, repositoryLogger)
+	categoryRepo := instrumented.NewCategoryRepository(memory.NewCategoryRepository(), "memory", tracer, meter, repositoryLogger)
+	inventoryRepo := instrumented.NewInventoryRepository(memory.NewInventoryRepository(), "memory", tracer, meter, repositoryLogger)
+	webhookRepo := instrumented.NewWebhookRepository(memory.NewWebhookRepository(), "memory", tracer, meter, repositoryLogger)
 
-	// Initialize service
-	productService := service.NewProductService(repo, tracer, meter, logger)
+	// Serve product reads from a read-through cache, invalidated by writes
+	if cfg.Cache.Enabled {
+		products = cache.NewProductRepository(products, cfg.Cache.MaxEntries, cfg.Cache.TTL, tracer, meter, repositoryLogger)
+	}
 
-	// Initialize handler
-	productHandler := handler.NewProductHandler(productService, logger)
+	// Answer routes switched off in the configuration with 503, before spending any work on them
+	routeToggles := middleware.NewRouteToggles(cfg.Server.DisabledRoutes)
+	guards := []func(nethttp.Handler) nethttp.Handler{middleware.RouteToggle(routeToggles, meter, httpLogger)}
+
+	// Configured API keys authenticate callers and identify them to the rate limiter
+	var apiKeys *auth.APIKeyAuthenticator
+	if cfg.Auth.Enabled && (cfg.Auth.APIKeys != "" || cfg.Auth.APIKeysFile != "") {
+		apiKeys, err = auth.NewAPIKeyAuthenticator(cfg.Auth.APIKeys, cfg.Auth.APIKeysFile)
+		if err != nil {
+			log.Fatalf("Failed to initialize authentication: %v", err)
+		}
+	}
+
+	// Limit each client to a token bucket per route, before spending any work on authentication
+	var rateLimits *ratelimit.LiveRules
+	if cfg.RateLimit.Enabled {
+		rules, err := ratelimit.ParseRules(cfg.RateLimit.Rate, cfg.RateLimit.Burst, cfg.RateLimit.Routes)
+		if err != nil {
+			log.Fatalf("Failed to initialize rate limiting: %v", err)
+		}
+		rateLimits = ratelimit.NewLiveRules(rules)
+		key, err := middleware.RateLimitKey(cfg.RateLimit.Key, apiKeys)
+		if err != nil {
+			log.Fatalf("Failed to initialize rate limiting: %v", err)
+		}
+		guards = append(guards, middleware.RateLimit(ratelimit.NewMemoryLimiter(), rateLimits, key,
+			cfg.RateLimit.ExemptPaths, meter, httpLogger))
+	}
+
+	// Authenticate callers with static API keys and/or JWTs verified against a JWKS
+	var authenticator auth.Authenticator
+	if cfg.Auth.Enabled {
+		authenticator, err = newAuthenticator(ctx, &cfg.Auth, apiKeys, cfg.Authz.RolesClaim, meter, logger)
+		if err != nil {
+			log.Fatalf("Failed to initialize authentication: %v", err)
+		}
+		guards = append(guards, middleware.Authentication(authenticator, cfg.Auth.ExemptPaths, httpLogger))
+	}
+
+	// Enforce the role policy per route and per product service operation
+	var authorizer *appauth.Authorizer
+	if cfg.Authz.Enabled {
+		policy, err := appauth.NewPolicy(cfg.Authz.RoleBindings, cfg.Authz.DefaultRole, cfg.Authz.RoutePolicy, cfg.Authz.ServicePolicy)
+		if err != nil {
+			log.Fatalf("Failed to initialize authorization policy: %v", err)
+		}
+		authorizer = appauth.NewAuthorizer(policy, meter, logger)
+		guards = append(guards, middleware.Authorization(authorizer))
+	}
+
+	// Initialize services, each wrapped in its instrumented decorator that traces, counts and logs its operations
+	categoryLock := service.NewCategoryLock()
+	auditRecorder := service.NewAuditService(auditRepo, authorizer, tracer, meter, serviceLogger)
+	auditService := service.NewInstrumentedAuditService(auditRecorder, tracer, meter, serviceLogger)
+	productService := service.NewInstrumentedProductService(
+		service.NewProductService(products, categoryRepo, categoryLock, authorizer, cfg.Trash.Retention, auditRecorder, tracer, serviceLogger),
+		tracer, meter, serviceLogger)
+	categoryService := service.NewInstrumentedCategoryService(
+		service.NewCategoryService(categoryRepo, products, categoryLock, serviceLogger),
+		tracer, meter, serviceLogger)
+	inventoryService := service.NewInstrumentedInventoryService(
+		service.NewInventoryService(inventoryRepo, products, cfg.Inventory.ReservationTTL,
+			cfg.Inventory.MaxReservationTTL, cfg.Inventory.ReservationRetention, meter, serviceLogger),
+		tracer, meter, serviceLogger)
+	webhookService := service.NewInstrumentedWebhookService(
+		service.NewWebhookService(webhookRepo, cfg.Webhooks.AllowPrivateNetworks, meter, serviceLogger),
+		tracer, meter, serviceLogger)
+	// The background runners of imports and images are started on the core services
+	importRunner := service.NewImportService(productService, authorizer, cfg.Import.ChunkSize, cfg.Import.SyncMaxRows,
+		cfg.Import.QueueSize, cfg.Import.JobRetention, tracer, meter, serviceLogger)
+	importService := service.NewInstrumentedImportService(importRunner, tracer, meter, serviceLogger)
+
+	// Product images live in a blob store on the local filesystem; thumbnails are generated in the background
+	imageBlobs, err := blob.NewLocalStore(cfg.Images.Dir, tracer)
+	if err != nil {
+		log.Fatalf("Failed to initialize image store: %v", err)
+	}
+	imageRunner := service.NewImageService(products, memory.NewImageRepository(), imageBlobs, authorizer,
+		int64(cfg.Images.MaxSize), cfg.Images.MaxCount, cfg.Images.ThumbnailSize, cfg.Images.QueueSize, tracer, meter, serviceLogger)
+	imageService := service.NewInstrumentedImageService(imageRunner, tracer, meter, serviceLogger)
+
+	// Initialize handlers
+	productHandler := handler.NewProductHandler(productService, cfg.Batch.MaxSize, httpLogger)
+	catalogHandler := handler.NewCatalogHandler(productService, importService, cfg.Import.MaxRows, httpLogger)
+	categoryHandler := handler.NewCategoryHandler(categoryService, httpLogger)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService, httpLogger)
+	webhookHandler := handler.NewWebhookHandler(webhookService, httpLogger)
+	auditHandler := handler.NewAuditHandler(auditService, httpLogger)
+	// Leave room for the multipart framing around the largest accepted set of images
+	imageHandler := handler.NewImageHandler(imageService, int64(cfg.Images.MaxCount)*int64(cfg.Images.MaxSize)+1<<20, httpLogger)
+	adminHandler := handler.NewAdminHandler(telem.LogLevels, httpLogger)
+	graphqlHandler := graphql.NewHandler(&cfg.GraphQL, productService, categoryService, tracer, meter, logger)
+
+	// Live product change stream, fed by the in-process event bus
+	streamBroker := stream.NewBroker(cfg.Stream.HistorySize, cfg.Stream.ClientBuffer, meter, logger)
+	streamHandler := handler.NewStreamHandler(streamBroker, cfg.Stream.HeartbeatInterval, httpLogger)
+
+	// Register event publishers; the in-process bus is always available to subscribers
+	eventBus := events.NewBus()
+	eventBus.Subscribe("webhooks", webhookService.HandleEvent)
+	eventBus.Subscribe("stream", streamBroker.HandleEvent)
+	eventBus.Subscribe("images", imageService.HandleEvent)
+	publishers := []events.Publisher{eventBus}
+	if cfg.Events.WebhookURL != "" {
+		publishers = append(publishers, events.NewWebhookPublisher(cfg.Events.WebhookURL, cfg.Events.WebhookTimeout))
+	}
+	if cfg.Events.FilePath != "" {
+		filePublisher, err := events.NewFilePublisher(cfg.Events.FilePath)
+		if err != nil {
+			log.Fatalf("Failed to initialize file event publisher: %v", err)
+		}
+		defer filePublisher.Close()
+		publishers = append(publishers, filePublisher)
+	}
+
+	// Start background workers
+	dispatcher := events.NewDispatcher(outbox, outbox.Notify(), publishers,
+		cfg.Events.DispatchInterval, cfg.Events.BatchSize, cfg.Events.MaxAttempts, tracer, meter, logger)
+	go dispatcher.Run(ctx)
+
+	webhookDeliverer := webhook.NewDeliverer(webhookRepo, cfg.Webhooks.Timeout, cfg.Webhooks.PollInterval,
+		cfg.Webhooks.MaxAttempts, cfg.Webhooks.BackoffBase, cfg.Webhooks.BackoffMax,
+		cfg.Webhooks.ReceiverConcurrency, cfg.Webhooks.AllowPrivateNetworks, tracer, meter, logger)
+	go webhookDeliverer.Run(ctx)
+
+	reservationExpirer := worker.NewPeriodic("InventoryWorker.ExpireReservations",
+		cfg.Inventory.ExpiryInterval, inventoryService.ExpireReservations, tracer, logger)
+	go reservationExpirer.Run(ctx)
+
+	go importRunner.Run(ctx)
+
+	// Apply the reloadable settings again on SIGHUP or when the config file changes
+	reloader := reload.NewReloader(args, cfg, reload.Targets{
+		LogLevels:  telem.LogLevels,
+		Sampler:    telem.Sampler,
+		RateLimits: rateLimits,
+		Routes:     routeToggles,
+	}, tracer, meter, logger)
+	go reloader.Run(ctx)
+	go imageRunner.Run(ctx)
+
+	// Permanently remove products that have been soft-deleted for longer than the trash retention
+	purger := worker.NewPeriodic("ProductWorker.PurgeDeleted",
+		cfg.Trash.PurgeInterval, func(ctx context.Context) error {
+			_, err := productService.PurgeDeletedProducts(ctx)
+			return err
+		}, tracer, logger)
+	go purger.Run(ctx)
+
+	if persistence != nil {
+		snapshotter := worker.NewPeriodic("PersistenceWorker.Snapshot",
+			cfg.Persistence.SnapshotInterval, persistence.Snapshot, tracer, logger)
+		go snapshotter.Run(ctx)
+	}
 
 	// Initialize HTTP server with otelhttp instrumentation
 	// otelhttp automatically provides HTTP metrics (active_requests, duration, etc.)
-	server := http.NewServer(&cfg.Server, productHandler, tracer, logger, telem)
+	server := http.NewServer(&cfg.Server, http.Handlers{
+		Product:     productHandler,
+		Catalog:     catalogHandler,
+		Category:    categoryHandler,
+		Inventory:   inventoryHandler,
+		Webhook:     webhookHandler,
+		Stream:      streamHandler,
+		Audit:       auditHandler,
+		Image:       imageHandler,
+		Admin:       adminHandler,
+		GraphQL:     graphqlHandler,
+		Idempotency: middleware.Idempotency(idempotency.NewMemoryStore(cfg.Idempotency.TTL), int64(cfg.Idempotency.MaxBodySize), meter, httpLogger),
+	}, guards, tracer, httpLogger, telem)
 
 	// Start server in a goroutine
 	go func() {
-		if err := server.Start(); err != nil {
+		if err := server.Start(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
 			logger.Error("Server error", "error", err.Error())
 			cancel()
 		}
 	}()
 
+	// Serve the same product service over gRPC on its own port
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		grpcServer = grpc.NewServer(&cfg.GRPC, grpc.NewProductServer(productService, logger), authenticator, logger, telem)
+
+		go func() {
+			if err := grpcServer.Start(); err != nil {
+				logger.Error("gRPC server error", "error", err.Error())
+				cancel()
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -87,5 +346,57 @@ func main() {
 		logger.Info("Context cancelled, shutting down...")
 	}
 
+	// Stop taking writes before the final snapshot; open streams would keep the HTTP server waiting
+	streamBroker.Close()
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer shutdownCancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Failed to shut down HTTP server gracefully", "error", err.Error())
+	}
+	if grpcServer != nil {
+		grpcServer.Stop()
+	}
+
+	// Snapshot on the way out so the next start does not have to replay the log
+	if persistence != nil {
+		if err := persistence.Snapshot(context.Background()); err != nil {
+			logger.Error("Failed to write shutdown snapshot", "error", err.Error())
+		}
+	}
+
 	logger.Info("Server stopped")
 }
+
+// newAuthenticator builds the authenticator chain from the configured API keys and JWKS
+func newAuthenticator(ctx context.Context, cfg *config.AuthConfig, keys *auth.APIKeyAuthenticator, rolesClaim string, meter metric.Meter, logger *slog.Logger) (auth.Authenticator, error) {
+	var apiKeys, tokens auth.Authenticator
+
+	if keys != nil {
+		logger.Info("API key authentication enabled", slog.Int("keys", keys.Len()))
+		apiKeys = keys
+	}
+
+	var jwks *auth.JWKS
+	switch {
+	case cfg.JWKSFile != "":
+		var err error
+		if jwks, err = auth.NewJWKSFromFile(cfg.JWKSFile); err != nil {
+			return nil, err
+		}
+	case cfg.JWKSURL != "":
+		jwks = auth.NewJWKSFromURL(ctx, cfg.JWKSURL, cfg.JWKSRefreshInterval, logger)
+	}
+	if jwks != nil {
+		logger.Info("JWT authentication enabled",
+			slog.String("issuer", cfg.JWTIssuer),
+			slog.String("audience", cfg.JWTAudience),
+		)
+		tokens = auth.NewJWTAuthenticator(jwks, cfg.JWTIssuer, cfg.JWTAudience, rolesClaim)
+	}
+
+	if apiKeys == nil && tokens == nil {
+		return nil, errors.New("AUTH_ENABLED requires API keys or a JWKS")
+	}
+
+	return auth.NewChain(apiKeys, tokens, meter), nil
+}